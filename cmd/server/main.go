@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os/signal"
+	"syscall"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opencensus.io/stats/view"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
@@ -15,6 +18,7 @@ import (
 	"grpc-starter/common/config"
 	gormConn "grpc-starter/common/gorm"
 	"grpc-starter/common/healthcheck"
+	commonJwt "grpc-starter/common/jwt"
 	"grpc-starter/common/postgres"
 	commonRedis "grpc-starter/common/redis"
 	notificationModules "grpc-starter/modules/notification/v1"
@@ -63,16 +67,24 @@ func main() {
 
 	redisPool := buildRedisPool(cfg)
 
-	grpcServer := createGrpcServer(cfg)
+	keyManager, kerr := commonJwt.NewKeyManager(cfg.JWTConfig)
+	checkError(kerr)
+
+	grpcServer := createGrpcServer(cfg, redisPool, keyManager)
 
 	grpcConn := server.InitGRPCConn(fmt.Sprintf("127.0.0.1:%v", cfg.Port.GRPC), false, "")
 
-	registerGrpcHandlers(grpcServer.Server, *cfg, db, redisPool, grpcConn)
+	registerGrpcHandlers(grpcServer.Server, *cfg, db, redisPool, grpcConn, keyManager)
+
+	// background jobs stop once the server receives the termination signal it awaits
+	jobsCtx, stopJobs := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopJobs()
+	startJobs(jobsCtx, *cfg, db, redisPool)
 
 	// Reflection for Evans CLI for GRPC Debugging. DO NOT EXPOSE THE SERVER PORT!
 	reflection.Register(grpcServer)
 
-	restServer := createRestServer(cfg.Port.REST)
+	restServer := createRestServer(cfg.Port.REST, keyManager)
 	registerRestHandlers(context.Background(), restServer.ServeMux, fmt.Sprintf(":%s", cfg.Port.GRPC), grpc.WithTransportCredentials(insecure.NewCredentials()))
 
 	// Uncomment to enable pub sub
@@ -94,18 +106,27 @@ func createPubSubClient(projectID, googleSaFile string) *pubsubSDK.PubSub {
 }
 
 // createGrpcServer creates a grpc server
-func createGrpcServer(cfg *config.Config) *server.Grpc {
+func createGrpcServer(cfg *config.Config, redisPool *redis.Pool, keyManager *commonJwt.KeyManager) *server.Grpc {
+	authorizer := commonJwt.NewAuthorizer(
+		commonJwt.NewTokenVerifier(*cfg, keyManager, commonJwt.SystemClock),
+		commonJwt.NewRevocationStore(commonRedis.NewContextClient(redisPool, cfg.Redis.CommandTimeout)),
+	)
+
 	if cfg.Env == envDevelopment {
-		return server.NewDevelopmentGrpc(cfg.Port.GRPC)
+		return server.NewDevelopmentGrpc(cfg.Port.GRPC, authorizer.Authorize)
 	}
-	srv, err := server.NewProductionGrpc(cfg.Env, cfg.ServiceName, cfg.Google.ProjectID, cfg.Port.GRPC)
+	srv, err := server.NewProductionGrpc(cfg.Env, cfg.ServiceName, cfg.Google.ProjectID, cfg.Port.GRPC, authorizer.Authorize)
 	checkError(err)
 	return srv
 }
 
 // createRestServer creates a rest server
-func createRestServer(port string) *server.Rest {
-	return server.NewProductionRest(port)
+func createRestServer(port string, keyManager *commonJwt.KeyManager) *server.Rest {
+	srv := server.NewProductionRest(port)
+	checkError(srv.EnableJWKS(func() interface{} {
+		return keyManager.JWKS()
+	}))
+	return srv
 }
 
 // registerGrpcHandlers registers all the grpc handlers
@@ -115,12 +136,20 @@ func registerGrpcHandlers(
 	db *gorm.DB,
 	redisPool *redis.Pool,
 	grpcConn *grpc.ClientConn,
+	keyManager *commonJwt.KeyManager,
 ) {
 	// start register all module's gRPC handlers
-	userModules.InitGrpc(server, cfg, db, redisPool, grpcConn)
+	checkError(userModules.InitGrpc(server, cfg, db, redisPool, grpcConn, keyManager))
 	// end of register all module's gRPC handlers
 }
 
+// startJobs starts all the background jobs
+func startJobs(ctx context.Context, cfg config.Config, db *gorm.DB, redisPool *redis.Pool) {
+	// start all module's background jobs
+	userModules.StartJobs(ctx, cfg, db, redisPool)
+	// end of start all module's background jobs
+}
+
 // registerRestHandlers registers all the rest handlers
 func registerRestHandlers(ctx context.Context, server *runtime.ServeMux, grpcPort string, options ...grpc.DialOption) {
 	// start register all module's REST handlers
@@ -137,6 +166,7 @@ func registerPubSubHandlers(
 ) []pubsubSDK.Subscriber {
 	var handlers []pubsubSDK.Subscriber
 	handlers = append(handlers, notificationModules.InitSendEmailSubscription(ctx, db, config))
+	handlers = append(handlers, notificationModules.InitSendSMSSubscription(ctx, db, config))
 
 	return handlers
 }
@@ -144,6 +174,7 @@ func registerPubSubHandlers(
 // buildRedisPool builds a redis pool
 func buildRedisPool(cfg *config.Config) *redis.Pool {
 	cachePool := commonRedis.NewPool(cfg.Redis.Address, cfg.Redis.Password)
+	checkError(view.Register(commonRedis.DefaultViews...))
 
 	ctx := context.Background()
 	_, err := cachePool.GetContext(ctx)
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Adapter adapts ContextCacheable to Cacheable for callers that truly have no context.
+// Every call runs with a background context, so it is only bounded by the timeout of the adapted cache
+// and starts a root span of its own. Callers serving a request should use ContextCacheable instead,
+// so the deadline of the request is honored and commands are traced within the request.
+type Adapter struct {
+	cache ContextCacheable
+}
+
+// NewAdapter creates an instance of Adapter.
+func NewAdapter(cache ContextCacheable) *Adapter {
+	return &Adapter{
+		cache: cache,
+	}
+}
+
+// Ping ping the redis server
+func (a *Adapter) Ping() error {
+	return a.cache.Ping(context.Background())
+}
+
+// Get get data from redis by cache key
+func (a *Adapter) Get(key string) ([]byte, error) {
+	return a.cache.Get(context.Background(), key)
+}
+
+// MGet get data of many cache keys in a single round trip, data of a missing key is nil
+func (a *Adapter) MGet(keys ...string) ([][]byte, error) {
+	return a.cache.MGet(context.Background(), keys...)
+}
+
+// GetDel get data from redis by cache key and remove it atomically, so only one caller gets it
+func (a *Adapter) GetDel(key string) ([]byte, error) {
+	return a.cache.GetDel(context.Background(), key)
+}
+
+// Set set data with defined cache key, value, and time-to-live (ttl) to store in redis
+func (a *Adapter) Set(key string, value interface{}, ttl int) error {
+	return a.cache.Set(context.Background(), key, value, ttl)
+}
+
+// MSet set data of many cache keys with the same time-to-live (ttl) in a single round trip
+func (a *Adapter) MSet(values map[string]interface{}, ttl int) error {
+	return a.cache.MSet(context.Background(), values, ttl)
+}
+
+// SetWithExpireAt set key value and update expire using unix timestamp
+func (a *Adapter) SetWithExpireAt(key string, value interface{}, ttl time.Time) error {
+	return a.cache.SetWithExpireAt(context.Background(), key, value, ttl)
+}
+
+// SetNX set data with defined cache key, value, and time-to-live (ttl) unless the key exists, reporting whether it was set
+func (a *Adapter) SetNX(key string, value interface{}, ttl int) (bool, error) {
+	return a.cache.SetNX(context.Background(), key, value, ttl)
+}
+
+// Incr increments counter by one, a new counter expires after ttl seconds
+func (a *Adapter) Incr(key string, ttl int) (int64, error) {
+	return a.cache.Incr(context.Background(), key, ttl)
+}
+
+// Decr decrements counter by one, a new counter expires after ttl seconds
+func (a *Adapter) Decr(key string, ttl int) (int64, error) {
+	return a.cache.Decr(context.Background(), key, ttl)
+}
+
+// Exists check if key is exist in redis
+func (a *Adapter) Exists(key string) (bool, error) {
+	return a.cache.Exists(context.Background(), key)
+}
+
+// Remove remove cache by cache key
+func (a *Adapter) Remove(key string) error {
+	return a.cache.Remove(context.Background(), key)
+}
+
+// BulkRemove remove cache by certain cache key pattern
+func (a *Adapter) BulkRemove(pattern string) error {
+	return a.cache.BulkRemove(context.Background(), pattern)
+}
+
+// Scan scan all cache key with certain pattern
+func (a *Adapter) Scan(pattern string) ([]string, error) {
+	return a.cache.Scan(context.Background(), pattern)
+}
@@ -2,6 +2,7 @@
 package cache
 
 import (
+	"context"
 	"time"
 )
 
@@ -11,10 +12,22 @@ type Cacheable interface {
 	Ping() error
 	// Get get data from redis by cache key
 	Get(key string) ([]byte, error)
-	// Set set data with defined cache key, value, and time-to-live (ttl) to store in redis
+	// MGet get data of many cache keys in a single round trip, data of a missing key is nil
+	MGet(keys ...string) ([][]byte, error)
+	// GetDel get data from redis by cache key and remove it atomically, so only one caller gets it
+	GetDel(key string) ([]byte, error)
+	// Set set data with defined cache key, value, and time-to-live (ttl) to store in redis, a non-positive ttl expires it at once
 	Set(key string, value interface{}, ttl int) error
+	// MSet set data of many cache keys with the same time-to-live (ttl) in a single round trip, a non-positive ttl expires them at once
+	MSet(values map[string]interface{}, ttl int) error
 	// SetWithExpireAt set key value and update expire using unix timestamp
 	SetWithExpireAt(key string, value interface{}, ttl time.Time) error
+	// SetNX set data with defined cache key, value, and time-to-live (ttl) unless the key exists, reporting whether it was set
+	SetNX(key string, value interface{}, ttl int) (bool, error)
+	// Incr increments counter by one, a new counter expires after ttl seconds
+	Incr(key string, ttl int) (int64, error)
+	// Decr decrements counter by one, a new counter expires after ttl seconds
+	Decr(key string, ttl int) (int64, error)
 	// Exists check if key is exist in redis
 	Exists(key string) (bool, error)
 	// Remove remove cache by cache key
@@ -24,3 +37,36 @@ type Cacheable interface {
 	// Scan scan all cache key with certain pattern
 	Scan(pattern string) ([]string, error)
 }
+
+// ContextCacheable is the context aware version of Cacheable.
+// Every method gives up once the context is done, either by its deadline or by cancellation.
+type ContextCacheable interface {
+	// Ping ping the redis server
+	Ping(ctx context.Context) error
+	// Get get data from redis by cache key
+	Get(ctx context.Context, key string) ([]byte, error)
+	// MGet get data of many cache keys in a single round trip, data of a missing key is nil
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	// GetDel get data from redis by cache key and remove it atomically, so only one caller gets it
+	GetDel(ctx context.Context, key string) ([]byte, error)
+	// Set set data with defined cache key, value, and time-to-live (ttl) to store in redis, a non-positive ttl expires it at once
+	Set(ctx context.Context, key string, value interface{}, ttl int) error
+	// MSet set data of many cache keys with the same time-to-live (ttl) in a single round trip, a non-positive ttl expires them at once
+	MSet(ctx context.Context, values map[string]interface{}, ttl int) error
+	// SetWithExpireAt set key value and update expire using unix timestamp
+	SetWithExpireAt(ctx context.Context, key string, value interface{}, ttl time.Time) error
+	// SetNX set data with defined cache key, value, and time-to-live (ttl) unless the key exists, reporting whether it was set
+	SetNX(ctx context.Context, key string, value interface{}, ttl int) (bool, error)
+	// Incr increments counter by one, a new counter expires after ttl seconds
+	Incr(ctx context.Context, key string, ttl int) (int64, error)
+	// Decr decrements counter by one, a new counter expires after ttl seconds
+	Decr(ctx context.Context, key string, ttl int) (int64, error)
+	// Exists check if key is exist in redis
+	Exists(ctx context.Context, key string) (bool, error)
+	// Remove remove cache by cache key
+	Remove(ctx context.Context, key string) error
+	// BulkRemove remove cache by certain cache key pattern
+	BulkRemove(ctx context.Context, pattern string) error
+	// Scan scan all cache key with certain pattern
+	Scan(ctx context.Context, pattern string) ([]string, error)
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes values of T to bytes stored in cache and decodes them back.
+type Codec[T any] interface {
+	// Marshal encodes value
+	Marshal(value T) ([]byte, error)
+	// Unmarshal decodes value
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSONCodec encodes values as JSON.
+type JSONCodec[T any] struct{}
+
+// Marshal encodes value as JSON
+func (JSONCodec[T]) Marshal(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes value from JSON
+func (JSONCodec[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// vtMessage is implemented by messages generated with vtprotobuf,
+// whose generated marshalling avoids the reflection of proto.Marshal.
+type vtMessage interface {
+	MarshalVT() ([]byte, error)
+	UnmarshalVT(data []byte) error
+}
+
+// ProtoCodec encodes protobuf messages in wire format.
+// Messages generated with vtprotobuf are encoded with their generated MarshalVT and UnmarshalVT,
+// other messages fall back to proto.Marshal and proto.Unmarshal.
+type ProtoCodec[T proto.Message] struct{}
+
+// Marshal encodes message in wire format
+func (ProtoCodec[T]) Marshal(value T) ([]byte, error) {
+	if message, ok := any(value).(vtMessage); ok {
+		return message.MarshalVT()
+	}
+
+	return proto.Marshal(value)
+}
+
+// Unmarshal decodes message from wire format
+func (ProtoCodec[T]) Unmarshal(data []byte) (T, error) {
+	var zero T
+	value, _ := zero.ProtoReflect().Type().New().Interface().(T)
+
+	if message, ok := any(value).(vtMessage); ok {
+		return value, message.UnmarshalVT(data)
+	}
+
+	return value, proto.Unmarshal(data, value)
+}
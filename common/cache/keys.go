@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"strings"
+)
+
+// keySeparator separates namespace and parts of a key
+const keySeparator = ":"
+
+// Keys builds keys within a namespace, so keys of different callers never collide.
+// Parts are joined with a colon, e.g. NewKeys("user", "id").Key("42") is "user:id:42".
+type Keys struct {
+	namespace string
+}
+
+// NewKeys creates keys within the namespace made of parts
+func NewKeys(namespace ...string) Keys {
+	return Keys{
+		namespace: strings.Join(namespace, keySeparator),
+	}
+}
+
+// Namespace returns the namespace of keys
+func (k Keys) Namespace() string {
+	return k.namespace
+}
+
+// Key returns the key of parts within the namespace
+func (k Keys) Key(parts ...string) string {
+	return strings.Join(append([]string{k.namespace}, parts...), keySeparator)
+}
+
+// Sub returns keys within a namespace nested in this namespace
+func (k Keys) Sub(parts ...string) Keys {
+	return Keys{
+		namespace: k.Key(parts...),
+	}
+}
+
+// Pattern returns the pattern matching every key within the namespace
+func (k Keys) Pattern() string {
+	return k.namespace + keySeparator + "*"
+}
@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultLoadTimeout bounds a load shared by concurrent callers when no load timeout is given
+const DefaultLoadTimeout = 5 * time.Second
+
+var (
+	// jitterRand randomizes ttl of every Typed cache, seeded so instances do not share the same sequence
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // jitter does not need a secure source
+	// jitterMutex guards jitterRand, which is not safe for concurrent use
+	jitterMutex sync.Mutex
+)
+
+// Typed caches values of T within a namespace.
+//
+// Values are encoded with a codec, and since ContextCacheable marshals every value as JSON,
+// the encoded bytes are stored as a JSON string. A tombstone left by Invalidate is read as a miss.
+// Every ttl is randomized by up to jitter, a fraction of the ttl, so values written together
+// do not expire together. A nil cache or a zero ttl disables caching, every lookup is a miss.
+type Typed[T any] struct {
+	cache       ContextCacheable
+	keys        Keys
+	codec       Codec[T]
+	ttl         time.Duration
+	jitter      float64
+	loadTimeout time.Duration
+	group       singleflight.Group
+}
+
+// NewTyped creates an instance of Typed.
+// A non-positive load timeout falls back to DefaultLoadTimeout.
+func NewTyped[T any](cache ContextCacheable, keys Keys, codec Codec[T], ttl time.Duration, jitter float64, loadTimeout time.Duration) *Typed[T] {
+	if loadTimeout <= 0 {
+		loadTimeout = DefaultLoadTimeout
+	}
+
+	return &Typed[T]{
+		cache:       cache,
+		keys:        keys,
+		codec:       codec,
+		ttl:         ttl,
+		jitter:      jitter,
+		loadTimeout: loadTimeout,
+	}
+}
+
+// Keys returns the keys values are cached under.
+func (t *Typed[T]) Keys() Keys {
+	return t.keys
+}
+
+// Get reads value cached under id, ok is false on a miss.
+// ContextCacheable does not tell a missing key from an unavailable cache, both are a miss,
+// so the returned error only reports a value that can not be decoded.
+func (t *Typed[T]) Get(ctx context.Context, id string) (value T, ok bool, err error) {
+	if !t.enabled() {
+		return value, false, nil
+	}
+
+	key := t.keys.Key(id)
+	raw, err := t.cache.Get(ctx, key)
+	if err != nil || len(raw) == 0 {
+		return value, false, nil
+	}
+
+	var data []byte
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return value, false, fmt.Errorf("error decoding cached value of key %s: %w", key, err)
+	}
+
+	// a tombstone is stored as JSON null, unlike any encoded value
+	if data == nil {
+		return value, false, nil
+	}
+
+	value, err = t.codec.Unmarshal(data)
+	if err != nil {
+		return value, false, fmt.Errorf("error decoding cached value of key %s: %w", key, err)
+	}
+
+	return value, true, nil
+}
+
+// Set caches value under id for the ttl of cache.
+func (t *Typed[T]) Set(ctx context.Context, id string, value T) error {
+	return t.SetWithTTL(ctx, id, value, t.ttl)
+}
+
+// SetWithTTL caches value under id for ttl, a ttl under a second is not cached.
+func (t *Typed[T]) SetWithTTL(ctx context.Context, id string, value T, ttl time.Duration) error {
+	if !t.enabled() {
+		return nil
+	}
+
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error encoding value of key %s: %w", t.keys.Key(id), err)
+	}
+
+	return t.setEncoded(ctx, id, data, ttl, false)
+}
+
+// SetIfAbsent caches value under id for the ttl of cache, unless a value or a tombstone is cached under id.
+// Values read from the source of truth are cached with it, so a read that raced a write
+// never replaces the tombstone of the write with the value it overwrote.
+func (t *Typed[T]) SetIfAbsent(ctx context.Context, id string, value T) error {
+	return t.SetIfAbsentWithTTL(ctx, id, value, t.ttl)
+}
+
+// SetIfAbsentWithTTL caches value under id for ttl, unless a value or a tombstone is cached under id, see SetIfAbsent.
+func (t *Typed[T]) SetIfAbsentWithTTL(ctx context.Context, id string, value T, ttl time.Duration) error {
+	if !t.enabled() {
+		return nil
+	}
+
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error encoding value of key %s: %w", t.keys.Key(id), err)
+	}
+
+	return t.setEncoded(ctx, id, data, ttl, true)
+}
+
+// setEncoded caches encoded value under id for ttl, only when nothing is cached under id if absent is set
+func (t *Typed[T]) setEncoded(ctx context.Context, id string, data []byte, ttl time.Duration, absent bool) error {
+	if !t.enabled() {
+		return nil
+	}
+
+	ttl = t.jittered(ttl)
+	if ttl < time.Second {
+		return nil
+	}
+
+	key := t.keys.Key(id)
+	if absent {
+		if _, err := t.cache.SetNX(ctx, key, data, int(ttl.Seconds())); err != nil {
+			return fmt.Errorf("error caching value of key %s: %w", key, err)
+		}
+		return nil
+	}
+
+	if err := t.cache.Set(ctx, key, data, int(ttl.Seconds())); err != nil {
+		return fmt.Errorf("error caching value of key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Invalidate replaces value cached under id with a tombstone for ttl, see Invalidate.
+func (t *Typed[T]) Invalidate(ctx context.Context, id string, ttl time.Duration) error {
+	if t.cache == nil {
+		return nil
+	}
+
+	return Invalidate(ctx, t.cache, t.keys.Key(id), ttl)
+}
+
+// Invalidate replaces value cached under key with a tombstone for ttl, it must be called after every write
+// of the cached value is committed. Typed reads a tombstone as a miss, and values read before the write
+// are cached with SetIfAbsent, which does not replace the tombstone, so a read racing the write
+// can not cache the overwritten value. Only a read slower than ttl still can.
+func Invalidate(ctx context.Context, cache ContextCacheable, key string, ttl time.Duration) error {
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+
+	if err := cache.Set(ctx, key, nil, int(ttl.Seconds())); err != nil {
+		return fmt.Errorf("error invalidating cached value of key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Delete removes value cached under id.
+func (t *Typed[T]) Delete(ctx context.Context, id string) error {
+	if t.cache == nil {
+		return nil
+	}
+
+	key := t.keys.Key(id)
+	if err := t.cache.Remove(ctx, key); err != nil {
+		return fmt.Errorf("error removing cached value of key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// GetOrLoad reads value cached under id, loading and caching it on a miss.
+//
+// Concurrent misses of the same id share a single load, so an expired value does not
+// send every caller to the source at once. Errors of load are returned as they are and never cached,
+// failing to cache a loaded value only costs the next lookup another load.
+func (t *Typed[T]) GetOrLoad(ctx context.Context, id string, load func(ctx context.Context) (T, error)) (T, error) {
+	if value, ok, err := t.Get(ctx, id); err == nil && ok {
+		return value, nil
+	}
+
+	return t.Load(ctx, id, load)
+}
+
+// Load loads value of id and caches it with SetIfAbsent, for callers that already missed with Get.
+//
+// Concurrent loads of the same id share a single load, which runs detached from the context
+// of every caller and is bounded by the load timeout instead, so a caller giving up does not fail the others.
+// Every caller still gives up once its own context is done. The loaded value is shared encoded
+// and decoded for every caller, so callers never share, and race on, the same value.
+func (t *Typed[T]) Load(ctx context.Context, id string, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+
+	results := t.group.DoChan(t.keys.Key(id), func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, t.loadTimeout)
+		defer cancel()
+
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := t.codec.Marshal(loaded)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding value of key %s: %w", t.keys.Key(id), err)
+		}
+
+		_ = t.setEncoded(loadCtx, id, data, t.ttl, true)
+
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return value, result.Err
+		}
+
+		data, _ := result.Val.([]byte)
+		return t.codec.Unmarshal(data)
+	}
+}
+
+// enabled checks whether values are cached
+func (t *Typed[T]) enabled() bool {
+	return t.cache != nil && t.ttl > 0
+}
+
+// jittered randomizes ttl by up to jitter of it in either direction
+func (t *Typed[T]) jittered(ttl time.Duration) time.Duration {
+	if t.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+
+	jitterMutex.Lock()
+	factor := 1 + t.jitter*(2*jitterRand.Float64()-1)
+	jitterMutex.Unlock()
+
+	return time.Duration(float64(ttl) * factor)
+}
+
+// detachedContext keeps the values of its parent, such as its span, without its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+// Deadline returns no deadline
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns a channel that is never closed
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns no error
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Value returns the value of key in parent
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,262 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/common/redis"
+)
+
+type testValue struct {
+	Name string `json:"name"`
+}
+
+func newTestCache(t *testing.T) (*miniredis.Miniredis, cache.ContextCacheable) {
+	server, err := miniredis.Run()
+	assert.Nil(t, err)
+	t.Cleanup(server.Close)
+
+	return server, redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+}
+
+func TestKeys(t *testing.T) {
+	keys := cache.NewKeys("user", "id")
+
+	assert.Equal(t, "user:id", keys.Namespace())
+	assert.Equal(t, "user:id:42", keys.Key("42"))
+	assert.Equal(t, "user:id:42:roles", keys.Sub("42").Key("roles"))
+	assert.Equal(t, "user:id:*", keys.Pattern())
+}
+
+func TestTyped(t *testing.T) {
+	t.Run("value is cached with json codec", func(t *testing.T) {
+		server, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+
+		_, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		assert.Nil(t, typed.Set(context.Background(), "1", testValue{Name: "one"}))
+		assert.True(t, server.Exists("test:1"))
+		assert.Equal(t, time.Minute, server.TTL("test:1"))
+
+		value, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "one", value.Name)
+
+		assert.Nil(t, typed.Delete(context.Background(), "1"))
+		_, ok, _ = typed.Get(context.Background(), "1")
+		assert.False(t, ok)
+	})
+
+	t.Run("message is cached with proto codec", func(t *testing.T) {
+		_, client := newTestCache(t)
+		typed := cache.NewTyped[*wrapperspb.StringValue](client, cache.NewKeys("test"), cache.ProtoCodec[*wrapperspb.StringValue]{}, time.Minute, 0, time.Second)
+
+		assert.Nil(t, typed.Set(context.Background(), "1", wrapperspb.String("one")))
+
+		value, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "one", value.GetValue())
+	})
+
+	t.Run("undecodable value is reported", func(t *testing.T) {
+		server, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+
+		assert.Nil(t, server.Set("test:1", "{"))
+
+		_, ok, err := typed.Get(context.Background(), "1")
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("ttl is jittered within bounds", func(t *testing.T) {
+		server, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, 100*time.Second, 0.2, time.Second)
+
+		for i := 0; i < 20; i++ {
+			assert.Nil(t, typed.Set(context.Background(), "1", testValue{}))
+			ttl := server.TTL("test:1")
+			assert.GreaterOrEqual(t, ttl, 79*time.Second)
+			assert.LessOrEqual(t, ttl, 120*time.Second)
+		}
+	})
+
+	t.Run("tombstone is a miss that values read before it do not replace", func(t *testing.T) {
+		server, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+
+		assert.Nil(t, typed.Set(context.Background(), "1", testValue{Name: "old"}))
+		assert.Nil(t, typed.Invalidate(context.Background(), "1", 5*time.Second))
+		assert.Equal(t, 5*time.Second, server.TTL("test:1"))
+
+		_, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		assert.Nil(t, typed.SetIfAbsent(context.Background(), "1", testValue{Name: "old"}))
+		_, ok, _ = typed.Get(context.Background(), "1")
+		assert.False(t, ok)
+
+		server.FastForward(5 * time.Second)
+
+		assert.Nil(t, typed.SetIfAbsent(context.Background(), "1", testValue{Name: "new"}))
+		value, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "new", value.Name)
+	})
+
+	t.Run("disabled cache always misses", func(t *testing.T) {
+		typed := cache.NewTyped[testValue](nil, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+
+		assert.Nil(t, typed.Set(context.Background(), "1", testValue{Name: "one"}))
+		_, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, typed.Delete(context.Background(), "1"))
+	})
+}
+
+func TestTyped_GetOrLoad(t *testing.T) {
+	t.Run("concurrent misses share a single load", func(t *testing.T) {
+		_, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+
+		var loads int32
+		release := make(chan struct{})
+		load := func(ctx context.Context) (testValue, error) {
+			atomic.AddInt32(&loads, 1)
+			<-release
+			return testValue{Name: "loaded"}, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := typed.GetOrLoad(context.Background(), "1", load)
+				assert.Nil(t, err)
+				assert.Equal(t, "loaded", value.Name)
+			}()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+
+		value, ok, err := typed.Get(context.Background(), "1")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "loaded", value.Name)
+	})
+
+	t.Run("error of load is returned and not cached", func(t *testing.T) {
+		server, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+		errLoad := errors.New("load failed")
+
+		_, err := typed.GetOrLoad(context.Background(), "1", func(ctx context.Context) (testValue, error) {
+			return testValue{}, errLoad
+		})
+
+		assert.ErrorIs(t, err, errLoad)
+		assert.False(t, server.Exists("test:1"))
+	})
+
+	t.Run("concurrent callers get their own copy of the loaded value", func(t *testing.T) {
+		_, client := newTestCache(t)
+		typed := cache.NewTyped[*testValue](client, cache.NewKeys("test"), cache.JSONCodec[*testValue]{}, time.Minute, 0, time.Second)
+
+		release := make(chan struct{})
+		load := func(ctx context.Context) (*testValue, error) {
+			<-release
+			return &testValue{Name: "loaded"}, nil
+		}
+
+		values := make([]*testValue, 2)
+		var wg sync.WaitGroup
+		for i := range values {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				value, err := typed.Load(context.Background(), "1", load)
+				assert.Nil(t, err)
+				values[i] = value
+			}(i)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.NotSame(t, values[0], values[1])
+		values[0].Name = "changed"
+		assert.Equal(t, "loaded", values[1].Name)
+	})
+
+	t.Run("shared load outlives the caller that started it", func(t *testing.T) {
+		_, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, time.Second)
+
+		release := make(chan struct{})
+		load := func(ctx context.Context) (testValue, error) {
+			select {
+			case <-release:
+				return testValue{Name: "loaded"}, nil
+			case <-ctx.Done():
+				return testValue{}, ctx.Err()
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() {
+			_, err := typed.Load(ctx, "1", load)
+			first <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		second := make(chan testValue, 1)
+		go func() {
+			value, err := typed.Load(context.Background(), "1", load)
+			assert.Nil(t, err)
+			second <- value
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+
+		close(release)
+		assert.Equal(t, "loaded", (<-second).Name)
+	})
+
+	t.Run("shared load is bounded by the load timeout", func(t *testing.T) {
+		_, client := newTestCache(t)
+		typed := cache.NewTyped[testValue](client, cache.NewKeys("test"), cache.JSONCodec[testValue]{}, time.Minute, 0, 50*time.Millisecond)
+
+		_, err := typed.Load(context.Background(), "1", func(ctx context.Context) (testValue, error) {
+			<-ctx.Done()
+			return testValue{}, ctx.Err()
+		})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -2,26 +2,44 @@
 package config
 
 import (
+	"net"
+	"strings"
+	"time"
+
 	"github.com/joeshaw/envdecode"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
+
+	"grpc-starter/common/hasher"
 )
 
 // Config holds configuration for the project.
 type Config struct {
-	Env          string `env:"ENV,default=development"`
-	ServiceName  string `env:"SERVICE_NAME,default=grpc-starter"`
-	Port         Port
-	HashID       HashID
-	Google       Google
-	Postgres     Postgres
-	Redis        Redis
-	Jaeger       Jaeger
-	JWTConfig    JWTConfig
-	SMTP         SMTP
-	Mailgun      Mailgun
-	Sendgrid     Sendgrid
-	CloudStorage CloudStorage
+	Env               string `env:"ENV,default=development"`
+	ServiceName       string `env:"SERVICE_NAME,default=grpc-starter"`
+	Port              Port
+	HashID            HashID
+	Google            Google
+	Postgres          Postgres
+	Redis             Redis
+	Jaeger            Jaeger
+	JWTConfig         JWTConfig
+	SMTP              SMTP
+	Mailgun           Mailgun
+	Sendgrid          Sendgrid
+	Wavecell          Wavecell
+	CloudStorage      CloudStorage
+	PasswordReset     PasswordReset
+	EmailVerification EmailVerification
+	PhoneOTP          PhoneOTP
+	MFA               MFA
+	LoginThrottle     LoginThrottle
+	PasswordPolicy    PasswordPolicy
+	PasswordHash      PasswordHash
+	OIDC              OIDC
+	AccountErasure    AccountErasure
+	UserCache         UserCache
+	Network           Network
 }
 
 // Port holds configuration for project's port.
@@ -55,9 +73,11 @@ type Postgres struct {
 }
 
 // Redis holds configuration for the Redis.
+// Every command is bounded by the command timeout, a non-positive timeout falls back to one second.
 type Redis struct {
-	Address  string `env:"REDIS_ADDRESS,required"`
-	Password string `env:"REDIS_PASSWORD"`
+	Address        string        `env:"REDIS_ADDRESS,required"`
+	Password       string        `env:"REDIS_PASSWORD"`
+	CommandTimeout time.Duration `env:"REDIS_COMMAND_TIMEOUT,default=1s"`
 }
 
 // Jaeger holds configuration for the Jaeger.
@@ -67,7 +87,14 @@ type Jaeger struct {
 
 // JWTConfig holds configuration for jwt.
 type JWTConfig struct {
-	SecretKey string `env:"JWT_SECRET_KEY"`
+	SecretKey       string        `env:"JWT_SECRET_KEY"`
+	KeysDir         string        `env:"JWT_KEYS_DIR"`
+	SigningKeyID    string        `env:"JWT_SIGNING_KEY_ID"`
+	Issuer          string        `env:"JWT_ISSUER,default=starter.mobile"`
+	Audience        string        `env:"JWT_AUDIENCE"`
+	AccessTokenTTL  time.Duration `env:"JWT_ACCESS_TOKEN_TTL,default=15m"`
+	RefreshTokenTTL time.Duration `env:"JWT_REFRESH_TOKEN_TTL,default=720h"`
+	ClockSkew       time.Duration `env:"JWT_CLOCK_SKEW,default=30s"`
 }
 
 // SMTP holds configuration for smtp email.
@@ -91,9 +118,169 @@ type Sendgrid struct {
 	APIKey string `env:"SENDGRID_API_KEY"`
 }
 
+// Wavecell holds configuration for wavecell sms service.
+type Wavecell struct {
+	BaseURL      string `env:"WAVECELL_BASE_URL,default=https://api.wavecell.com"`
+	APIKey       string `env:"WAVECELL_API_KEY"`
+	SubAccountID string `env:"WAVECELL_SUB_ACCOUNT_ID"`
+	Source       string `env:"WAVECELL_SOURCE"`
+	Country      string `env:"WAVECELL_COUNTRY,default=ID"`
+	CallbackURL  string `env:"WAVECELL_CALLBACK_URL"`
+}
+
 // CloudStorage holds configuration for file service.
 type CloudStorage struct {
-	AssetURL string `env:"ASSET_URL"`
+	AssetURL           string `env:"ASSET_URL"`
+	FileServiceBaseURL string `env:"FILESVC_BASE_URL"`
+}
+
+// PasswordReset holds configuration for the forgot password flow.
+type PasswordReset struct {
+	URL      string        `env:"PASSWORD_RESET_URL,default=http://localhost:8081/reset-password"`
+	TokenTTL time.Duration `env:"PASSWORD_RESET_TOKEN_TTL,default=15m"`
+}
+
+// EmailVerification holds configuration for the email verification flow.
+type EmailVerification struct {
+	CodeTTL        time.Duration `env:"EMAIL_VERIFICATION_CODE_TTL,default=10m"`
+	MaxAttempts    int           `env:"EMAIL_VERIFICATION_MAX_ATTEMPTS,default=5"`
+	ResendCooldown time.Duration `env:"EMAIL_VERIFICATION_RESEND_COOLDOWN,default=1m"`
+}
+
+// PhoneOTP holds configuration for the phone number login flow.
+// Requests are limited per phone number, by a cooldown between two codes and by a quota within a window.
+type PhoneOTP struct {
+	CodeTTL        time.Duration `env:"PHONE_OTP_CODE_TTL,default=5m"`
+	MaxAttempts    int           `env:"PHONE_OTP_MAX_ATTEMPTS,default=5"`
+	ResendCooldown time.Duration `env:"PHONE_OTP_RESEND_COOLDOWN,default=1m"`
+	MaxRequests    int           `env:"PHONE_OTP_MAX_REQUESTS,default=5"`
+	RequestWindow  time.Duration `env:"PHONE_OTP_REQUEST_WINDOW,default=1h"`
+}
+
+// MFA holds configuration for time-based one-time password two-factor authentication.
+type MFA struct {
+	Issuer            string        `env:"MFA_ISSUER,default=Starter"`
+	ChallengeTTL      time.Duration `env:"MFA_CHALLENGE_TTL,default=5m"`
+	MaxAttempts       int           `env:"MFA_MAX_ATTEMPTS,default=5"`
+	Skew              int           `env:"MFA_SKEW,default=1"`
+	RecoveryCodeCount int           `env:"MFA_RECOVERY_CODE_COUNT,default=10"`
+}
+
+// LoginThrottle holds configuration for failed login lockout.
+// Once an account or an ip address reaches its maximum failures, login is locked for the base lockout,
+// the lockout doubles with every following failure up to the maximum lockout.
+// Failures are counted within the failure window that starts with the first of them.
+type LoginThrottle struct {
+	AccountMaxFailures int           `env:"LOGIN_ACCOUNT_MAX_FAILURES,default=5"`
+	IPMaxFailures      int           `env:"LOGIN_IP_MAX_FAILURES,default=50"`
+	BaseLockout        time.Duration `env:"LOGIN_BASE_LOCKOUT,default=30s"`
+	MaxLockout         time.Duration `env:"LOGIN_MAX_LOCKOUT,default=1h"`
+	FailureWindow      time.Duration `env:"LOGIN_FAILURE_WINDOW,default=1h"`
+}
+
+// PasswordPolicy holds configuration for password rules applied on registration and password change.
+type PasswordPolicy struct {
+	MinLength     int  `env:"PASSWORD_MIN_LENGTH,default=8"`
+	MaxLength     int  `env:"PASSWORD_MAX_LENGTH,default=72"`
+	RequireUpper  bool `env:"PASSWORD_REQUIRE_UPPER,default=false"`
+	RequireLower  bool `env:"PASSWORD_REQUIRE_LOWER,default=true"`
+	RequireDigit  bool `env:"PASSWORD_REQUIRE_DIGIT,default=true"`
+	RequireSymbol bool `env:"PASSWORD_REQUIRE_SYMBOL,default=false"`
+	CheckBreached bool `env:"PASSWORD_CHECK_BREACHED,default=true"`
+}
+
+// PasswordHash holds configuration for hashing user passwords.
+// Hashes made with another algorithm or parameters are upgraded on the next successful login.
+type PasswordHash struct {
+	Algorithm         PasswordHashAlgorithm `env:"PASSWORD_HASH_ALGORITHM,default=bcrypt"`
+	BcryptCost        int                   `env:"PASSWORD_HASH_BCRYPT_COST,default=12"`
+	Argon2Memory      uint32                `env:"PASSWORD_HASH_ARGON2_MEMORY,default=65536"`
+	Argon2Iterations  uint32                `env:"PASSWORD_HASH_ARGON2_ITERATIONS,default=3"`
+	Argon2Parallelism uint8                 `env:"PASSWORD_HASH_ARGON2_PARALLELISM,default=2"`
+	Argon2SaltLength  uint32                `env:"PASSWORD_HASH_ARGON2_SALT_LENGTH,default=16"`
+	Argon2KeyLength   uint32                `env:"PASSWORD_HASH_ARGON2_KEY_LENGTH,default=32"`
+}
+
+// PasswordHashAlgorithm is the name of a supported password hash algorithm, either bcrypt or argon2id.
+type PasswordHashAlgorithm string
+
+// Decode decodes PasswordHashAlgorithm, rejecting algorithms that are not supported
+func (a *PasswordHashAlgorithm) Decode(value string) error {
+	switch value = strings.TrimSpace(value); value {
+	case hasher.AlgorithmBcrypt, hasher.AlgorithmArgon2id:
+		*a = PasswordHashAlgorithm(value)
+		return nil
+	default:
+		return errors.Errorf("unsupported password hash algorithm %q", value)
+	}
+}
+
+// OIDC holds configuration for login with ID tokens of OpenID Connect providers.
+// Google is enabled when its client ids are set, the generic provider is enabled when its issuer is set.
+// Client ids are separated by semicolon.
+type OIDC struct {
+	GoogleClientIDs   []string      `env:"OIDC_GOOGLE_CLIENT_IDS"`
+	ProviderName      string        `env:"OIDC_PROVIDER_NAME,default=oidc"`
+	ProviderIssuer    string        `env:"OIDC_PROVIDER_ISSUER"`
+	ProviderClientIDs []string      `env:"OIDC_PROVIDER_CLIENT_IDS"`
+	ProviderJWKSURL   string        `env:"OIDC_PROVIDER_JWKS_URL"`
+	KeysCacheTTL      time.Duration `env:"OIDC_KEYS_CACHE_TTL,default=1h"`
+	ClockSkew         time.Duration `env:"OIDC_CLOCK_SKEW,default=1m"`
+	HTTPTimeout       time.Duration `env:"OIDC_HTTP_TIMEOUT,default=10s"`
+}
+
+// AccountErasure holds configuration for erasing personal data of deleted accounts.
+// Accounts are erased once they have been deleted for longer than the retention period,
+// the job looks for them every interval and erases at most batch size accounts at a time.
+// An account failing to be erased is left out until the retry delay has passed, so it does not hold back the others.
+type AccountErasure struct {
+	Enabled         bool          `env:"ACCOUNT_ERASURE_ENABLED,default=true"`
+	RetentionPeriod time.Duration `env:"ACCOUNT_ERASURE_RETENTION_PERIOD,default=720h"`
+	Interval        time.Duration `env:"ACCOUNT_ERASURE_INTERVAL,default=1h"`
+	BatchSize       int           `env:"ACCOUNT_ERASURE_BATCH_SIZE,default=100"`
+	RetryDelay      time.Duration `env:"ACCOUNT_ERASURE_RETRY_DELAY,default=24h"`
+}
+
+// UserCache holds configuration for caching user lookups in redis.
+// Lookups finding no user are cached for the negative ttl, so that repeated misses do not reach the database.
+// Both ttls are randomized by up to the jitter, a fraction of the ttl, so users cached together do not expire together.
+// Caching is disabled when the ttl is zero.
+// A load from the database shared by concurrent lookups of the same user is bounded by the load timeout,
+// independently of the requests waiting on it.
+type UserCache struct {
+	TTL         time.Duration `env:"USER_CACHE_TTL,default=5m"`
+	NegativeTTL time.Duration `env:"USER_CACHE_NEGATIVE_TTL,default=30s"`
+	TTLJitter   float64       `env:"USER_CACHE_TTL_JITTER,default=0.1"`
+	LoadTimeout time.Duration `env:"USER_CACHE_LOAD_TIMEOUT,default=5s"`
+}
+
+// Network holds configuration for the network in front of the service.
+// Callers are identified by the address of their gRPC peer, only peers within the trusted proxies,
+// such as the REST gateway dialing from loopback, are trusted to forward the address of their client.
+type Network struct {
+	TrustedProxies CIDRs `env:"TRUSTED_PROXIES,default=127.0.0.1/32;::1/128"`
+}
+
+// CIDRs are networks decoded from a semicolon separated list of CIDR notations, e.g. 10.0.0.0/8;::1/128.
+type CIDRs []*net.IPNet
+
+// Decode decodes CIDRs from a semicolon separated list of CIDR notations
+func (c *CIDRs) Decode(value string) error {
+	var networks CIDRs
+	for _, cidr := range strings.Split(value, ";") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return errors.Wrapf(err, "invalid CIDR %q", cidr)
+		}
+		networks = append(networks, network)
+	}
+
+	*c = networks
+	return nil
 }
 
 // NewConfig creates an instance of Config.
@@ -27,3 +27,18 @@ func TestNewConfig(t *testing.T) {
 		assert.NotNil(t, cfg)
 	})
 }
+
+func TestPasswordHashAlgorithm_Decode(t *testing.T) {
+	t.Run("supported algorithms are decoded", func(t *testing.T) {
+		for _, value := range []string{"bcrypt", "argon2id"} {
+			var algorithm config.PasswordHashAlgorithm
+			assert.Nil(t, algorithm.Decode(value))
+			assert.Equal(t, config.PasswordHashAlgorithm(value), algorithm)
+		}
+	})
+
+	t.Run("fail to decode unsupported algorithm", func(t *testing.T) {
+		var algorithm config.PasswordHashAlgorithm
+		assert.NotNil(t, algorithm.Decode("md5"))
+	})
+}
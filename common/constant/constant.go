@@ -25,10 +25,30 @@ const (
 	NinetyNineHundred = 9999
 	// TenThousand define number ten thousand
 	TenThousand = 10000
+	// Five define number five
+	Five = 5
 	// Six define number six
 	Six = 6
 	// MobileIssuer define mobile issuer
 	MobileIssuer = "starter.mobile"
 	// SuccessMessage define success message
 	SuccessMessage = "success"
+	// ForgotPasswordMessage define forgot password response message
+	ForgotPasswordMessage = "jika email terdaftar, link reset password akan dikirimkan ke email anda"
+	// LogoutMessage define logout response message
+	LogoutMessage = "berhasil keluar"
+	// LogoutAllMessage define logout from all devices response message
+	LogoutAllMessage = "berhasil keluar dari semua perangkat"
+	// DeleteAccountMessage define delete account response message
+	DeleteAccountMessage = "akun berhasil dihapus"
+	// VerifyEmailMessage define verify email response message
+	VerifyEmailMessage = "email berhasil diverifikasi"
+	// ResendVerificationMessage define resend verification response message
+	ResendVerificationMessage = "kode verifikasi telah dikirimkan ke email anda"
+	// RequestPhoneOTPMessage define request phone otp response message
+	RequestPhoneOTPMessage = "jika nomor telepon terdaftar, kode verifikasi akan dikirimkan melalui sms"
+	// ChangePasswordMessage define change password response message
+	ChangePasswordMessage = "password berhasil diubah"
+	// RevokeSessionMessage define revoke session response message
+	RevokeSessionMessage = "berhasil keluar dari perangkat"
 )
@@ -9,6 +9,10 @@ import (
 	"grpc-starter/common/tools"
 )
 
+// SystemActor is the actor of changes made without an authenticated principal,
+// changes made by an authenticated principal are audited with its user id by gorm callbacks
+const SystemActor = "system"
+
 // Auditable define entity for auditable
 type Auditable struct {
 	CreatedBy sql.NullString `json:"created_by"`
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strings"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -14,6 +16,56 @@ var (
 	ErrInternalServerError = NewError(codes.Internal, "internal server error")
 	// ErrWrongLoginCredentials represents error when login credentials are wrong.
 	ErrWrongLoginCredentials = NewError(codes.InvalidArgument, "username atau password salah")
+	// ErrTooManyLoginAttempts represents error when login is locked after too many failed attempts.
+	ErrTooManyLoginAttempts = NewError(codes.ResourceExhausted, "terlalu banyak percobaan login, silahkan coba lagi beberapa saat lagi")
+	// ErrInvalidPasswordResetToken represents error when password reset token is invalid, expired, or already used.
+	ErrInvalidPasswordResetToken = NewError(codes.InvalidArgument, "token reset password tidak valid atau sudah kadaluarsa")
+	// ErrInvalidRefreshToken represents error when refresh token is invalid, expired, revoked, or reused.
+	ErrInvalidRefreshToken = NewError(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	// ErrUserNotFound represents error when user is not found.
+	ErrUserNotFound = NewError(codes.NotFound, "user tidak ditemukan")
+	// ErrUserVersionConflict represents error when user was updated by another request since it was read.
+	ErrUserVersionConflict = NewError(codes.Aborted, "data user telah diubah oleh permintaan lain, silahkan muat ulang dan coba lagi")
+	// ErrUserErased represents error when personal data of a deleted user has been erased, so it can not be restored anymore.
+	ErrUserErased = NewError(codes.FailedPrecondition, "data user telah dihapus permanen dan tidak dapat dipulihkan")
+	// ErrInvalidUpdateMask represents error when update mask contains a field that can not be updated.
+	ErrInvalidUpdateMask = NewError(codes.InvalidArgument, "update mask tidak valid")
+	// ErrUserDisabled represents error when user is disabled by an administrator.
+	ErrUserDisabled = NewError(codes.PermissionDenied, "akun anda telah dinonaktifkan")
+	// ErrInvalidPageToken represents error when page token is malformed or does not match the requested sorting.
+	ErrInvalidPageToken = NewError(codes.InvalidArgument, "page token tidak valid")
+	// ErrInvalidVerificationCode represents error when email verification code is wrong or expired.
+	ErrInvalidVerificationCode = NewError(codes.InvalidArgument, "kode verifikasi tidak valid atau sudah kadaluarsa")
+	// ErrTooManyVerificationAttempts represents error when email verification code is guessed wrongly too many times.
+	ErrTooManyVerificationAttempts = NewError(codes.ResourceExhausted, "terlalu banyak percobaan verifikasi, silahkan minta kode verifikasi baru")
+	// ErrVerificationResendTooSoon represents error when email verification code is requested again before the cooldown ends.
+	ErrVerificationResendTooSoon = NewError(codes.ResourceExhausted, "kode verifikasi baru saja dikirim, silahkan coba beberapa saat lagi")
+	// ErrOTPRequestLimitExceeded represents error when one-time password is requested too many times within a window.
+	ErrOTPRequestLimitExceeded = NewError(codes.ResourceExhausted, "terlalu banyak permintaan kode verifikasi, silahkan coba lagi nanti")
+	// ErrEmailAlreadyVerified represents error when user email is already verified.
+	ErrEmailAlreadyVerified = NewError(codes.FailedPrecondition, "email anda sudah terverifikasi")
+	// ErrMFAAlreadyEnabled represents error when two-factor authentication of user is already confirmed.
+	ErrMFAAlreadyEnabled = NewError(codes.FailedPrecondition, "autentikasi dua faktor sudah aktif")
+	// ErrMFANotEnrolled represents error when two-factor authentication is confirmed before it is enrolled.
+	ErrMFANotEnrolled = NewError(codes.FailedPrecondition, "autentikasi dua faktor belum didaftarkan")
+	// ErrInvalidMFACode represents error when one-time password or recovery code is wrong or already used.
+	ErrInvalidMFACode = NewError(codes.InvalidArgument, "kode autentikasi dua faktor tidak valid")
+	// ErrInvalidMFAChallenge represents error when mfa challenge token is invalid, expired, or exhausted.
+	ErrInvalidMFAChallenge = NewError(codes.Unauthenticated, "sesi verifikasi dua faktor tidak valid atau sudah kadaluarsa, silahkan login kembali")
+	// ErrPasswordPolicyViolated represents error when password does not satisfy the password policy.
+	ErrPasswordPolicyViolated = NewError(codes.InvalidArgument, "password tidak memenuhi ketentuan")
+	// ErrUserAlreadyExists represents error when email, username, or phone number is already used by another user.
+	ErrUserAlreadyExists = NewError(codes.AlreadyExists, "user sudah terdaftar")
+	// ErrInvalidIDToken represents error when id token of an OpenID Connect provider is invalid, expired, or not trusted.
+	ErrInvalidIDToken = NewError(codes.Unauthenticated, "id token tidak valid atau sudah kadaluarsa")
+	// ErrIdentityEmailNotVerified represents error when OpenID Connect provider has not verified the email of the account.
+	ErrIdentityEmailNotVerified = NewError(codes.FailedPrecondition, "email akun penyedia login belum terverifikasi")
+	// ErrIdentityLinkNotAllowed represents error when the email of the account belongs to a user whose email is not verified.
+	ErrIdentityLinkNotAllowed = NewError(codes.FailedPrecondition, "email sudah terdaftar namun belum terverifikasi, silahkan login dengan password dan verifikasi email anda terlebih dahulu")
+	// ErrSessionNotFound represents error when session is not found, or is already revoked or expired.
+	ErrSessionNotFound = NewError(codes.NotFound, "sesi tidak ditemukan")
+	// ErrRoleNotFound represents error when role is not found.
+	ErrRoleNotFound = NewError(codes.NotFound, "role tidak ditemukan")
 )
 
 // Error represents a data structure for error.
@@ -39,12 +91,51 @@ func (err *Error) Error() error {
 	return fmt.Errorf("%d:%s", err.Code, err.Message)
 }
 
+// WithFieldViolations returns gRPC status error carrying the fields that caused the error as bad request details.
+func (err *Error) WithFieldViolations(violations ...*errdetails.BadRequest_FieldViolation) error {
+	st, detailErr := status.New(err.Code, err.Message).WithDetails(&errdetails.BadRequest{
+		FieldViolations: violations,
+	})
+	if detailErr != nil {
+		return err.Error()
+	}
+
+	return st.Err()
+}
+
+// NewFieldViolation creates field violation of bad request details.
+func NewFieldViolation(field string, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	}
+}
+
+// ToGRPCError converts error returned by service into gRPC status error.
+// Details of gRPC status error are kept as they are.
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	parseError := ParseError(err)
+	return status.Error(parseError.Code, parseError.Message)
+}
+
 // ParseError parses error message and returns an instance of Error.
 func ParseError(err error) *Error {
 	if err == nil {
 		return nil
 	}
 
+	if st, ok := status.FromError(err); ok {
+		return NewError(st.Code(), st.Message())
+	}
+
 	split := strings.Split(err.Error(), ":")
 
 	var strToCode = map[string]codes.Code{
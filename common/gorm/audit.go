@@ -0,0 +1,71 @@
+package gorm
+
+import (
+	"database/sql"
+
+	"gorm.io/gorm"
+
+	commonJwt "grpc-starter/common/jwt"
+)
+
+const (
+	// createdByColumn is the column of auditable entities holding who created the row
+	createdByColumn = "created_by"
+	// updatedByColumn is the column of auditable entities holding who last updated the row
+	updatedByColumn = "updated_by"
+)
+
+// RegisterAuditCallbacks registers callbacks that fill created_by and updated_by of auditable entities
+// with the user id of the authenticated principal found in the statement context.
+// Statements without principal, such as registration or background jobs, keep the values set by the caller.
+func RegisterAuditCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("audit:create", auditCreate); err != nil {
+		return err
+	}
+
+	return db.Callback().Update().Before("gorm:update").Register("audit:update", auditUpdate)
+}
+
+// auditCreate fills created_by and updated_by on create
+func auditCreate(db *gorm.DB) {
+	actor, ok := actorFromStatement(db.Statement)
+	if !ok {
+		return
+	}
+
+	setAuditColumn(db.Statement, createdByColumn, actor)
+	setAuditColumn(db.Statement, updatedByColumn, actor)
+}
+
+// auditUpdate fills updated_by on update
+func auditUpdate(db *gorm.DB) {
+	actor, ok := actorFromStatement(db.Statement)
+	if !ok {
+		return
+	}
+
+	setAuditColumn(db.Statement, updatedByColumn, actor)
+}
+
+// actorFromStatement finds user id of the authenticated principal of the statement
+func actorFromStatement(stmt *gorm.Statement) (sql.NullString, bool) {
+	if stmt.Schema == nil || stmt.Context == nil {
+		return sql.NullString{}, false
+	}
+
+	principal, ok := commonJwt.PrincipalFromContext(stmt.Context)
+	if !ok {
+		return sql.NullString{}, false
+	}
+
+	return sql.NullString{String: principal.UserID.String(), Valid: true}, true
+}
+
+// setAuditColumn sets column only when the entity has it
+func setAuditColumn(stmt *gorm.Statement, column string, actor sql.NullString) {
+	if stmt.Schema.LookUpField(column) == nil {
+		return
+	}
+
+	stmt.SetColumn(column, actor, true)
+}
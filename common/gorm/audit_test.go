@@ -0,0 +1,78 @@
+package gorm_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	commonentity "grpc-starter/common/entity"
+	commonGorm "grpc-starter/common/gorm"
+	commonJwt "grpc-starter/common/jwt"
+)
+
+type auditedEntity struct {
+	ID   uuid.UUID
+	Name string
+	commonentity.Auditable
+}
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := commonGorm.RegisterAuditCallbacks(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestRegisterAuditCallbacks(t *testing.T) {
+	principal := &commonJwt.Principal{UserID: uuid.New()}
+	actor := sql.NullString{String: principal.UserID.String(), Valid: true}
+
+	t.Run("create is audited with principal", func(t *testing.T) {
+		db := newDryRunDB(t)
+		ctx := commonJwt.ContextWithPrincipal(context.Background(), principal)
+		row := &auditedEntity{ID: uuid.New(), Auditable: commonentity.NewAuditable("system")}
+
+		stmt := db.WithContext(ctx).Create(row).Statement
+
+		assert.Nil(t, stmt.Error)
+		assert.Equal(t, actor, row.CreatedBy)
+		assert.Equal(t, actor, row.UpdatedBy)
+	})
+
+	t.Run("create without principal keeps the given actor", func(t *testing.T) {
+		db := newDryRunDB(t)
+		row := &auditedEntity{ID: uuid.New(), Auditable: commonentity.NewAuditable("system")}
+
+		stmt := db.WithContext(context.Background()).Create(row).Statement
+
+		assert.Nil(t, stmt.Error)
+		assert.Equal(t, "system", row.CreatedBy.String)
+	})
+
+	t.Run("update is audited with principal", func(t *testing.T) {
+		db := newDryRunDB(t)
+		ctx := commonJwt.ContextWithPrincipal(context.Background(), principal)
+		values := map[string]interface{}{"name": "updated"}
+
+		stmt := db.WithContext(ctx).Model(&auditedEntity{}).Where("id = ?", uuid.New()).UpdateColumns(values).Statement
+
+		assert.Nil(t, stmt.Error)
+		assert.Equal(t, actor, values["updated_by"])
+		assert.Contains(t, stmt.SQL.String(), "updated_by")
+	})
+}
@@ -0,0 +1,44 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	// uniqueViolationCode is the postgres SQLSTATE of unique_violation
+	uniqueViolationCode = "23505"
+)
+
+// UniqueViolationError represents error when a row violates a unique constraint.
+type UniqueViolationError struct {
+	// Constraint is the name of the violated constraint.
+	Constraint string
+	err        error
+}
+
+// Error returns the message of the underlying database error.
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("unique constraint %s violated: %v", e.Constraint, e.err)
+}
+
+// Unwrap returns the underlying database error.
+func (e *UniqueViolationError) Unwrap() error {
+	return e.err
+}
+
+// TranslateError translates postgres unique violation into UniqueViolationError,
+// other errors are returned as they are.
+func TranslateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return &UniqueViolationError{
+			Constraint: pgErr.ConstraintName,
+			err:        err,
+		}
+	}
+
+	return err
+}
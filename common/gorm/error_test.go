@@ -0,0 +1,39 @@
+package gorm_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	commonGorm "grpc-starter/common/gorm"
+)
+
+func TestTranslateError(t *testing.T) {
+	t.Run("unique violation is translated", func(t *testing.T) {
+		pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}
+
+		err := commonGorm.TranslateError(fmt.Errorf("insert: %w", pgErr))
+
+		var uniqueErr *commonGorm.UniqueViolationError
+		assert.True(t, errors.As(err, &uniqueErr))
+		assert.Equal(t, "users_email_key", uniqueErr.Constraint)
+		assert.True(t, errors.Is(err, pgErr))
+	})
+
+	t.Run("other postgres error is returned as it is", func(t *testing.T) {
+		pgErr := &pgconn.PgError{Code: "23503", ConstraintName: "users_role_id_fkey"}
+
+		err := commonGorm.TranslateError(pgErr)
+
+		var uniqueErr *commonGorm.UniqueViolationError
+		assert.False(t, errors.As(err, &uniqueErr))
+		assert.Equal(t, pgErr, err)
+	})
+
+	t.Run("nil error stays nil", func(t *testing.T) {
+		assert.Nil(t, commonGorm.TranslateError(nil))
+	})
+}
@@ -25,5 +25,13 @@ func NewPostgresGormDB(pgxPool *pgxpool.Pool) (*gorm.DB, error) {
 	}), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
-	return connCfg, err
+	if err != nil {
+		return nil, err
+	}
+
+	if err := RegisterAuditCallbacks(connCfg); err != nil {
+		return nil, err
+	}
+
+	return connCfg, nil
 }
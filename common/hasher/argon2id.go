@@ -0,0 +1,138 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// AlgorithmArgon2id is the name of argon2id algorithm
+	AlgorithmArgon2id = "argon2id"
+	// argon2idPrefix is the PHC string format identifier of argon2id
+	argon2idPrefix = "$argon2id$"
+	// argon2idFormat is the PHC string format of argon2id hash
+	argon2idFormat = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+	// argon2idParts is the number of parts of argon2id hash split by $, the first part is empty
+	argon2idParts = 6
+)
+
+// encoding is the base64 encoding of PHC string format, without padding
+var encoding = base64.RawStdEncoding
+
+// Argon2idParams holds parameters of argon2id.
+type Argon2idParams struct {
+	// Memory is the amount of memory in KiB.
+	Memory uint32
+	// Iterations is the number of passes over the memory.
+	Iterations uint32
+	// Parallelism is the number of threads.
+	Parallelism uint8
+	// SaltLength is the number of random bytes of salt.
+	SaltLength uint32
+	// KeyLength is the number of bytes of the derived key.
+	KeyLength uint32
+}
+
+// Argon2id hashes passwords with argon2id, its hashes are encoded in PHC string format like
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type Argon2id struct {
+	params Argon2idParams
+}
+
+// NewArgon2id creates Argon2id with the given parameters.
+func NewArgon2id(params Argon2idParams) *Argon2id {
+	return &Argon2id{
+		params: params,
+	}
+}
+
+// Algorithm returns the name of the algorithm.
+func (a *Argon2id) Algorithm() string {
+	return AlgorithmArgon2id
+}
+
+// Hash hashes password with argon2id and a random salt.
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+
+	return fmt.Sprintf(
+		argon2idFormat,
+		argon2.Version,
+		a.params.Memory,
+		a.params.Iterations,
+		a.params.Parallelism,
+		encoding.EncodeToString(salt),
+		encoding.EncodeToString(key),
+	), nil
+}
+
+// Verify compares password with argon2id hash using the parameters stored in the hash.
+func (a *Argon2id) Verify(encoded string, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// Supports checks whether encoded hash is an argon2id hash.
+func (a *Argon2id) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+// NeedsRehash checks whether argon2id hash is made with other parameters.
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params != a.params
+}
+
+// decodeArgon2id decodes parameters, salt, and key of argon2id hash
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != argon2idParts || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := encoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	key, err := encoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,79 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// AlgorithmBcrypt is the name of bcrypt algorithm
+	AlgorithmBcrypt = "bcrypt"
+	// BcryptMaxPasswordBytes is the number of bytes of a password bcrypt uses, the rest of it is ignored
+	BcryptMaxPasswordBytes = 72
+)
+
+// bcryptPrefixes are the modular crypt format identifiers of bcrypt versions
+var bcryptPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+// Bcrypt hashes passwords with bcrypt, its hashes are encoded in modular crypt format like $2a$12$...
+type Bcrypt struct {
+	cost int
+}
+
+// NewBcrypt creates Bcrypt with the given cost.
+func NewBcrypt(cost int) *Bcrypt {
+	return &Bcrypt{
+		cost: cost,
+	}
+}
+
+// Algorithm returns the name of the algorithm.
+func (b *Bcrypt) Algorithm() string {
+	return AlgorithmBcrypt
+}
+
+// Hash hashes password with bcrypt.
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+// Verify compares password with bcrypt hash.
+func (b *Bcrypt) Verify(encoded string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	return true, nil
+}
+
+// Supports checks whether encoded hash is a bcrypt hash.
+func (b *Bcrypt) Supports(encoded string) bool {
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(encoded, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NeedsRehash checks whether bcrypt hash is made with another cost.
+func (b *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+
+	return cost != b.cost
+}
@@ -0,0 +1,3 @@
+// Package hasher provides password hashing with self-describing encoded hashes,
+// so hashes made with an outdated algorithm or parameters can be detected and upgraded.
+package hasher
@@ -0,0 +1,85 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsupportedHash is returned when no hasher recognizes the encoded hash.
+	ErrUnsupportedHash = errors.New("hasher: unsupported hash")
+	// ErrMalformedHash is returned when the encoded hash can not be decoded.
+	ErrMalformedHash = errors.New("hasher: malformed hash")
+)
+
+// PasswordHasher hashes passwords and verifies them against encoded hashes.
+type PasswordHasher interface {
+	// Algorithm returns the name of the algorithm.
+	Algorithm() string
+	// Hash hashes password into an encoded hash that carries its algorithm and parameters.
+	Hash(password string) (string, error)
+	// Verify compares password with encoded hash.
+	Verify(encoded string, password string) (bool, error)
+	// Supports checks whether encoded hash is made with the algorithm of the hasher.
+	Supports(encoded string) bool
+	// NeedsRehash checks whether encoded hash is made with another algorithm or parameters than the hasher uses.
+	NeedsRehash(encoded string) bool
+}
+
+// Chain hashes passwords with the preferred hasher and verifies hashes of every hasher it knows,
+// so users keep logging in while their hashes are upgraded.
+type Chain struct {
+	preferred PasswordHasher
+	hashers   []PasswordHasher
+}
+
+// NewChain creates Chain that prefers the hasher with the given algorithm.
+func NewChain(algorithm string, hashers ...PasswordHasher) (*Chain, error) {
+	for _, hasher := range hashers {
+		if hasher.Algorithm() == algorithm {
+			return &Chain{
+				preferred: hasher,
+				hashers:   hashers,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("hasher: unknown algorithm %q", algorithm)
+}
+
+// Algorithm returns the name of the preferred algorithm.
+func (c *Chain) Algorithm() string {
+	return c.preferred.Algorithm()
+}
+
+// Hash hashes password with the preferred hasher.
+func (c *Chain) Hash(password string) (string, error) {
+	return c.preferred.Hash(password)
+}
+
+// Verify compares password with encoded hash using the hasher that made it.
+func (c *Chain) Verify(encoded string, password string) (bool, error) {
+	for _, hasher := range c.hashers {
+		if hasher.Supports(encoded) {
+			return hasher.Verify(encoded, password)
+		}
+	}
+
+	return false, ErrUnsupportedHash
+}
+
+// Supports checks whether encoded hash is made with one of the known algorithms.
+func (c *Chain) Supports(encoded string) bool {
+	for _, hasher := range c.hashers {
+		if hasher.Supports(encoded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NeedsRehash checks whether encoded hash is not made with the preferred algorithm and parameters.
+func (c *Chain) NeedsRehash(encoded string) bool {
+	return !c.preferred.Supports(encoded) || c.preferred.NeedsRehash(encoded)
+}
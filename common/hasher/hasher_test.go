@@ -0,0 +1,122 @@
+package hasher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/hasher"
+)
+
+const (
+	testPassword = "correct horse battery staple"
+	// testSeededHash is the bcrypt hash with cost 12 used by the seeded user
+	testSeededHash = "$2a$12$xQxjo6oocTh1/vScQ/nA.eSkNa8C..ozVH7vJvN75OfXXhhXXQG3y"
+)
+
+var testArgon2idParams = hasher.Argon2idParams{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestBcrypt(t *testing.T) {
+	bcryptHasher := hasher.NewBcrypt(4)
+
+	t.Run("hash can be verified", func(t *testing.T) {
+		encoded, err := bcryptHasher.Hash(testPassword)
+		assert.Nil(t, err)
+		assert.True(t, bcryptHasher.Supports(encoded))
+		assert.False(t, bcryptHasher.NeedsRehash(encoded))
+
+		valid, err := bcryptHasher.Verify(encoded, testPassword)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+
+		valid, err = bcryptHasher.Verify(encoded, "wrong password")
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("hash with another cost needs rehash", func(t *testing.T) {
+		assert.True(t, bcryptHasher.Supports(testSeededHash))
+		assert.True(t, bcryptHasher.NeedsRehash(testSeededHash))
+		assert.False(t, hasher.NewBcrypt(12).NeedsRehash(testSeededHash))
+	})
+}
+
+func TestArgon2id(t *testing.T) {
+	argon2idHasher := hasher.NewArgon2id(testArgon2idParams)
+
+	t.Run("hash is encoded in phc string format and can be verified", func(t *testing.T) {
+		encoded, err := argon2idHasher.Hash(testPassword)
+		assert.Nil(t, err)
+		assert.Regexp(t, `^\$argon2id\$v=19\$m=64,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, encoded)
+		assert.False(t, argon2idHasher.NeedsRehash(encoded))
+
+		valid, err := argon2idHasher.Verify(encoded, testPassword)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+
+		valid, err = argon2idHasher.Verify(encoded, "wrong password")
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("hash with other parameters needs rehash but still verifies", func(t *testing.T) {
+		params := testArgon2idParams
+		params.Iterations = 2
+		encoded, err := hasher.NewArgon2id(params).Hash(testPassword)
+		assert.Nil(t, err)
+		assert.True(t, argon2idHasher.NeedsRehash(encoded))
+
+		valid, err := argon2idHasher.Verify(encoded, testPassword)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("malformed hash is rejected", func(t *testing.T) {
+		valid, err := argon2idHasher.Verify("$argon2id$v=19$m=64,t=1$salt$key", testPassword)
+		assert.ErrorIs(t, err, hasher.ErrMalformedHash)
+		assert.False(t, valid)
+	})
+}
+
+func TestChain(t *testing.T) {
+	bcryptHasher := hasher.NewBcrypt(4)
+	argon2idHasher := hasher.NewArgon2id(testArgon2idParams)
+
+	t.Run("unknown algorithm is rejected", func(t *testing.T) {
+		_, err := hasher.NewChain("md5", bcryptHasher, argon2idHasher)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("hashes of other algorithms are verified and need rehash", func(t *testing.T) {
+		chain, err := hasher.NewChain(hasher.AlgorithmArgon2id, bcryptHasher, argon2idHasher)
+		assert.Nil(t, err)
+
+		legacy, err := bcryptHasher.Hash(testPassword)
+		assert.Nil(t, err)
+
+		valid, err := chain.Verify(legacy, testPassword)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.True(t, chain.NeedsRehash(legacy))
+
+		upgraded, err := chain.Hash(testPassword)
+		assert.Nil(t, err)
+		assert.True(t, argon2idHasher.Supports(upgraded))
+		assert.False(t, chain.NeedsRehash(upgraded))
+	})
+
+	t.Run("unsupported hash is rejected", func(t *testing.T) {
+		chain, err := hasher.NewChain(hasher.AlgorithmBcrypt, bcryptHasher, argon2idHasher)
+		assert.Nil(t, err)
+
+		valid, err := chain.Verify("plain text", testPassword)
+		assert.ErrorIs(t, err, hasher.ErrUnsupportedHash)
+		assert.False(t, valid)
+	})
+}
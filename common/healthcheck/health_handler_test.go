@@ -66,6 +66,20 @@ func TestHealthHandler_Check(t *testing.T) {
 	})
 }
 
+func TestHealthHandler_AuthFuncOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("health check is public", func(t *testing.T) {
+		exec := createHealthHandlerExecutor(ctrl)
+
+		ctx, err := exec.handler.AuthFuncOverride(testContext, "/grpc.health.v1.Health/Check")
+
+		assert.Nil(t, err)
+		assert.Equal(t, testContext, ctx)
+	})
+}
+
 func createHealthHandlerExecutor(ctrl *gomock.Controller) *HealthHandlerExecutor {
 	c := mock_healthcheck.NewMockCheckHealth(ctrl)
 	h := healthcheck.NewHealthHandler(c)
@@ -3,90 +3,130 @@ package jwt
 import (
 	"context"
 	"fmt"
-	"os"
+	"log"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	authv1 "grpc-starter/api/auth/v1"
 	"grpc-starter/common/tools"
 )
 
-const (
-	// userServiceLogin is the name of the service that is used for login.
-	userServiceLogin = "/starter.user.v1.UserService/Login"
-	// userServiceRegister is the name of the service that is used for register.
-	userServiceRegister = "/starter.user.v1.UserService/Register"
-	//nolint // userServiceForgotPassword is the name of the service that is used for forgot password.
-	userServiceForgotPassword = "/starter.user.v1.UserService/ForgotPassword"
-)
-
-var ignoreMethod = []string{
-	userServiceLogin,
-	userServiceRegister,
-	userServiceForgotPassword,
-}
+type contextKey string
 
 // CustomClaims define available data in JWT
 type CustomClaims struct {
-	ExpiresAt int64     `json:"exp,omitempty"`
-	ID        string    `json:"jti,omitempty"`
-	IssuedAt  int64     `json:"iat,omitempty"`
-	NotBefore int64     `json:"nbf,omitempty"`
-	Subject   uuid.UUID `json:"sub,omitempty"`
-	Issuer    string    `json:"iss,omitempty"`
-	jwt.StandardClaims
+	ExpiresAt  int64     `json:"exp,omitempty"`
+	ID         string    `json:"jti,omitempty"`
+	IssuedAt   int64     `json:"iat,omitempty"`
+	NotBefore  int64     `json:"nbf,omitempty"`
+	Subject    uuid.UUID `json:"sub,omitempty"`
+	Issuer     string    `json:"iss,omitempty"`
+	Audience   string    `json:"aud,omitempty"`
+	Generation int64     `json:"gen,omitempty"`
+	// SessionID is the session the token is issued for, it is shared by every token of a session
+	SessionID uuid.UUID `json:"sid,omitempty"`
+	Roles     []string  `json:"roles,omitempty"`
+	// Scopes are permissions granted to the subject through its roles
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Valid validates time based claims against the current time without any clock skew.
+// TokenVerifier uses Validate instead so that clock and skew are configurable.
+func (c *CustomClaims) Valid() error {
+	return c.Validate(time.Now(), 0)
+}
+
+// Validate validates time based claims against now, tolerating the given clock skew.
+func (c *CustomClaims) Validate(now time.Time, skew time.Duration) error {
+	if c.ExpiresAt == 0 {
+		return fmt.Errorf("token has no expiration time")
+	}
+	if now.Add(-skew).Unix() >= c.ExpiresAt {
+		return fmt.Errorf("token is expired")
+	}
+	if c.NotBefore != 0 && now.Add(skew).Unix() < c.NotBefore {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if c.IssuedAt != 0 && now.Add(skew).Unix() < c.IssuedAt {
+		return fmt.Errorf("token is used before issued")
+	}
+
+	return nil
+}
+
+// Authorizer authenticates requests using JWT and rejects revoked tokens
+type Authorizer struct {
+	verifier   *TokenVerifier
+	revocation *RevocationStore
+}
+
+// NewAuthorizer creates an instance of Authorizer.
+func NewAuthorizer(verifier *TokenVerifier, revocation *RevocationStore) *Authorizer {
+	return &Authorizer{
+		verifier:   verifier,
+		revocation: revocation,
+	}
 }
 
-// Authorize is used by a middleware to authenticate requests
-func Authorize(ctx context.Context) (context.Context, error) {
+// Authorize is used by a middleware to authorize requests using the policy declared on the called RPC.
+// RPCs without a policy are rejected.
+func (a *Authorizer) Authorize(ctx context.Context) (context.Context, error) {
 	method, _ := grpc.Method(ctx)
-	for _, v := range ignoreMethod {
-		if method == v {
-			return ctx, nil
-		}
+	policy, err := MethodPolicy(method)
+	if err != nil {
+		log.Println("[Authorizer - Authorize] Error while finding authorization policy :", err)
+		return nil, status.Errorf(codes.PermissionDenied, "Anda tidak memiliki akses")
+	}
+
+	if policy.GetAccess() == authv1.Access_ACCESS_PUBLIC {
+		return ctx, nil
 	}
+
 	token, err := grpc_auth.AuthFromMD(ctx, "bearer")
 	if err != nil {
 		return nil, err
 	}
 
-	userClaims, err := verifyClaims(token)
+	userClaims, err := a.verifier.Verify(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	revoked, err := a.revocation.IsRevoked(ctx, userClaims)
 	if err != nil {
+		log.Println("[Authorizer - Authorize] Error while checking token revocation :", err)
+		return nil, status.Errorf(codes.Internal, "internal server error")
+	}
+	if revoked {
 		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
 	}
 
-	newCtx := context.WithValue(ctx, tools.ContextKeySubjectID, userClaims.ID)
+	if !HasAnyRole(userClaims.Roles, policy.GetRoles()) || !HasAllPermissions(userClaims.Scopes, policy.GetPermissions()) {
+		return nil, status.Errorf(codes.PermissionDenied, "Anda tidak memiliki akses")
+	}
+
+	newCtx := context.WithValue(ctx, tools.ContextKeySubjectID, userClaims.Subject.String())
+	newCtx = ContextWithPrincipal(newCtx, NewPrincipal(userClaims))
 
 	return newCtx, nil
 }
 
-// verifyClaims verifies the claims in the token.
-func verifyClaims(accessToken string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		accessToken,
-		&CustomClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				return nil, fmt.Errorf("unexpected token signing method")
-			}
-
-			return []byte(os.Getenv("JWT_SECRET_KEY")), nil
-		},
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+// RequirePermissions makes sure the caller has all of the given permissions.
+// Handlers use it to guard operations that are narrower than the policy of their RPC.
+func RequirePermissions(ctx context.Context, permissions ...string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
 	}
 
-	claims, ok := token.Claims.(*CustomClaims)
-	if !ok {
-		return nil, fmt.Errorf("invalid token claims")
+	if !principal.HasPermissions(permissions...) {
+		return status.Errorf(codes.PermissionDenied, "Anda tidak memiliki akses")
 	}
 
-	return claims, nil
+	return nil
 }
@@ -0,0 +1,305 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"grpc-starter/common/config"
+)
+
+const (
+	// keyFileExtension is the extension of PEM key files loaded from keys directory
+	keyFileExtension = ".pem"
+	// legacyKeyID is the key id of the shared HMAC secret, tokens signed with it carry no kid header
+	legacyKeyID = ""
+)
+
+// Key is a single key known by the KeyManager.
+// A key without signKey is a retired key, it only verifies tokens that were signed before the rotation.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// JSONWebKey is the public part of a key as described in RFC 7517.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys as described in RFC 7517.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeyManager holds every key used to sign and verify tokens.
+//
+// Keys are loaded from PEM files inside the keys directory and identified by their file name without extension,
+// which becomes the kid header of signed tokens. Only the key selected by the signing key id signs new tokens,
+// every other key keeps verifying tokens it has signed. To rotate, add a new key file, point the signing key id to it,
+// and remove the old file (or replace it with its public key) once the tokens it has signed are expired.
+//
+// When the keys directory is empty, the shared HMAC secret is used to sign and verify tokens with HS256.
+type KeyManager struct {
+	keys       map[string]*Key
+	signingKey *Key
+}
+
+// NewKeyManager creates an instance of KeyManager from configuration.
+func NewKeyManager(cfg config.JWTConfig) (*KeyManager, error) {
+	km := &KeyManager{
+		keys: make(map[string]*Key),
+	}
+
+	if cfg.SecretKey != "" {
+		km.keys[legacyKeyID] = &Key{
+			ID:        legacyKeyID,
+			Method:    jwt.SigningMethodHS256,
+			signKey:   []byte(cfg.SecretKey),
+			verifyKey: []byte(cfg.SecretKey),
+		}
+	}
+
+	if cfg.KeysDir != "" {
+		if err := km.loadDir(cfg.KeysDir); err != nil {
+			return nil, err
+		}
+	}
+
+	signingKeyID := cfg.SigningKeyID
+	if signingKeyID == "" && cfg.KeysDir != "" {
+		id, err := km.defaultSigningKeyID()
+		if err != nil {
+			return nil, err
+		}
+		signingKeyID = id
+	}
+
+	key, ok := km.keys[signingKeyID]
+	if !ok || key.signKey == nil {
+		return nil, fmt.Errorf("signing key %q is not found or has no private key", signingKeyID)
+	}
+	km.signingKey = key
+
+	return km, nil
+}
+
+// Sign signs claims using the signing key.
+func (km *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(km.signingKey.Method, claims)
+	if km.signingKey.ID != legacyKeyID {
+		token.Header["kid"] = km.signingKey.ID
+	}
+
+	return token.SignedString(km.signingKey.signKey)
+}
+
+// Parse parses and verifies signature of a token into claims using the key referenced by its kid header.
+// Claims are not validated, TokenVerifier validates them using its own clock.
+func (km *KeyManager) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	return parser.ParseWithClaims(tokenString, claims, km.keyFunc)
+}
+
+// JWKS returns public keys of every asymmetric key.
+// The shared HMAC secret is never published.
+func (km *KeyManager) JWKS() JSONWebKeySet {
+	ids := make([]string, 0, len(km.keys))
+	for id := range km.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, id := range ids {
+		if jwk, ok := km.keys[id].jwk(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// defaultSigningKeyID returns id of the only private key inside keys directory.
+// When there is none, the shared HMAC secret keeps signing tokens.
+// When there is more than one, the signing key id must be configured explicitly.
+func (km *KeyManager) defaultSigningKeyID() (string, error) {
+	found := legacyKeyID
+	for id, key := range km.keys {
+		if id == legacyKeyID || key.signKey == nil {
+			continue
+		}
+		if found != legacyKeyID {
+			return "", fmt.Errorf("more than one private key found, JWT_SIGNING_KEY_ID must be set")
+		}
+		found = id
+	}
+
+	return found, nil
+}
+
+// keyFunc selects verification key by kid header and makes sure the token uses the algorithm of that key.
+func (km *KeyManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := km.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected token signing method")
+	}
+
+	return key.verifyKey, nil
+}
+
+// loadDir loads every PEM file inside dir as a key.
+func (km *KeyManager) loadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExtension))
+	if err != nil {
+		return fmt.Errorf("error listing keys directory %s: %w", dir, err)
+	}
+
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), keyFileExtension)
+
+		key, err := loadKey(id, file)
+		if err != nil {
+			return err
+		}
+		km.keys[id] = key
+	}
+
+	return nil
+}
+
+// loadKey loads a private or public key from a PEM file.
+func loadKey(id, file string) (*Key, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("error reading key %s: %w", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("error decoding key %s: no PEM block found", file)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("error parsing key %s: unsupported PEM block type %q", file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing key %s: %w", file, err)
+	}
+
+	key, err := newKey(id, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("error loading key %s: %w", file, err)
+	}
+
+	return key, nil
+}
+
+// newKey builds a Key and picks its signing method from the key type.
+func newKey(id string, parsed interface{}) (*Key, error) {
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	case *ecdsa.PrivateKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: id, Method: method, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *ecdsa.PublicKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: id, Method: method, verifyKey: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Method: SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Method: SigningMethodEdDSA, verifyKey: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// ecdsaMethod picks ECDSA signing method for a curve.
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	default:
+		return nil, fmt.Errorf("unsupported elliptic curve %s", curve.Params().Name)
+	}
+}
+
+// jwk returns public part of the key, symmetric keys are never exposed.
+func (k *Key) jwk() (JSONWebKey, bool) {
+	jwk := JSONWebKey{
+		KeyID:     k.ID,
+		Use:       "sig",
+		Algorithm: k.Method.Alg(),
+	}
+
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = jwt.EncodeSegment(pub.N.Bytes())
+		jwk.E = jwt.EncodeSegment(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = jwt.EncodeSegment(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = jwt.EncodeSegment(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = jwt.EncodeSegment(pub)
+	default:
+		return JSONWebKey{}, false
+	}
+
+	return jwk, true
+}
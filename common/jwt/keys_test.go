@@ -0,0 +1,209 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/config"
+	commonJwt "grpc-starter/common/jwt"
+)
+
+func writePrivateKey(t *testing.T, dir, id string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, id, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, id string, key interface{}) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, id, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, dir, id, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, id+".pem"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyManager_SignAndParse(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name string
+		key  interface{}
+		alg  string
+	}{
+		{name: "rsa key signs with RS256", key: rsaKey, alg: "RS256"},
+		{name: "ecdsa P-256 key signs with ES256", key: ecKey, alg: "ES256"},
+		{name: "ed25519 key signs with EdDSA", key: edKey, alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePrivateKey(t, dir, "key-1", tt.key)
+
+			km, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: dir})
+			assert.Nil(t, err)
+
+			claims := &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: uuid.New()}
+			signed, err := km.Sign(claims)
+			assert.Nil(t, err)
+
+			parsed := &commonJwt.CustomClaims{}
+			token, err := km.Parse(signed, parsed)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.alg, token.Method.Alg())
+			assert.Equal(t, "key-1", token.Header["kid"])
+			assert.Equal(t, claims.Subject, parsed.Subject)
+		})
+	}
+
+	t.Run("shared secret is used when no keys directory is configured", func(t *testing.T) {
+		km, err := commonJwt.NewKeyManager(config.JWTConfig{SecretKey: "secret"})
+		assert.Nil(t, err)
+
+		signed, err := km.Sign(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		token, err := km.Parse(signed, &commonJwt.CustomClaims{})
+		assert.Nil(t, err)
+		assert.Equal(t, "HS256", token.Method.Alg())
+		assert.Empty(t, km.JWKS().Keys)
+	})
+}
+
+func TestKeyManager_Rotation(t *testing.T) {
+	t.Run("token signed by retired key is still valid", func(t *testing.T) {
+		oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+
+		oldDir := t.TempDir()
+		writePrivateKey(t, oldDir, "old", oldKey)
+		oldManager, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: oldDir})
+		assert.Nil(t, err)
+
+		signed, err := oldManager.Sign(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		dir := t.TempDir()
+		writePublicKey(t, dir, "old", &oldKey.PublicKey)
+		writePrivateKey(t, dir, "new", newKey)
+		km, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: dir, SigningKeyID: "new"})
+		assert.Nil(t, err)
+
+		_, err = km.Parse(signed, &commonJwt.CustomClaims{})
+		assert.Nil(t, err)
+
+		signed, err = km.Sign(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+		token, err := km.Parse(signed, &commonJwt.CustomClaims{})
+		assert.Nil(t, err)
+		assert.Equal(t, "new", token.Header["kid"])
+	})
+
+	t.Run("retired key cannot be used to sign", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		dir := t.TempDir()
+		writePublicKey(t, dir, "old", &key.PublicKey)
+
+		_, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: dir, SigningKeyID: "old"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("signing key id is required when there is more than one private key", func(t *testing.T) {
+		first, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		_, second, _ := ed25519.GenerateKey(rand.Reader)
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "first", first)
+		writePrivateKey(t, dir, "second", second)
+
+		_, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: dir})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("token with unknown key id is rejected", func(t *testing.T) {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		otherDir := t.TempDir()
+		writePrivateKey(t, otherDir, "other", key)
+		other, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: otherDir})
+		assert.Nil(t, err)
+
+		signed, err := other.Sign(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		km, err := commonJwt.NewKeyManager(config.JWTConfig{SecretKey: "secret"})
+		assert.Nil(t, err)
+
+		_, err = km.Parse(signed, &commonJwt.CustomClaims{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("token using another algorithm than its key is rejected", func(t *testing.T) {
+		km, err := commonJwt.NewKeyManager(config.JWTConfig{SecretKey: "secret"})
+		assert.Nil(t, err)
+
+		// alg "none" with empty kid must not fall back to the shared secret
+		_, err = km.Parse("eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiIxIn0.", &commonJwt.CustomClaims{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestKeyManager_JWKS(t *testing.T) {
+	t.Run("publishes public part of every asymmetric key", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		edPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "rsa", rsaKey)
+		writePublicKey(t, dir, "ec", &ecKey.PublicKey)
+		writePublicKey(t, dir, "ed", edPublic)
+
+		km, err := commonJwt.NewKeyManager(config.JWTConfig{KeysDir: dir, SecretKey: "secret"})
+		assert.Nil(t, err)
+
+		set := km.JWKS()
+		assert.Len(t, set.Keys, 3)
+
+		keys := map[string]commonJwt.JSONWebKey{}
+		for _, key := range set.Keys {
+			assert.Equal(t, "sig", key.Use)
+			keys[key.KeyID] = key
+		}
+
+		assert.Equal(t, "RSA", keys["rsa"].KeyType)
+		assert.Equal(t, "RS256", keys["rsa"].Algorithm)
+		assert.Equal(t, "AQAB", keys["rsa"].E)
+		assert.NotEmpty(t, keys["rsa"].N)
+
+		assert.Equal(t, "EC", keys["ec"].KeyType)
+		assert.Equal(t, "ES384", keys["ec"].Algorithm)
+		assert.Equal(t, "P-384", keys["ec"].Curve)
+		assert.Len(t, keys["ec"].X, 64)
+		assert.Len(t, keys["ec"].Y, 64)
+
+		assert.Equal(t, "OKP", keys["ed"].KeyType)
+		assert.Equal(t, "EdDSA", keys["ed"].Algorithm)
+		assert.Equal(t, "Ed25519", keys["ed"].Curve)
+	})
+}
@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	authv1 "grpc-starter/api/auth/v1"
+)
+
+// reflectionMethodPrefix is the prefix of gRPC server reflection methods, which are not declared in our protos
+const reflectionMethodPrefix = "/grpc.reflection."
+
+// policies caches authorization policy per full method name
+var policies sync.Map
+
+// reflectionPolicy is the policy of gRPC server reflection, it is public so that debugging clients such as Evans
+// can list services without a token. Reflection is only registered for servers whose port is not exposed.
+var reflectionPolicy = &authv1.Policy{Access: authv1.Access_ACCESS_PUBLIC}
+
+// MethodPolicy finds the authorization policy declared by the (starter.auth.v1.policy) option of an RPC.
+// fullMethod is in the form of /package.Service/Method, as given by grpc.Method.
+// It returns error when the method is unknown or has no policy, such method must be rejected.
+// gRPC server reflection methods are exempted explicitly, they are public.
+func MethodPolicy(fullMethod string) (*authv1.Policy, error) {
+	if strings.HasPrefix(fullMethod, reflectionMethodPrefix) {
+		return reflectionPolicy, nil
+	}
+
+	if policy, ok := policies.Load(fullMethod); ok {
+		return policy.(*authv1.Policy), nil
+	}
+
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("method %s is not found: %w", fullMethod, err)
+	}
+
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a method", fullMethod)
+	}
+
+	options, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok || options == nil {
+		return nil, fmt.Errorf("method %s declares no authorization policy", fullMethod)
+	}
+
+	policy, ok := proto.GetExtension(options, authv1.E_Policy).(*authv1.Policy)
+	if !ok || policy == nil || policy.GetAccess() == authv1.Access_ACCESS_UNSPECIFIED {
+		return nil, fmt.Errorf("method %s declares no authorization policy", fullMethod)
+	}
+
+	policies.Store(fullMethod, policy)
+
+	return policy, nil
+}
+
+// HasAnyRole checks whether roles contains at least one of the required roles.
+// No required role means any role is allowed.
+func HasAnyRole(roles []string, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+
+	for _, r := range required {
+		for _, role := range roles {
+			if role == r {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasAllPermissions checks whether scopes contains every required permission.
+func HasAllPermissions(scopes []string, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, scope := range scopes {
+			if scope == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
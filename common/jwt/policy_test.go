@@ -0,0 +1,74 @@
+package jwt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authv1 "grpc-starter/api/auth/v1"
+	_ "grpc-starter/api/user/v1"
+	commonJwt "grpc-starter/common/jwt"
+)
+
+func TestMethodPolicy(t *testing.T) {
+	t.Run("public method", func(t *testing.T) {
+		policy, err := commonJwt.MethodPolicy("/starter.user.v1.UserService/Login")
+		assert.Nil(t, err)
+		assert.Equal(t, authv1.Access_ACCESS_PUBLIC, policy.GetAccess())
+	})
+
+	t.Run("authenticated method", func(t *testing.T) {
+		policy, err := commonJwt.MethodPolicy("/starter.user.v1.UserService/LogoutAll")
+		assert.Nil(t, err)
+		assert.Equal(t, authv1.Access_ACCESS_AUTHENTICATED, policy.GetAccess())
+	})
+
+	t.Run("unknown method is rejected", func(t *testing.T) {
+		_, err := commonJwt.MethodPolicy("/starter.user.v1.UserService/Unknown")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("server reflection is public", func(t *testing.T) {
+		for _, method := range []string{
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+		} {
+			policy, err := commonJwt.MethodPolicy(method)
+			assert.Nil(t, err)
+			assert.Equal(t, authv1.Access_ACCESS_PUBLIC, policy.GetAccess())
+		}
+	})
+
+	t.Run("method without policy is rejected", func(t *testing.T) {
+		_, err := commonJwt.MethodPolicy("/grpc.health.v1.Health/Check")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestHasAnyRole(t *testing.T) {
+	t.Run("no required role allows anyone", func(t *testing.T) {
+		assert.True(t, commonJwt.HasAnyRole(nil, nil))
+	})
+
+	t.Run("one of the required roles is enough", func(t *testing.T) {
+		assert.True(t, commonJwt.HasAnyRole([]string{"user", "admin"}, []string{"admin", "support"}))
+	})
+
+	t.Run("missing required role is rejected", func(t *testing.T) {
+		assert.False(t, commonJwt.HasAnyRole([]string{"user"}, []string{"admin"}))
+	})
+}
+
+func TestHasAllPermissions(t *testing.T) {
+	t.Run("no required permission allows anyone", func(t *testing.T) {
+		assert.True(t, commonJwt.HasAllPermissions(nil, nil))
+	})
+
+	t.Run("every required permission is granted", func(t *testing.T) {
+		assert.True(t, commonJwt.HasAllPermissions([]string{"users.read", "roles.assign"}, []string{"roles.assign"}))
+	})
+
+	t.Run("missing one of the required permissions is rejected", func(t *testing.T) {
+		assert.False(t, commonJwt.HasAllPermissions([]string{"users.read"}, []string{"users.read", "roles.assign"}))
+	})
+}
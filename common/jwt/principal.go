@@ -0,0 +1,64 @@
+package jwt
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// contextKeyPrincipal is the context key of authenticated principal
+var contextKeyPrincipal = contextKey("principal")
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// UserID is the subject of the access token
+	UserID uuid.UUID
+	// TokenID is the jti of the access token
+	TokenID string
+	// SessionID is the session the access token is issued for
+	SessionID uuid.UUID
+	// Roles are roles of the user when the access token was issued
+	Roles []string
+	// Scopes are permissions granted to the user through its roles
+	Scopes []string
+	// Issuer is the issuer of the access token
+	Issuer string
+	// ExpiresAt is the expiration time of the access token
+	ExpiresAt time.Time
+}
+
+// NewPrincipal creates an instance of Principal from verified claims.
+func NewPrincipal(claims *CustomClaims) *Principal {
+	return &Principal{
+		UserID:    claims.Subject,
+		TokenID:   claims.ID,
+		SessionID: claims.SessionID,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+		Issuer:    claims.Issuer,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}
+}
+
+// HasRole checks whether the principal has the given role.
+func (p *Principal) HasRole(role string) bool {
+	return HasAnyRole(p.Roles, []string{role})
+}
+
+// HasPermissions checks whether the principal has all of the given permissions.
+func (p *Principal) HasPermissions(permissions ...string) bool {
+	return HasAllPermissions(p.Scopes, permissions)
+}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKeyPrincipal, principal)
+}
+
+// PrincipalFromContext gets the authenticated principal from the context.
+// It is only available for RPCs that are not public.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKeyPrincipal).(*Principal)
+	return principal, ok && principal != nil
+}
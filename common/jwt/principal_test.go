@@ -0,0 +1,69 @@
+package jwt_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	commonJwt "grpc-starter/common/jwt"
+)
+
+func TestNewPrincipal(t *testing.T) {
+	t.Run("principal is built from subject, not token id", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+		claims := &commonJwt.CustomClaims{
+			ID:        uuid.New().String(),
+			Subject:   uuid.New(),
+			Issuer:    "starter.test",
+			ExpiresAt: expiresAt.Unix(),
+			Roles:     []string{"admin"},
+			Scopes:    []string{"roles.assign"},
+		}
+
+		principal := commonJwt.NewPrincipal(claims)
+
+		assert.Equal(t, claims.Subject, principal.UserID)
+		assert.Equal(t, claims.ID, principal.TokenID)
+		assert.Equal(t, "starter.test", principal.Issuer)
+		assert.True(t, expiresAt.Equal(principal.ExpiresAt))
+		assert.True(t, principal.HasRole("admin"))
+		assert.True(t, principal.HasPermissions("roles.assign"))
+		assert.False(t, principal.HasPermissions("users.write"))
+	})
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	t.Run("principal is found in context", func(t *testing.T) {
+		principal := &commonJwt.Principal{UserID: uuid.New()}
+		ctx := commonJwt.ContextWithPrincipal(context.Background(), principal)
+
+		found, ok := commonJwt.PrincipalFromContext(ctx)
+
+		assert.True(t, ok)
+		assert.Equal(t, principal, found)
+	})
+
+	t.Run("context without principal", func(t *testing.T) {
+		_, ok := commonJwt.PrincipalFromContext(context.Background())
+		assert.False(t, ok)
+	})
+}
+
+func TestRequirePermissions(t *testing.T) {
+	t.Run("principal having every permission is allowed", func(t *testing.T) {
+		ctx := commonJwt.ContextWithPrincipal(context.Background(), &commonJwt.Principal{Scopes: []string{"users.read"}})
+		assert.Nil(t, commonJwt.RequirePermissions(ctx, "users.read"))
+	})
+
+	t.Run("principal missing a permission is denied", func(t *testing.T) {
+		ctx := commonJwt.ContextWithPrincipal(context.Background(), &commonJwt.Principal{Scopes: []string{"users.read"}})
+		assert.NotNil(t, commonJwt.RequirePermissions(ctx, "users.write"))
+	})
+
+	t.Run("unauthenticated request is denied", func(t *testing.T) {
+		assert.NotNil(t, commonJwt.RequirePermissions(context.Background(), "users.read"))
+	})
+}
@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+
+	"grpc-starter/common/cache"
+)
+
+const (
+	// revokedTokenKey is the cache key of a revoked token, identified by its jti
+	revokedTokenKey = "jwt:revoked:%s"
+	// revokedSessionKey is the cache key of a revoked session, identified by its id
+	revokedSessionKey = "jwt:revoked-session:%s"
+	// tokenGenerationKey is the cache key of user token generation
+	tokenGenerationKey = "jwt:generation:%s"
+)
+
+// RevocationStore keeps revoked tokens and user token generations in cache.
+//
+// A single token is revoked by storing its jti until the token expires.
+// All tokens of a user are revoked by bumping the user token generation,
+// every token carrying an older generation is rejected afterwards.
+type RevocationStore struct {
+	cache cache.ContextCacheable
+}
+
+// NewRevocationStore creates an instance of RevocationStore.
+func NewRevocationStore(cache cache.ContextCacheable) *RevocationStore {
+	return &RevocationStore{
+		cache: cache,
+	}
+}
+
+// RevokeToken revokes a single token until it expires.
+func (s *RevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := int(time.Until(expiresAt).Seconds())
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := s.cache.Set(ctx, fmt.Sprintf(revokedTokenKey, tokenID), true, ttl); err != nil {
+		return fmt.Errorf("error revoking token %s: %w", tokenID, err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks whether a single token has been revoked.
+func (s *RevocationStore) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return s.cache.Exists(ctx, fmt.Sprintf(revokedTokenKey, tokenID))
+}
+
+// RevokeSession revokes every token issued for a session.
+// The session only needs to be remembered as long as the longest living token, hence the ttl.
+func (s *RevocationStore) RevokeSession(ctx context.Context, sessionID uuid.UUID, ttl time.Duration) error {
+	if err := s.cache.Set(ctx, fmt.Sprintf(revokedSessionKey, sessionID), true, int(ttl.Seconds())); err != nil {
+		return fmt.Errorf("error revoking session %s: %w", sessionID, err)
+	}
+
+	return nil
+}
+
+// IsSessionRevoked checks whether a session has been revoked.
+func (s *RevocationStore) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return s.cache.Exists(ctx, fmt.Sprintf(revokedSessionKey, sessionID))
+}
+
+// RevokeAll revokes every token of a user issued before now.
+// The generation only needs to outlive the longest living token, hence the ttl.
+func (s *RevocationStore) RevokeAll(ctx context.Context, userID uuid.UUID, ttl time.Duration) error {
+	if err := s.cache.Set(ctx, fmt.Sprintf(tokenGenerationKey, userID), time.Now().UnixNano(), int(ttl.Seconds())); err != nil {
+		return fmt.Errorf("error revoking tokens of user %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// Generation returns current token generation of a user.
+// It returns zero when the user has never revoked all of their tokens.
+func (s *RevocationStore) Generation(ctx context.Context, userID uuid.UUID) (int64, error) {
+	data, err := s.cache.Get(ctx, fmt.Sprintf(tokenGenerationKey, userID))
+	if errors.Is(err, redigo.ErrNil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var generation int64
+	if err := json.Unmarshal(data, &generation); err != nil {
+		return 0, fmt.Errorf("error decoding token generation of user %s: %w", userID, err)
+	}
+
+	return generation, nil
+}
+
+// IsRevoked checks whether the token described by claims has been revoked,
+// either by its jti, by its session or by a newer user token generation.
+func (s *RevocationStore) IsRevoked(ctx context.Context, claims *CustomClaims) (bool, error) {
+	revoked, err := s.IsTokenRevoked(ctx, claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	if claims.SessionID != uuid.Nil {
+		revoked, err = s.IsSessionRevoked(ctx, claims.SessionID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	generation, err := s.Generation(ctx, claims.Subject)
+	if err != nil {
+		return false, err
+	}
+
+	return claims.Generation < generation, nil
+}
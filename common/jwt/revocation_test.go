@@ -0,0 +1,122 @@
+package jwt_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/common/redis"
+)
+
+func newRevocationStore(t *testing.T) *commonJwt.RevocationStore {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	return commonJwt.NewRevocationStore(redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second))
+}
+
+func TestRevocationStore_RevokeToken(t *testing.T) {
+	t.Run("revoked token is reported as revoked", func(t *testing.T) {
+		store := newRevocationStore(t)
+		claims := &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: uuid.New()}
+
+		err := store.RevokeToken(context.Background(), claims.ID, time.Now().Add(time.Minute))
+		assert.Nil(t, err)
+
+		revoked, err := store.IsRevoked(context.Background(), claims)
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("other token is not reported as revoked", func(t *testing.T) {
+		store := newRevocationStore(t)
+
+		err := store.RevokeToken(context.Background(), uuid.New().String(), time.Now().Add(time.Minute))
+		assert.Nil(t, err)
+
+		revoked, err := store.IsRevoked(context.Background(), &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: uuid.New()})
+		assert.Nil(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestRevocationStore_RevokeAll(t *testing.T) {
+	t.Run("tokens of older generation are revoked", func(t *testing.T) {
+		store := newRevocationStore(t)
+		userID := uuid.New()
+
+		before, err := store.Generation(context.Background(), userID)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), before)
+
+		err = store.RevokeAll(context.Background(), userID, time.Minute)
+		assert.Nil(t, err)
+
+		after, err := store.Generation(context.Background(), userID)
+		assert.Nil(t, err)
+		assert.Greater(t, after, before)
+
+		revoked, err := store.IsRevoked(context.Background(), &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: userID, Generation: before})
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = store.IsRevoked(context.Background(), &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: userID, Generation: after})
+		assert.Nil(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestRevocationStore_Generation(t *testing.T) {
+	t.Run("expired generation is reported as zero", func(t *testing.T) {
+		server, err := miniredis.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(server.Close)
+
+		store := commonJwt.NewRevocationStore(redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second))
+		userID := uuid.New()
+
+		err = store.RevokeAll(context.Background(), userID, time.Minute)
+		assert.Nil(t, err)
+
+		server.FastForward(time.Minute)
+
+		generation, err := store.Generation(context.Background(), userID)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), generation)
+	})
+}
+
+func TestRevocationStore_RevokeSession(t *testing.T) {
+	t.Run("tokens of revoked session are revoked", func(t *testing.T) {
+		store := newRevocationStore(t)
+		sessionID := uuid.New()
+
+		err := store.RevokeSession(context.Background(), sessionID, time.Minute)
+		assert.Nil(t, err)
+
+		revoked, err := store.IsRevoked(context.Background(), &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: uuid.New(), SessionID: sessionID})
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("tokens of other session are not revoked", func(t *testing.T) {
+		store := newRevocationStore(t)
+
+		err := store.RevokeSession(context.Background(), uuid.New(), time.Minute)
+		assert.Nil(t, err)
+
+		revoked, err := store.IsRevoked(context.Background(), &commonJwt.CustomClaims{ID: uuid.New().String(), Subject: uuid.New(), SessionID: uuid.New()})
+		assert.Nil(t, err)
+		assert.False(t, revoked)
+	})
+}
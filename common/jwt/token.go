@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"grpc-starter/common/config"
+)
+
+// Clock tells the current time. It is swapped by a fake clock in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function into a Clock.
+type ClockFunc func() time.Time
+
+// Now returns the current time.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is a Clock backed by time.Now.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// TokenIssuer issues signed access tokens.
+type TokenIssuer struct {
+	cfg        config.JWTConfig
+	keyManager *KeyManager
+	clock      Clock
+}
+
+// NewTokenIssuer creates an instance of TokenIssuer.
+func NewTokenIssuer(cfg config.Config, keyManager *KeyManager, clock Clock) *TokenIssuer {
+	return &TokenIssuer{
+		cfg:        cfg.JWTConfig,
+		keyManager: keyManager,
+		clock:      clock,
+	}
+}
+
+// Issue fills registered claims (jti, iat, nbf, exp, iss and aud) and signs claims.
+// The expiration time is available in claims.ExpiresAt once the token is issued.
+func (i *TokenIssuer) Issue(claims *CustomClaims) (string, error) {
+	now := i.clock.Now()
+
+	claims.ID = uuid.New().String()
+	claims.IssuedAt = now.Unix()
+	claims.NotBefore = now.Unix()
+	claims.ExpiresAt = now.Add(i.cfg.AccessTokenTTL).Unix()
+	claims.Issuer = i.cfg.Issuer
+	claims.Audience = i.cfg.Audience
+
+	return i.keyManager.Sign(claims)
+}
+
+// TokenVerifier verifies access tokens issued by TokenIssuer.
+type TokenVerifier struct {
+	cfg        config.JWTConfig
+	keyManager *KeyManager
+	clock      Clock
+}
+
+// NewTokenVerifier creates an instance of TokenVerifier.
+func NewTokenVerifier(cfg config.Config, keyManager *KeyManager, clock Clock) *TokenVerifier {
+	return &TokenVerifier{
+		cfg:        cfg.JWTConfig,
+		keyManager: keyManager,
+		clock:      clock,
+	}
+}
+
+// Verify verifies signature, time based claims, issuer and audience of a token.
+func (v *TokenVerifier) Verify(accessToken string) (*CustomClaims, error) {
+	claims := &CustomClaims{}
+	if _, err := v.keyManager.Parse(accessToken, claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if err := claims.Validate(v.clock.Now(), v.cfg.ClockSkew); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if claims.Issuer != v.cfg.Issuer {
+		return nil, fmt.Errorf("invalid token: unexpected issuer %q", claims.Issuer)
+	}
+
+	if claims.Audience != v.cfg.Audience {
+		return nil, fmt.Errorf("invalid token: unexpected audience %q", claims.Audience)
+	}
+
+	return claims, nil
+}
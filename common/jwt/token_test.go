@@ -0,0 +1,120 @@
+package jwt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/config"
+	commonJwt "grpc-starter/common/jwt"
+)
+
+func newTestConfig() config.Config {
+	return config.Config{
+		JWTConfig: config.JWTConfig{
+			SecretKey:      "secret",
+			Issuer:         "starter.test",
+			Audience:       "starter.api",
+			AccessTokenTTL: 15 * time.Minute,
+			ClockSkew:      30 * time.Second,
+		},
+	}
+}
+
+func newTestIssuerAndVerifier(t *testing.T, cfg config.Config, now *time.Time) (*commonJwt.TokenIssuer, *commonJwt.TokenVerifier) {
+	km, err := commonJwt.NewKeyManager(cfg.JWTConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := commonJwt.ClockFunc(func() time.Time { return *now })
+
+	return commonJwt.NewTokenIssuer(cfg, km, clock), commonJwt.NewTokenVerifier(cfg, km, clock)
+}
+
+func TestTokenIssuer_Issue(t *testing.T) {
+	t.Run("fills registered claims", func(t *testing.T) {
+		now := time.Date(2022, 7, 1, 9, 0, 0, 0, time.UTC)
+		issuer, verifier := newTestIssuerAndVerifier(t, newTestConfig(), &now)
+
+		claims := &commonJwt.CustomClaims{Subject: uuid.New()}
+		token, err := issuer.Issue(claims)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, claims.ID)
+		assert.Equal(t, now.Unix(), claims.IssuedAt)
+		assert.Equal(t, now.Add(15*time.Minute).Unix(), claims.ExpiresAt)
+
+		verified, err := verifier.Verify(token)
+		assert.Nil(t, err)
+		assert.Equal(t, claims.Subject, verified.Subject)
+		assert.Equal(t, "starter.test", verified.Issuer)
+		assert.Equal(t, "starter.api", verified.Audience)
+	})
+}
+
+func TestTokenVerifier_Verify(t *testing.T) {
+	t.Run("expired token is rejected", func(t *testing.T) {
+		now := time.Date(2022, 7, 1, 9, 0, 0, 0, time.UTC)
+		issuer, verifier := newTestIssuerAndVerifier(t, newTestConfig(), &now)
+
+		token, err := issuer.Issue(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		now = now.Add(15*time.Minute + time.Minute)
+		_, err = verifier.Verify(token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("token expired within clock skew is accepted", func(t *testing.T) {
+		now := time.Date(2022, 7, 1, 9, 0, 0, 0, time.UTC)
+		issuer, verifier := newTestIssuerAndVerifier(t, newTestConfig(), &now)
+
+		token, err := issuer.Issue(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		now = now.Add(15*time.Minute + 10*time.Second)
+		_, err = verifier.Verify(token)
+		assert.Nil(t, err)
+	})
+
+	t.Run("token issued in the future is rejected", func(t *testing.T) {
+		now := time.Date(2022, 7, 1, 9, 0, 0, 0, time.UTC)
+		issuer, verifier := newTestIssuerAndVerifier(t, newTestConfig(), &now)
+
+		token, err := issuer.Issue(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		now = now.Add(-time.Minute)
+		_, err = verifier.Verify(token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("token of another issuer is rejected", func(t *testing.T) {
+		now := time.Now()
+		cfg := newTestConfig()
+		issuer, _ := newTestIssuerAndVerifier(t, cfg, &now)
+
+		token, err := issuer.Issue(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		cfg.JWTConfig.Issuer = "starter.other"
+		_, verifier := newTestIssuerAndVerifier(t, cfg, &now)
+		_, err = verifier.Verify(token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("token of another audience is rejected", func(t *testing.T) {
+		now := time.Now()
+		cfg := newTestConfig()
+		issuer, _ := newTestIssuerAndVerifier(t, cfg, &now)
+
+		token, err := issuer.Issue(&commonJwt.CustomClaims{Subject: uuid.New()})
+		assert.Nil(t, err)
+
+		cfg.JWTConfig.Audience = "starter.admin"
+		_, verifier := newTestIssuerAndVerifier(t, cfg, &now)
+		_, err = verifier.Verify(token)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,2 @@
+// Package oidc verifies ID tokens issued by OpenID Connect providers such as Google.
+package oidc
@@ -0,0 +1,246 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/sync/singleflight"
+
+	commonJwt "grpc-starter/common/jwt"
+)
+
+const (
+	// discoveryPath is the path of OpenID Connect discovery document relative to the issuer
+	discoveryPath = "/.well-known/openid-configuration"
+	// minRefreshInterval limits how often keys are fetched again, whether the last fetch succeeded or not
+	minRefreshInterval = 10 * time.Second
+)
+
+// discovery holds the fields of OpenID Connect discovery document that are used
+type discovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// remoteKeySet fetches and caches signing keys of a provider
+type remoteKeySet struct {
+	provider    Provider
+	client      *http.Client
+	ttl         time.Duration
+	now         func() time.Time
+	group       singleflight.Group
+	mu          sync.Mutex
+	jwksURL     string
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// newRemoteKeySet creates remoteKeySet of provider
+func newRemoteKeySet(provider Provider, client *http.Client, ttl time.Duration, now func() time.Time) *remoteKeySet {
+	return &remoteKeySet{
+		provider: provider,
+		client:   client,
+		ttl:      ttl,
+		now:      now,
+		jwksURL:  provider.JWKSURL,
+	}
+}
+
+// key returns public key by key id.
+//
+// Keys are fetched again when they are expired or the key id is unknown, but no more than once
+// per minRefreshInterval whether fetching succeeds or not. Concurrent callers share a single fetch
+// that runs without holding the lock, and expired keys keep being served while fetching them fails.
+func (s *remoteKeySet) key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	now := s.now()
+	key, ok := s.keys[kid]
+	fresh := now.Sub(s.fetchedAt) < s.ttl
+	throttled := !s.attemptedAt.IsZero() && now.Sub(s.attemptedAt) < minRefreshInterval
+	s.mu.Unlock()
+
+	if ok && (fresh || throttled) {
+		return key, nil
+	}
+
+	if throttled {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	results := s.group.DoChan(s.provider.Name, func() (interface{}, error) {
+		return nil, s.refresh()
+	})
+
+	var err error
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		err = result.Err
+	}
+
+	s.mu.Lock()
+	key, ok = s.keys[kid]
+	s.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh fetches signing keys, discovering their url first when it is not configured.
+// It runs detached from the context of every caller and is bounded by the client timeout instead,
+// so a caller giving up does not fail the others.
+func (s *remoteKeySet) refresh() error {
+	ctx := context.Background()
+
+	s.mu.Lock()
+	attemptedAt := s.now()
+	s.attemptedAt = attemptedAt
+	jwksURL := s.jwksURL
+	s.mu.Unlock()
+
+	if jwksURL == "" {
+		discovered, err := s.discover(ctx)
+		if err != nil {
+			return err
+		}
+		jwksURL = discovered
+
+		s.mu.Lock()
+		s.jwksURL = jwksURL
+		s.mu.Unlock()
+	}
+
+	var set commonJwt.JSONWebKeySet
+	if err := s.getJSON(ctx, jwksURL, &set); err != nil {
+		return fmt.Errorf("error fetching signing keys: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := publicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = attemptedAt
+	s.mu.Unlock()
+
+	return nil
+}
+
+// discover finds url of signing keys from the discovery document of the first issuer
+func (s *remoteKeySet) discover(ctx context.Context) (string, error) {
+	if len(s.provider.Issuers) == 0 {
+		return "", fmt.Errorf("provider %s has no issuer to discover", s.provider.Name)
+	}
+
+	issuer := s.provider.Issuers[0]
+	var doc discovery
+	if err := s.getJSON(ctx, strings.TrimSuffix(issuer, "/")+discoveryPath, &doc); err != nil {
+		return "", fmt.Errorf("error fetching discovery document: %w", err)
+	}
+
+	if doc.Issuer != issuer {
+		return "", fmt.Errorf("discovery document issuer %q does not match %q", doc.Issuer, issuer)
+	}
+
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("discovery document of %q has no jwks_uri", issuer)
+	}
+
+	return doc.JWKSURI, nil
+}
+
+// getJSON fetches url and decodes its json body into v
+func (s *remoteKeySet) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// publicKey converts RSA or EC json web key into its public key
+func publicKey(jwk commonJwt.JSONWebKey) (interface{}, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := jwt.DecodeSegment(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwt.DecodeSegment(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve %q", jwk.Curve)
+		}
+		x, err := jwt.DecodeSegment(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwt.DecodeSegment(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
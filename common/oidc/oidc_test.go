@@ -0,0 +1,281 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/oidc"
+	"grpc-starter/common/oidc/oidctest"
+)
+
+const (
+	testClientID = "starter-test"
+	testSkew     = time.Minute
+)
+
+func newTestVerifier(t *testing.T) (*oidctest.Issuer, *oidc.Verifier) {
+	issuer, server, err := oidctest.NewServer()
+	assert.Nil(t, err)
+	t.Cleanup(server.Close)
+
+	provider := oidc.Provider{
+		Name:      "test",
+		Issuers:   []string{issuer.URL},
+		ClientIDs: []string{testClientID},
+	}
+
+	return issuer, oidc.NewVerifier([]oidc.Provider{provider}, http.DefaultClient, time.Hour, testSkew, time.Now)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Run("valid id token is verified with discovered keys", func(t *testing.T) {
+		issuer, verifier := newTestVerifier(t)
+
+		rawIDToken, err := issuer.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		idToken, err := verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, "test", idToken.Provider)
+		assert.Equal(t, "subject-1", idToken.Subject)
+		assert.Equal(t, "user@starter.com", idToken.Email)
+		assert.True(t, idToken.EmailVerified)
+	})
+
+	t.Run("id token of issuer served on the configured issuer url is verified", func(t *testing.T) {
+		issuer, server, err := oidctest.NewServerOn("http://127.0.0.1:18090")
+		assert.Nil(t, err)
+		t.Cleanup(server.Close)
+
+		provider := oidc.Provider{
+			Name:      "test",
+			Issuers:   []string{"http://127.0.0.1:18090"},
+			ClientIDs: []string{testClientID},
+		}
+		verifier := oidc.NewVerifier([]oidc.Provider{provider}, http.DefaultClient, time.Hour, testSkew, time.Now)
+
+		rawIDToken, err := issuer.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		idToken, err := verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, "subject-1", idToken.Subject)
+	})
+
+	t.Run("email verified encoded as string is accepted", func(t *testing.T) {
+		issuer, verifier := newTestVerifier(t)
+
+		rawIDToken, err := issuer.Sign(jwt.MapClaims{
+			"iss":            issuer.URL,
+			"aud":            []string{testClientID},
+			"sub":            "subject-1",
+			"email_verified": "true",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		})
+		assert.Nil(t, err)
+
+		idToken, err := verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+		assert.True(t, idToken.EmailVerified)
+	})
+
+	t.Run("id token of another client is rejected", func(t *testing.T) {
+		issuer, verifier := newTestVerifier(t)
+
+		rawIDToken, err := issuer.IDToken("another-client", "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("expired id token is rejected", func(t *testing.T) {
+		issuer, verifier := newTestVerifier(t)
+
+		rawIDToken, err := issuer.Sign(jwt.MapClaims{
+			"iss": issuer.URL,
+			"aud": testClientID,
+			"sub": "subject-1",
+			"exp": time.Now().Add(-2 * testSkew).Unix(),
+		})
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("id token of unknown issuer is rejected", func(t *testing.T) {
+		_, verifier := newTestVerifier(t)
+		other, server, err := oidctest.NewServer()
+		assert.Nil(t, err)
+		defer server.Close()
+
+		rawIDToken, err := other.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.ErrorIs(t, err, oidc.ErrUnknownIssuer)
+	})
+
+	t.Run("id token signed by another key of the same issuer is rejected", func(t *testing.T) {
+		issuer, verifier := newTestVerifier(t)
+		forger, err := oidctest.NewIssuer(issuer.URL)
+		assert.Nil(t, err)
+
+		rawIDToken, err := forger.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("id token signed with hmac is rejected", func(t *testing.T) {
+		issuer, verifier := newTestVerifier(t)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iss": issuer.URL,
+			"aud": testClientID,
+			"sub": "subject-1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		rawIDToken, err := token.SignedString([]byte("secret"))
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.NotNil(t, err)
+	})
+}
+
+// flakyJWKS serves an issuer and counts requests for its signing keys, which fail while failing is set
+type flakyJWKS struct {
+	mu       sync.Mutex
+	requests int
+	failing  bool
+}
+
+func (f *flakyJWKS) fail(failing bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing = failing
+}
+
+func (f *flakyJWKS) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *flakyJWKS) handler(issuer http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks" {
+			f.mu.Lock()
+			f.requests++
+			failing := f.failing
+			f.mu.Unlock()
+
+			if failing {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		issuer.ServeHTTP(w, r)
+	})
+}
+
+func newFlakyTestVerifier(t *testing.T, ttl time.Duration, now func() time.Time) (*oidctest.Issuer, *flakyJWKS, *oidc.Verifier) {
+	server := httptest.NewUnstartedServer(nil)
+	issuer, err := oidctest.NewIssuer("http://" + server.Listener.Addr().String())
+	assert.Nil(t, err)
+
+	jwks := &flakyJWKS{}
+	server.Config.Handler = jwks.handler(issuer.Handler())
+	server.Start()
+	t.Cleanup(server.Close)
+
+	provider := oidc.Provider{
+		Name:      "test",
+		Issuers:   []string{issuer.URL},
+		ClientIDs: []string{testClientID},
+	}
+
+	return issuer, jwks, oidc.NewVerifier([]oidc.Provider{provider}, http.DefaultClient, ttl, testSkew, now)
+}
+
+func TestVerifier_Verify_KeyRefresh(t *testing.T) {
+	t.Run("unknown key ids fetch keys again at most once per refresh interval", func(t *testing.T) {
+		now := time.Now()
+		issuer, jwks, verifier := newFlakyTestVerifier(t, time.Hour, func() time.Time { return now })
+
+		rawIDToken, err := issuer.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+
+		for i := 0; i < 5; i++ {
+			forger, err := oidctest.NewIssuer(issuer.URL)
+			assert.Nil(t, err)
+			forged, err := forger.IDToken(testClientID, "subject-1", "user@starter.com", true)
+			assert.Nil(t, err)
+
+			_, err = verifier.Verify(context.Background(), forged)
+			assert.NotNil(t, err)
+		}
+		assert.Equal(t, 1, jwks.count())
+
+		now = now.Add(11 * time.Second)
+		forger, err := oidctest.NewIssuer(issuer.URL)
+		assert.Nil(t, err)
+		forged, err := forger.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), forged)
+		assert.NotNil(t, err)
+		assert.Equal(t, 2, jwks.count())
+	})
+
+	t.Run("failed fetch is not retried within the refresh interval", func(t *testing.T) {
+		now := time.Now()
+		issuer, jwks, verifier := newFlakyTestVerifier(t, time.Hour, func() time.Time { return now })
+		jwks.fail(true)
+
+		rawIDToken, err := issuer.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err = verifier.Verify(context.Background(), rawIDToken)
+			assert.NotNil(t, err)
+		}
+		assert.Equal(t, 1, jwks.count())
+
+		now = now.Add(11 * time.Second)
+		jwks.fail(false)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, jwks.count())
+	})
+
+	t.Run("expired keys are served while fetching them again fails", func(t *testing.T) {
+		now := time.Now()
+		issuer, jwks, verifier := newFlakyTestVerifier(t, time.Minute, func() time.Time { return now })
+
+		rawIDToken, err := issuer.IDToken(testClientID, "subject-1", "user@starter.com", true)
+		assert.Nil(t, err)
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+
+		now = now.Add(2 * time.Minute)
+		jwks.fail(true)
+
+		_, err = verifier.Verify(context.Background(), rawIDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, jwks.count())
+	})
+}
@@ -0,0 +1,152 @@
+// Package oidctest provides a local OpenID Connect issuer that signs ID tokens for tests.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	commonJwt "grpc-starter/common/jwt"
+)
+
+const (
+	// keySize is the size of RSA signing key in bits
+	keySize = 2048
+	// keyIDLength is the number of random bytes of key id
+	keyIDLength = 8
+	// jwksPath is the path of signing keys
+	jwksPath = "/jwks"
+	// tokenTTL is the lifetime of signed ID tokens
+	tokenTTL = time.Hour
+)
+
+// Issuer is a local OpenID Connect issuer, it serves discovery document and signing keys,
+// and signs ID tokens with a key generated on creation.
+type Issuer struct {
+	// URL is the issuer identifier, which is the base url the issuer is served on.
+	URL   string
+	key   *rsa.PrivateKey
+	keyID string
+}
+
+// NewIssuer creates Issuer served on url with a new signing key.
+func NewIssuer(url string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := make([]byte, keyIDLength)
+	if _, err := rand.Read(keyID); err != nil {
+		return nil, err
+	}
+
+	return &Issuer{
+		URL:   url,
+		key:   key,
+		keyID: hex.EncodeToString(keyID),
+	}, nil
+}
+
+// NewServer starts a new Issuer on a local test server, the caller has to close the server.
+func NewServer() (*Issuer, *httptest.Server, error) {
+	server := httptest.NewUnstartedServer(nil)
+
+	return startServer(server, "http://"+server.Listener.Addr().String())
+}
+
+// NewServerOn starts a new Issuer on a local test server listening on the host of issuerURL,
+// so a service configured with issuerURL as trusted issuer accepts its ID tokens. The caller has to close the server.
+func NewServerOn(issuerURL string) (*Issuer, *httptest.Server, error) {
+	parsed, err := url.Parse(issuerURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listener, err := net.Listen("tcp", parsed.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server := httptest.NewUnstartedServer(nil)
+	_ = server.Listener.Close()
+	server.Listener = listener
+
+	return startServer(server, issuerURL)
+}
+
+// startServer starts unstarted server serving a new Issuer with the given url
+func startServer(server *httptest.Server, issuerURL string) (*Issuer, *httptest.Server, error) {
+	issuer, err := NewIssuer(issuerURL)
+	if err != nil {
+		_ = server.Listener.Close()
+		return nil, nil, err
+	}
+
+	server.Config.Handler = issuer.Handler()
+	server.Start()
+
+	return issuer, server, nil
+}
+
+// Handler serves discovery document and signing keys of the issuer.
+func (i *Issuer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"issuer":                                i.URL,
+			"jwks_uri":                              i.URL + jwksPath,
+			"id_token_signing_alg_values_supported": []string{jwt.SigningMethodRS256.Alg()},
+		})
+	})
+	mux.HandleFunc(jwksPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, commonJwt.JSONWebKeySet{Keys: []commonJwt.JSONWebKey{{
+			KeyType:   "RSA",
+			KeyID:     i.keyID,
+			Use:       "sig",
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			N:         jwt.EncodeSegment(i.key.N.Bytes()),
+			E:         jwt.EncodeSegment(big.NewInt(int64(i.key.E)).Bytes()),
+		}}})
+	})
+
+	return mux
+}
+
+// IDToken signs ID token for audience with the given subject and email.
+func (i *Issuer) IDToken(audience string, subject string, email string, emailVerified bool) (string, error) {
+	now := time.Now()
+
+	return i.Sign(jwt.MapClaims{
+		"iss":            i.URL,
+		"aud":            audience,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": emailVerified,
+		"iat":            now.Unix(),
+		"exp":            now.Add(tokenTTL).Unix(),
+	})
+}
+
+// Sign signs arbitrary claims with the signing key of the issuer.
+func (i *Issuer) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.keyID
+
+	return token.SignedString(i.key)
+}
+
+// writeJSON writes v as json response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
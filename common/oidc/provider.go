@@ -0,0 +1,113 @@
+package oidc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// ProviderGoogle is the name of Google provider
+	ProviderGoogle = "google"
+	// googleIssuer is the issuer of Google ID tokens
+	googleIssuer = "https://accounts.google.com"
+	// googleLegacyIssuer is the issuer without scheme that Google still puts in some ID tokens
+	googleLegacyIssuer = "accounts.google.com"
+	// googleJWKSURL is the url of Google signing keys
+	googleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+)
+
+// Provider is an OpenID Connect provider whose ID tokens are accepted.
+type Provider struct {
+	// Name identifies the provider of linked identities, it must never change once identities are linked.
+	Name string
+	// Issuers are the accepted values of iss claim, the first one is used for discovery.
+	Issuers []string
+	// ClientIDs are the accepted values of aud claim.
+	ClientIDs []string
+	// JWKSURL is the url of signing keys, it is discovered from the first issuer when empty.
+	JWKSURL string
+}
+
+// NewGoogleProvider creates Provider for Sign in with Google accepting ID tokens of the given client ids.
+func NewGoogleProvider(clientIDs []string) Provider {
+	return Provider{
+		Name:      ProviderGoogle,
+		Issuers:   []string{googleIssuer, googleLegacyIssuer},
+		ClientIDs: clientIDs,
+		JWKSURL:   googleJWKSURL,
+	}
+}
+
+// IDToken holds verified claims of an ID token.
+type IDToken struct {
+	// Provider is the name of the provider that issued the token.
+	Provider      string
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// claims are the claims of an ID token that are used for verification and identity linking
+type claims struct {
+	Issuer          string       `json:"iss"`
+	Subject         string       `json:"sub"`
+	Audience        audience     `json:"aud"`
+	AuthorizedParty string       `json:"azp"`
+	ExpiresAt       int64        `json:"exp"`
+	IssuedAt        int64        `json:"iat"`
+	NotBefore       int64        `json:"nbf"`
+	Email           string       `json:"email"`
+	EmailVerified   flexibleBool `json:"email_verified"`
+	Name            string       `json:"name"`
+}
+
+// Valid always passes, Verifier validates the claims using its own clock and skew.
+func (c *claims) Valid() error {
+	return nil
+}
+
+// audience is aud claim, which is either a single string or an array of strings
+type audience []string
+
+// UnmarshalJSON decodes aud claim from string or array of strings.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("aud claim is neither string nor array of strings")
+	}
+	*a = multiple
+
+	return nil
+}
+
+// contains checks whether audience contains one of the values
+func (a audience) contains(values []string) bool {
+	for _, aud := range a {
+		for _, value := range values {
+			if aud == value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// flexibleBool is a boolean claim that some providers encode as string
+type flexibleBool bool
+
+// UnmarshalJSON decodes boolean claim from boolean or string.
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	*b = flexibleBool(string(data) == "true")
+	return nil
+}
@@ -0,0 +1,130 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// ErrUnknownIssuer is returned when ID token is issued by an issuer that is not configured.
+var ErrUnknownIssuer = errors.New("oidc: unknown issuer")
+
+// Verifier verifies ID tokens of the configured providers.
+type Verifier struct {
+	keySets   map[string]*remoteKeySet
+	providers map[string]Provider
+	skew      time.Duration
+	now       func() time.Time
+}
+
+// NewVerifier creates Verifier of providers, signing keys are fetched with client and cached for ttl.
+func NewVerifier(providers []Provider, client *http.Client, ttl time.Duration, skew time.Duration, now func() time.Time) *Verifier {
+	v := &Verifier{
+		keySets:   make(map[string]*remoteKeySet),
+		providers: make(map[string]Provider),
+		skew:      skew,
+		now:       now,
+	}
+
+	for _, provider := range providers {
+		keySet := newRemoteKeySet(provider, client, ttl, now)
+		for _, issuer := range provider.Issuers {
+			v.keySets[issuer] = keySet
+			v.providers[issuer] = provider
+		}
+	}
+
+	return v
+}
+
+// Verify verifies signature, issuer, audience, and time based claims of ID token.
+func (v *Verifier) Verify(ctx context.Context, rawIDToken string) (*IDToken, error) {
+	var unverified claims
+	if _, _, err := new(jwt.Parser).ParseUnverified(rawIDToken, &unverified); err != nil {
+		return nil, fmt.Errorf("oidc: malformed id token: %w", err)
+	}
+
+	provider, ok := v.providers[unverified.Issuer]
+	if !ok {
+		return nil, ErrUnknownIssuer
+	}
+	keySet := v.keySets[unverified.Issuer]
+
+	verified := &claims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	if _, err := parser.ParseWithClaims(rawIDToken, verified, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := keySet.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		return key, checkMethod(token.Method, key)
+	}); err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token: %w", err)
+	}
+
+	if err := v.validate(provider, verified); err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token: %w", err)
+	}
+
+	return &IDToken{
+		Provider:      provider.Name,
+		Issuer:        verified.Issuer,
+		Subject:       verified.Subject,
+		Email:         verified.Email,
+		EmailVerified: bool(verified.EmailVerified),
+		Name:          verified.Name,
+	}, nil
+}
+
+// validate validates claims of ID token whose signature is verified
+func (v *Verifier) validate(provider Provider, c *claims) error {
+	now := v.now()
+
+	if c.Subject == "" {
+		return fmt.Errorf("token has no subject")
+	}
+	if c.ExpiresAt == 0 {
+		return fmt.Errorf("token has no expiration time")
+	}
+	if now.Add(-v.skew).Unix() >= c.ExpiresAt {
+		return fmt.Errorf("token is expired")
+	}
+	if c.NotBefore != 0 && now.Add(v.skew).Unix() < c.NotBefore {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if c.IssuedAt != 0 && now.Add(v.skew).Unix() < c.IssuedAt {
+		return fmt.Errorf("token is used before issued")
+	}
+	if !c.Audience.contains(provider.ClientIDs) {
+		return fmt.Errorf("token is not issued for this client")
+	}
+	if len(c.Audience) > 1 && c.AuthorizedParty != "" && !audience([]string{c.AuthorizedParty}).contains(provider.ClientIDs) {
+		return fmt.Errorf("token is authorized for another party")
+	}
+
+	return nil
+}
+
+// checkMethod makes sure token is signed with an asymmetric method matching the key type,
+// so a public key can never be used as HMAC secret
+func checkMethod(method jwt.SigningMethod, key interface{}) error {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, ok := method.(*jwt.SigningMethodRSA); ok {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if _, ok := method.(*jwt.SigningMethodECDSA); ok {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unexpected signing method %s", method.Alg())
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const (
+	// commandStatusOK is the status tag of a command that succeeded
+	commandStatusOK = "ok"
+	// commandStatusError is the status tag of a command that failed
+	commandStatusError = "error"
+)
+
+var (
+	// KeyCommand is the tag of the redis command, e.g. GET
+	KeyCommand = tag.MustNewKey("redis_command")
+	// KeyStatus is the tag of the status of the redis command, either ok or error
+	KeyStatus = tag.MustNewKey("redis_status")
+
+	// commandLatency measures latency of redis commands, including getting a connection from the pool
+	commandLatency = stats.Float64("redis/client/latency", "Latency of redis commands", stats.UnitMilliseconds)
+)
+
+var (
+	// CommandLatencyView is the distribution of latency of redis commands by command and status
+	CommandLatencyView = &view.View{
+		Name:        "redis/client/latency",
+		Description: "Distribution of latency of redis commands, by command and status",
+		Measure:     commandLatency,
+		Aggregation: view.Distribution(0.1, 0.3, 0.6, 1, 2, 3, 5, 8, 10, 15, 20, 30, 50, 80, 100, 200, 300, 500, 1000, 2000, 5000),
+		TagKeys:     []tag.Key{KeyCommand, KeyStatus},
+	}
+
+	// CommandCountView is the count of redis commands by command and status
+	CommandCountView = &view.View{
+		Name:        "redis/client/commands",
+		Description: "Count of redis commands, by command and status",
+		Measure:     commandLatency,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeyCommand, KeyStatus},
+	}
+
+	// DefaultViews are the views of redis commands, to be registered with view.Register
+	DefaultViews = []*view.View{CommandLatencyView, CommandCountView}
+)
+
+// recordCommand records latency of command started at start
+func recordCommand(ctx context.Context, cmd string, start time.Time, err error) {
+	status := commandStatusOK
+	if err != nil {
+		status = commandStatusError
+	}
+
+	latency := float64(time.Since(start)) / float64(time.Millisecond)
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(KeyCommand, cmd),
+		tag.Upsert(KeyStatus, status),
+	}, commandLatency.M(latency))
+}
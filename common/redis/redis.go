@@ -1,12 +1,17 @@
 package redis
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
+	"go.opencensus.io/trace"
+
+	"grpc-starter/common/cache"
 )
 
 const (
@@ -14,16 +19,58 @@ const (
 	cutVal         = 12
 	maxIdle        = 3
 	maxIdleTimeout = 240
+
+	// DefaultCommandTimeout bounds every command of a client created with NewClient
+	DefaultCommandTimeout = time.Second
+
+	// scanBatchSize is the number of keys scanned, and then deleted, per round trip
+	scanBatchSize = 100
 )
 
+// incrByScript increments counter and sets its ttl when it has none,
+// so the ttl starts with the first increment and is not extended by later ones.
+const incrByScript = `
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return value
+`
+
+// getDelScript gets value and deletes its key in one step, like GETDEL of Redis 6.2 does
+const getDelScript = `
+local value = redis.call('GET', KEYS[1])
+if value then
+	redis.call('DEL', KEYS[1])
+end
+return value
+`
+
+// setPExpireAtScript sets value and its expiry as unix time in milliseconds in one step,
+// like SET with PXAT of Redis 6.2 does
+const setPExpireAtScript = `
+redis.call('SET', KEYS[1], ARGV[1])
+redis.call('PEXPIREAT', KEYS[1], ARGV[2])
+return 'OK'
+`
+
+// errNonPositiveTTL is returned when a key set only if absent or a counter would be stored without expiry
+var errNonPositiveTTL = errors.New("ttl must be positive")
+
+// command is a redis command sent in a pipeline
+type command struct {
+	name string
+	args []interface{}
+}
+
 // NewPool creates new redis server pool.
 func NewPool(server string, password string) *redis.Pool {
 	return &redis.Pool{
 		MaxIdle:     maxIdle,
 		IdleTimeout: maxIdleTimeout * time.Second,
 
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", server)
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			c, err := redis.DialContext(ctx, "tcp", server)
 			if err != nil {
 				log.Println("there was an error while dialing redis :", err)
 				return nil, err
@@ -61,178 +108,405 @@ func NewPool(server string, password string) *redis.Pool {
 	}
 }
 
-// Client define struct for redis client
-type Client struct {
+// NewClient create new redis client for callers without a context.
+// It adapts ContextClient, bounding every command by DefaultCommandTimeout.
+func NewClient(cachePool *redis.Pool) *cache.Adapter {
+	return cache.NewAdapter(NewContextClient(cachePool, DefaultCommandTimeout))
+}
+
+// ContextClient define struct for context aware redis client.
+//
+// Every command is traced with an OpenCensus span and its latency is recorded, see DefaultViews.
+// A command gives up once its context is done, and is bounded by the timeout
+// when the context has no earlier deadline. Commands are never unbounded, since a command
+// abandoned on cancellation keeps its connection until its deadline, so a non-positive
+// timeout falls back to DefaultCommandTimeout.
+type ContextClient struct {
 	cachePool *redis.Pool
+	timeout   time.Duration
 }
 
-// NewClient create new redis client
-func NewClient(cachePool *redis.Pool) *Client {
-	return &Client{
+// NewContextClient create new context aware redis client
+func NewContextClient(cachePool *redis.Pool, timeout time.Duration) *ContextClient {
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+
+	return &ContextClient{
 		cachePool: cachePool,
+		timeout:   timeout,
 	}
 }
 
 // Ping ping the redis server
-func (r *Client) Ping() error {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
-
-	_, err := redis.String(conn.Do("PING"))
+func (r *ContextClient) Ping(ctx context.Context) error {
+	_, err := redis.String(r.do(ctx, "PING"))
 	if err != nil {
-		return fmt.Errorf("cannot 'PING' db: %v", err)
+		return fmt.Errorf("cannot 'PING' db: %w", err)
 	}
 	return nil
 }
 
 // Get get data from redis by cache key
-func (r *Client) Get(key string) ([]byte, error) {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
-
-	var data []byte
-	data, err := redis.Bytes(conn.Do("GET", key))
+func (r *ContextClient) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := redis.Bytes(r.do(ctx, "GET", key))
 	if err != nil {
-		return data, fmt.Errorf("error getting key %s: %v", key, err)
+		return data, fmt.Errorf("error getting key %s: %w", key, err)
 	}
 	return data, err
 }
 
-// Set set data with defined cache key, value, and time-to-live (ttl) to store in redis
-func (r *Client) Set(key string, value interface{}, ttl int) error {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
+// MGet get data of many cache keys in a single round trip, data of a missing key is nil
+func (r *ContextClient) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	if len(keys) == 0 {
+		return [][]byte{}, nil
+	}
+
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = key
+	}
+
+	data, err := redis.ByteSlices(r.do(ctx, "MGET", args...))
+	if err != nil {
+		return nil, fmt.Errorf("error getting %d keys: %w", len(keys), err)
+	}
+	return data, nil
+}
 
+// GetDel get data from redis by cache key and remove it atomically, so only one caller gets it.
+// It runs as a script rather than GETDEL, so it does not need Redis 6.2.
+func (r *ContextClient) GetDel(ctx context.Context, key string) ([]byte, error) {
+	data, err := redis.Bytes(r.eval(ctx, "GETDEL", getDelScript, key))
+	if err != nil {
+		return data, fmt.Errorf("error getting and deleting key %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Set set data with defined cache key, value, and time-to-live (ttl) to store in redis.
+// Value and ttl are set atomically, the key is never stored without expiry.
+// A non-positive ttl expires the value at once, so the key is deleted instead, like SET followed by EXPIRE does.
+func (r *ContextClient) Set(ctx context.Context, key string, value interface{}, ttl int) error {
 	val, err := json.Marshal(value)
 
 	if err != nil {
 		return fmt.Errorf("error while marshalling interface for cache")
 	}
 
-	_, err = conn.Do("SET", key, val)
-	if err != nil {
-		v := string(val)
-		if len(v) > maxValLength {
-			v = v[0:cutVal] + "..."
+	if ttl <= 0 {
+		if _, err := r.do(ctx, "DEL", key); err != nil {
+			return fmt.Errorf("error setting expired key %s: %w", key, err)
 		}
-		return fmt.Errorf("error setting key %s to %s: %v", key, v, err)
+		return nil
 	}
-	_, err = conn.Do("EXPIRE", key, ttl)
 
-	if err != nil {
-		return fmt.Errorf("error set expire key %s", key)
+	if _, err := r.do(ctx, "SET", key, val, "EX", ttl); err != nil {
+		return fmt.Errorf("error setting key %s to %s: %w", key, truncateValue(val), err)
 	}
 
-	return err
+	return nil
 }
 
-// SetWithExpireAt set key value and update expire using unix timestamp
-func (r *Client) SetWithExpireAt(key string, value interface{}, ttl time.Time) error {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
+// MSet set data of many cache keys with the same time-to-live (ttl) in a single round trip.
+// Every key is set in one transaction, so either all of them are stored or none is.
+// A non-positive ttl expires the values at once, so the keys are deleted instead, see Set.
+func (r *ContextClient) MSet(ctx context.Context, values map[string]interface{}, ttl int) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if ttl <= 0 {
+		keys := make([]interface{}, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		if _, err := r.do(ctx, "DEL", keys...); err != nil {
+			return fmt.Errorf("error setting %d expired keys: %w", len(values), err)
+		}
+		return nil
+	}
+
+	commands := make([]command, 0, len(values)+2)
+	commands = append(commands, command{name: "MULTI"})
+	for key, value := range values {
+		val, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("error while marshalling interface for cache")
+		}
+		commands = append(commands, command{name: "SET", args: []interface{}{key, val, "EX", ttl}})
+	}
+	commands = append(commands, command{name: "EXEC"})
+
+	replies, err := r.pipeline(ctx, "MSET", commands)
+	if err != nil {
+		return fmt.Errorf("error setting %d keys: %w", len(values), err)
+	}
+
+	results, err := redis.Values(replies[len(replies)-1], nil)
+	if err != nil {
+		return fmt.Errorf("error setting %d keys: %w", len(values), err)
+	}
+	for _, result := range results {
+		if resultErr, ok := result.(redis.Error); ok {
+			return fmt.Errorf("error setting %d keys: %w", len(values), resultErr)
+		}
+	}
+
+	return nil
+}
 
+// SetWithExpireAt set key value and update expire using unix timestamp.
+// Value and expiry are set atomically by a script rather than SET with PXAT, so it does not need Redis 6.2.
+func (r *ContextClient) SetWithExpireAt(ctx context.Context, key string, value interface{}, ttl time.Time) error {
 	val, err := json.Marshal(value)
 
 	if err != nil {
 		return fmt.Errorf("error while marshalling interface for cache")
 	}
 
-	_, err = conn.Do("SET", key, val)
+	if _, err := r.eval(ctx, "SET", setPExpireAtScript, key, val, ttl.UnixMilli()); err != nil {
+		return fmt.Errorf("error setting key %s to %s: %w", key, truncateValue(val), err)
+	}
+
+	return nil
+}
+
+// SetNX set data with defined cache key, value, and time-to-live (ttl) unless the key exists.
+// It reports whether the value was set, checking and setting the key atomically.
+func (r *ContextClient) SetNX(ctx context.Context, key string, value interface{}, ttl int) (bool, error) {
+	if ttl <= 0 {
+		return false, fmt.Errorf("error setting key %s: %w", key, errNonPositiveTTL)
+	}
+
+	val, err := json.Marshal(value)
+
 	if err != nil {
-		v := string(val)
-		if len(v) > maxValLength {
-			v = v[0:cutVal] + "..."
-		}
-		return fmt.Errorf("error setting key %s to %s: %v", key, v, err)
+		return false, fmt.Errorf("error while marshalling interface for cache")
 	}
-	_, err = conn.Do("EXPIREAT", key, ttl.Unix())
 
+	reply, err := r.do(ctx, "SET", key, val, "EX", ttl, "NX")
 	if err != nil {
-		return fmt.Errorf("error set expireat key %s", key)
+		return false, fmt.Errorf("error setting key %s to %s: %w", key, truncateValue(val), err)
 	}
 
-	return err
+	// SET NX replies nil when the key exists
+	return reply != nil, nil
 }
 
-// Exists check if key is exist in redis
-func (r *Client) Exists(key string) (bool, error) {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
+// Incr increments counter by one and returns its new value.
+// A new counter expires after ttl seconds, which later increments do not extend.
+func (r *ContextClient) Incr(ctx context.Context, key string, ttl int) (int64, error) {
+	return r.incrBy(ctx, "INCR", key, 1, ttl)
+}
+
+// Decr decrements counter by one and returns its new value.
+// A new counter expires after ttl seconds, which later decrements do not extend.
+func (r *ContextClient) Decr(ctx context.Context, key string, ttl int) (int64, error) {
+	return r.incrBy(ctx, "DECR", key, -1, ttl)
+}
 
-	ok, err := redis.Bool(conn.Do("EXISTS", key))
+// Exists check if key is exist in redis
+func (r *ContextClient) Exists(ctx context.Context, key string) (bool, error) {
+	ok, err := redis.Bool(r.do(ctx, "EXISTS", key))
 	if err != nil {
-		return ok, fmt.Errorf("error checking if key %s exists: %v", key, err)
+		return ok, fmt.Errorf("error checking if key %s exists: %w", key, err)
 	}
 	return ok, err
 }
 
 // Remove remove cache by cache key
-func (r *Client) Remove(key string) error {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
-
-	_, err := conn.Do("DEL", key)
+func (r *ContextClient) Remove(ctx context.Context, key string) error {
+	_, err := r.do(ctx, "DEL", key)
 	return err
 }
 
-// BulkRemove remove cache by certain cache key pattern
-func (r *Client) BulkRemove(pattern string) error {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
-
-	val, getKeysErr := redis.Strings(conn.Do("KEYS", pattern))
-
-	if getKeysErr != nil {
-		return fmt.Errorf("error retrieving '%s' keys", pattern)
-	}
+// BulkRemove remove cache by certain cache key pattern.
+// Keys are found with SCAN, which unlike KEYS does not block the server,
+// and every batch of found keys is deleted in a single pipelined round trip.
+func (r *ContextClient) BulkRemove(ctx context.Context, pattern string) error {
+	return r.scan(ctx, pattern, func(keys []string) error {
+		commands := make([]command, len(keys))
+		for i, key := range keys {
+			commands[i] = command{name: "DEL", args: []interface{}{key}}
+		}
 
-	for i := range val {
-		_, err := conn.Do("DEL", val[i])
-		if err != nil {
-			return fmt.Errorf("error deleting '%s' keys", val[i])
+		if _, err := r.pipeline(ctx, "DEL", commands); err != nil {
+			return fmt.Errorf("error deleting '%s' keys: %w", pattern, err)
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // Scan scan all cache key with certain pattern
-func (r *Client) Scan(pattern string) ([]string, error) {
-	conn := r.cachePool.Get()
-	defer func() {
-		_ = conn.Close()
-	}()
+func (r *ContextClient) Scan(ctx context.Context, pattern string) ([]string, error) {
+	keys := []string{}
+	err := r.scan(ctx, pattern, func(batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	})
 
+	return keys, err
+}
+
+// scan iterates keys matching pattern, calling fn with every non empty batch of keys
+func (r *ContextClient) scan(ctx context.Context, pattern string, fn func(keys []string) error) error {
 	iter := 0
-	keys := []string{}
 	for {
-		arr, err := redis.Values(conn.Do("SCAN", iter, "MATCH", pattern))
+		arr, err := redis.Values(r.do(ctx, "SCAN", iter, "MATCH", pattern, "COUNT", scanBatchSize))
 		if err != nil {
-			return keys, fmt.Errorf("error retrieving '%s' keys", pattern)
+			return fmt.Errorf("error retrieving '%s' keys: %w", pattern, err)
 		}
 
 		iter, _ = redis.Int(arr[0], nil)
-		k, _ := redis.Strings(arr[1], nil)
-		keys = append(keys, k...)
+		keys, _ := redis.Strings(arr[1], nil)
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
 
 		if iter == 0 {
-			break
+			return nil
 		}
 	}
+}
+
+// incrBy adds delta to counter, setting ttl of a new counter in the same script
+func (r *ContextClient) incrBy(ctx context.Context, name string, key string, delta int64, ttl int) (int64, error) {
+	if ttl <= 0 {
+		return 0, fmt.Errorf("error incrementing key %s: %w", key, errNonPositiveTTL)
+	}
+
+	value, err := redis.Int64(r.eval(ctx, name, incrByScript, key, delta, ttl))
+	if err != nil {
+		return 0, fmt.Errorf("error incrementing key %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// eval runs script on key with args, traced and recorded as name, see run
+func (r *ContextClient) eval(ctx context.Context, name string, script string, key string, args ...interface{}) (interface{}, error) {
+	return r.run(ctx, name, func(conn redis.Conn, timeout time.Duration) (interface{}, error) {
+		return redis.DoWithTimeout(conn, timeout, "EVAL", append([]interface{}{script, 1, key}, args...)...)
+	})
+}
+
+// do runs command on a connection of the pool, see run
+func (r *ContextClient) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return r.run(ctx, cmd, func(conn redis.Conn, timeout time.Duration) (interface{}, error) {
+		return redis.DoWithTimeout(conn, timeout, cmd, args...)
+	})
+}
 
-	return keys, nil
+// pipeline sends commands on a connection of the pool and receives their replies in a single round trip.
+// It returns the first error replied by any of the commands, see run.
+func (r *ContextClient) pipeline(ctx context.Context, name string, commands []command) ([]interface{}, error) {
+	replies, err := redis.Values(r.run(ctx, name, func(conn redis.Conn, timeout time.Duration) (interface{}, error) {
+		for _, c := range commands {
+			if err := conn.Send(c.name, c.args...); err != nil {
+				return nil, err
+			}
+		}
+
+		// an empty command flushes the pipeline and receives every pending reply
+		return redis.DoWithTimeout(conn, timeout, "")
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reply := range replies {
+		if replyErr, ok := reply.(redis.Error); ok {
+			return replies, replyErr
+		}
+	}
+
+	return replies, nil
+}
+
+// run runs fn on a connection of the pool, traced and recorded as name, giving up once ctx is done.
+// fn is given the time left until the deadline of ctx, which bounds its commands.
+func (r *ContextClient) run(ctx context.Context, name string, fn func(conn redis.Conn, timeout time.Duration) (interface{}, error)) (reply interface{}, err error) {
+	ctx, span := trace.StartSpan(ctx, "Redis-"+name)
+	span.AddAttributes(trace.StringAttribute("redis.command", name))
+	start := time.Now()
+	defer func() {
+		recordCommand(ctx, name, start, err)
+		span.SetStatus(commandStatus(err))
+		span.End()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	conn, err := r.cachePool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return runContext(ctx, conn, fn)
+}
+
+// runContext runs fn on conn and closes it, returning early once ctx is done.
+// The commands of fn are bounded by the deadline of ctx, so commands abandoned on cancellation
+// still finish in the background, closing conn afterwards.
+func runContext(ctx context.Context, conn redis.Conn, fn func(conn redis.Conn, timeout time.Duration) (interface{}, error)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// ctx always has a deadline, see run, and a zero timeout would leave the command unbounded
+	deadline, _ := ctx.Deadline()
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		_ = conn.Close()
+		return nil, context.DeadlineExceeded
+	}
+
+	type result struct {
+		reply interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		reply, err := fn(conn, timeout)
+		done <- result{reply: reply, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.reply, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// commandStatus returns span status of a command that returned err
+func commandStatus(err error) trace.Status {
+	switch {
+	case err == nil:
+		return trace.Status{Code: trace.StatusCodeOK}
+	case errors.Is(err, context.DeadlineExceeded):
+		return trace.Status{Code: trace.StatusCodeDeadlineExceeded, Message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return trace.Status{Code: trace.StatusCodeCancelled, Message: err.Error()}
+	default:
+		return trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()}
+	}
+}
+
+// truncateValue truncates value for error messages
+func truncateValue(val []byte) string {
+	v := string(val)
+	if len(v) > maxValLength {
+		v = v[0:cutVal] + "..."
+	}
+	return v
 }
@@ -2,11 +2,17 @@ package redis_test
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	redigo "github.com/gomodule/redigo/redis"
 	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
 
+	"grpc-starter/common/cache"
 	"grpc-starter/common/config"
 	"grpc-starter/common/redis"
 )
@@ -43,3 +49,254 @@ func TestNewPool(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestContextClient(t *testing.T) {
+	t.Run("value is set and read", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		assert.Nil(t, client.Set(ctx, "key", "value", 60))
+		assert.Equal(t, 60*time.Second, server.TTL("key"))
+
+		data, err := client.Get(ctx, "key")
+		assert.Nil(t, err)
+		assert.Equal(t, `"value"`, string(data))
+
+		exists, err := client.Exists(ctx, "key")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("value without positive ttl expires at once", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		assert.Nil(t, client.Set(ctx, "key", "previous", 60))
+		assert.Nil(t, client.Set(ctx, "key", "value", 0))
+		assert.False(t, server.Exists("key"))
+
+		assert.Nil(t, client.MSet(ctx, map[string]interface{}{"one": 1, "two": 2}, 60))
+		assert.Nil(t, client.MSet(ctx, map[string]interface{}{"one": 1, "two": 2}, -1))
+		assert.False(t, server.Exists("one"))
+		assert.False(t, server.Exists("two"))
+	})
+
+	t.Run("value is read and deleted at once", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		assert.Nil(t, client.Set(ctx, "key", "value", 60))
+
+		data, err := client.GetDel(ctx, "key")
+		assert.Nil(t, err)
+		assert.Equal(t, `"value"`, string(data))
+		assert.False(t, server.Exists("key"))
+
+		_, err = client.GetDel(ctx, "key")
+		assert.ErrorIs(t, err, redigo.ErrNil)
+	})
+
+	t.Run("value is set with an absolute expiry", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		assert.Nil(t, client.SetWithExpireAt(ctx, "key", "value", time.Now().Add(time.Minute)))
+		assert.InDelta(t, time.Minute.Seconds(), server.TTL("key").Seconds(), 1)
+
+		data, err := client.Get(ctx, "key")
+		assert.Nil(t, err)
+		assert.Equal(t, `"value"`, string(data))
+	})
+
+	t.Run("value is only set when key does not exist", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		ok, err := client.SetNX(ctx, "key", "first", 60)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 60*time.Second, server.TTL("key"))
+
+		ok, err = client.SetNX(ctx, "key", "second", 60)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		data, err := client.Get(ctx, "key")
+		assert.Nil(t, err)
+		assert.Equal(t, `"first"`, string(data))
+	})
+
+	t.Run("many values are set and read", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		assert.Nil(t, client.MSet(ctx, map[string]interface{}{"one": 1, "two": 2}, 60))
+		assert.Equal(t, 60*time.Second, server.TTL("one"))
+		assert.Equal(t, 60*time.Second, server.TTL("two"))
+
+		data, err := client.MGet(ctx, "one", "missing", "two")
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{[]byte("1"), nil, []byte("2")}, data)
+	})
+
+	t.Run("counter expires after ttl of its first increment", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		value, err := client.Incr(ctx, "counter", 60)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), value)
+
+		server.FastForward(30 * time.Second)
+
+		value, err = client.Incr(ctx, "counter", 60)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), value)
+		assert.Equal(t, 30*time.Second, server.TTL("counter"))
+
+		value, err = client.Decr(ctx, "counter", 60)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), value)
+		assert.Equal(t, 30*time.Second, server.TTL("counter"))
+	})
+
+	t.Run("keys matching pattern are removed in batches", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		for i := 0; i < 250; i++ {
+			assert.Nil(t, server.Set(fmt.Sprintf("prefix:%d", i), "value"))
+		}
+		assert.Nil(t, server.Set("other", "value"))
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx := context.Background()
+
+		keys, err := client.Scan(ctx, "prefix:*")
+		assert.Nil(t, err)
+		assert.Len(t, keys, 250)
+
+		assert.Nil(t, client.BulkRemove(ctx, "prefix:*"))
+		assert.Equal(t, []string{"other"}, server.Keys())
+	})
+
+	t.Run("cancelled context is honored", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.Ping(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("unresponsive server is bounded by timeout", func(t *testing.T) {
+		pool := newUnresponsivePool(t)
+		client := redis.NewContextClient(pool, 50*time.Millisecond)
+
+		start := time.Now()
+		err := client.Ping(context.Background())
+		assert.NotNil(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("abandoned command is bounded without timeout", func(t *testing.T) {
+		pool := newUnresponsivePool(t)
+		client := redis.NewContextClient(pool, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		err := client.Ping(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// the abandoned command releases its connection by the default timeout
+		assert.Eventually(t, func() bool {
+			return pool.ActiveCount() == 0
+		}, 2*redis.DefaultCommandTimeout, 10*time.Millisecond)
+	})
+
+	t.Run("commands are recorded", func(t *testing.T) {
+		server, _ := miniredis.Run()
+		defer server.Close()
+
+		assert.Nil(t, view.Register(redis.CommandCountView))
+		defer view.Unregister(redis.CommandCountView)
+
+		client := redis.NewContextClient(redis.NewPool(server.Addr(), ""), time.Second)
+		assert.Nil(t, client.Remove(context.Background(), "key"))
+
+		rows, err := view.RetrieveData(redis.CommandCountView.Name)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, rows)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	server, _ := miniredis.Run()
+	defer server.Close()
+
+	var client cache.Cacheable = redis.NewClient(redis.NewPool(server.Addr(), ""))
+
+	assert.Nil(t, client.Set("key", 1, 60))
+	data, err := client.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(data))
+}
+
+// newUnresponsivePool creates a pool of a server that accepts connections but never replies
+func newUnresponsivePool(t *testing.T) *redigo.Pool {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+		}()
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	pool := redis.NewPool(listener.Addr().String(), "")
+	pool.TestOnBorrow = nil
+
+	return pool
+}
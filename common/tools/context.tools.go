@@ -1,6 +1,13 @@
 package tools
 
-import "context"
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
 
 type contextKey string
 
@@ -8,6 +15,17 @@ func (c contextKey) String() string {
 	return string(c)
 }
 
+const (
+	// forwardedForMetadataKey is the metadata set by the REST gateway with the address of the HTTP client
+	forwardedForMetadataKey = "x-forwarded-for"
+	// gatewayUserAgentMetadataKey is the metadata set by the REST gateway with the user agent of the HTTP client
+	gatewayUserAgentMetadataKey = "grpcgateway-user-agent"
+	// userAgentMetadataKey is the metadata set by gRPC clients with their user agent
+	userAgentMetadataKey = "user-agent"
+	// deviceNameMetadataKey is the metadata carrying the device name chosen by the client
+	deviceNameMetadataKey = "x-device-name"
+)
+
 var (
 	// ContextKeySubjectID var
 	ContextKeySubjectID = contextKey("subjectID")
@@ -15,7 +33,8 @@ var (
 	ContextKeyJobID contextKey
 )
 
-// GetSubjectFromContext gets the caller value from the context.
+// GetSubjectFromContext gets the user id of the caller from the context.
+// Prefer jwt.PrincipalFromContext, which also carries roles and token details of the caller.
 func GetSubjectFromContext(ctx context.Context) (string, bool) {
 	caller, ok := ctx.Value(ContextKeySubjectID).(string)
 	return caller, ok
@@ -26,3 +45,84 @@ func GetJobIDFromContext(ctx context.Context) (string, bool) {
 	jobID, ok := ctx.Value(ContextKeyJobID).(string)
 	return jobID, ok
 }
+
+// GetClientIPFromContext gets the ip address of the caller from the context.
+// It is the address of the gRPC peer, unless the peer is one of the trusted proxies, such as the REST gateway,
+// which appends the address of its HTTP client to x-forwarded-for. Only the last entry is used then,
+// the previous entries are sent by the client itself and can not be trusted.
+// x-forwarded-for sent by any other peer is ignored, since every gRPC client can send it.
+func GetClientIPFromContext(ctx context.Context, trustedProxies []*net.IPNet) string {
+	peerIP := getPeerIPFromContext(ctx)
+	if !isTrustedProxy(peerIP, trustedProxies) {
+		return peerIP
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(forwardedForMetadataKey); len(values) > 0 {
+			entries := strings.Split(values[len(values)-1], ",")
+			if forwarded := strings.TrimSpace(entries[len(entries)-1]); forwarded != "" {
+				return forwarded
+			}
+		}
+	}
+
+	return peerIP
+}
+
+// getPeerIPFromContext gets the ip address of the gRPC peer from the context
+func getPeerIPFromContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// isTrustedProxy checks whether ip address is within one of the trusted proxies
+func isTrustedProxy(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetUserAgentFromContext gets the user agent of the caller from the context.
+// The user agent of the HTTP client is preferred over the one of the REST gateway itself.
+func GetUserAgentFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, key := range []string{gatewayUserAgentMetadataKey, userAgentMetadataKey} {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+// GetDeviceNameFromContext gets the device name sent by the caller in x-device-name from the context.
+func GetDeviceNameFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(deviceNameMetadataKey); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,47 @@
+package tools_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"grpc-starter/common/tools"
+)
+
+func TestGetClientIPFromContext(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("127.0.0.1/32")
+	trustedProxies := []*net.IPNet{loopback}
+
+	newContext := func(peerAddr string, forwardedFor ...string) context.Context {
+		addr, _ := net.ResolveTCPAddr("tcp", peerAddr)
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+		if len(forwardedFor) > 0 {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", forwardedFor[0]))
+		}
+		return ctx
+	}
+
+	t.Run("last forwarded address is used behind trusted proxy", func(t *testing.T) {
+		ctx := newContext("127.0.0.1:50000", "10.0.0.1, 203.0.113.7")
+		assert.Equal(t, "203.0.113.7", tools.GetClientIPFromContext(ctx, trustedProxies))
+	})
+
+	t.Run("forwarded address sent by untrusted peer is ignored", func(t *testing.T) {
+		ctx := newContext("198.51.100.2:50000", "203.0.113.7")
+		assert.Equal(t, "198.51.100.2", tools.GetClientIPFromContext(ctx, trustedProxies))
+	})
+
+	t.Run("peer address is used without forwarded address", func(t *testing.T) {
+		ctx := newContext("127.0.0.1:50000")
+		assert.Equal(t, "127.0.0.1", tools.GetClientIPFromContext(ctx, trustedProxies))
+	})
+
+	t.Run("forwarded address is ignored without trusted proxies", func(t *testing.T) {
+		ctx := newContext("127.0.0.1:50000", "203.0.113.7")
+		assert.Equal(t, "127.0.0.1", tools.GetClientIPFromContext(ctx, nil))
+	})
+}
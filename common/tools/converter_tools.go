@@ -15,6 +15,14 @@ func StringToNullString(d string) sql.NullString {
 	}
 }
 
+// EmptyStringToNullString convert string to sql null string, empty string becomes NULL
+func EmptyStringToNullString(d string) sql.NullString {
+	return sql.NullString{
+		String: d,
+		Valid:  d != "",
+	}
+}
+
 // BoolToNullBool convert bool to sql null bool
 func BoolToNullBool(d bool) sql.NullBool {
 	return sql.NullBool{
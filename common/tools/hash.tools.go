@@ -0,0 +1,13 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SHA256Hex returns hex encoded sha256 digest of a string.
+// It is used to store opaque tokens without keeping their plain value.
+func SHA256Hex(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
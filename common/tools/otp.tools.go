@@ -27,3 +27,20 @@ func GenerateOTP() (string, string, error) {
 
 	return otpString, encrypted, nil
 }
+
+// GenerateRecoveryCode creates a recovery code formatted as two groups of five hex characters and its bcrypt hash
+func GenerateRecoveryCode() (string, string, error) {
+	token, err := GenerateRandomToken(constant.Five)
+	if err != nil {
+		return "", "", err
+	}
+
+	code := token[:constant.Five] + "-" + token[constant.Five:]
+
+	encrypted, err := BcryptEncrypt(code)
+	if err != nil {
+		return "", "", err
+	}
+
+	return code, encrypted, nil
+}
@@ -0,0 +1,16 @@
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateRandomToken creates hex encoded cryptographically secure random token from n random bytes
+func GenerateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
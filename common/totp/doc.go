@@ -0,0 +1,2 @@
+// Package totp implements time-based one-time passwords as described in RFC 6238.
+package totp
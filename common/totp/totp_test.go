@@ -0,0 +1,92 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/totp"
+)
+
+// rfcSecret is the SHA1 secret of RFC 6238 appendix B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	t.Run("matches RFC 6238 test vectors", func(t *testing.T) {
+		vectors := map[int64]string{
+			59:          "287082",
+			1111111109:  "081804",
+			1111111111:  "050471",
+			1234567890:  "005924",
+			2000000000:  "279037",
+			20000000000: "353130",
+		}
+
+		for unix, expected := range vectors {
+			code, err := totp.Code(rfcSecret, totp.Step(time.Unix(unix, 0)))
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, code)
+		}
+	})
+
+	t.Run("fails on invalid secret", func(t *testing.T) {
+		_, err := totp.Code("not base32!", 1)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	t.Run("accepts code of the current step", func(t *testing.T) {
+		step, ok := totp.Validate(rfcSecret, "050471", now, 1)
+
+		assert.True(t, ok)
+		assert.Equal(t, totp.Step(now), step)
+	})
+
+	t.Run("accepts code of the previous step within skew", func(t *testing.T) {
+		code, _ := totp.Code(rfcSecret, totp.Step(now)-1)
+		step, ok := totp.Validate(rfcSecret, code, now, 1)
+
+		assert.True(t, ok)
+		assert.Equal(t, totp.Step(now)-1, step)
+	})
+
+	t.Run("rejects code outside skew", func(t *testing.T) {
+		code, _ := totp.Code(rfcSecret, totp.Step(now)-2)
+		_, ok := totp.Validate(rfcSecret, code, now, 1)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects code with wrong length", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, "50471", now, 1)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+
+	assert.Nil(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = totp.Code(secret, 1)
+	assert.Nil(t, err)
+}
+
+func TestURI(t *testing.T) {
+	uri := totp.URI("Starter", "user@starter.com", "SECRET")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Starter:user@starter.com?"))
+	assert.Contains(t, uri, "secret=SECRET")
+	assert.Contains(t, uri, "issuer=Starter")
+	assert.Contains(t, uri, "digits=6")
+}
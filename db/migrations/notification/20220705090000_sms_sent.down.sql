@@ -0,0 +1,3 @@
+BEGIN;
+DROP TABLE IF EXISTS notification.sms_sent;
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS notification.sms_sent
+(
+    created_by   VARCHAR(200) NOT NULL,
+    updated_by   VARCHAR(200) NOT NULL,
+    deleted_by   VARCHAR(200),
+    created_at   TIMESTAMPTZ  NOT NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL,
+    deleted_at   TIMESTAMPTZ,
+    id           BIGSERIAL PRIMARY KEY,
+    "m_id"       VARCHAR(200) NULL,
+    client_m_id  uuid         NOT NULL,
+    "to"         VARCHAR(200) NOT NULL,
+    content      TEXT         NOT NULL,
+    "status"     VARCHAR(100) NOT NULL,
+    status_notes TEXT         NULL,
+    "category"   VARCHAR(255) NULL
+);
+COMMIT;
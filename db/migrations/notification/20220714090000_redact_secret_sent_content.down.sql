@@ -0,0 +1,9 @@
+BEGIN;
+
+-- reset links and one-time passwords are not kept anywhere once redacted
+DO $$
+BEGIN
+    RAISE NOTICE 'redacted content of sent emails and sms can not be restored, it stays redacted';
+END $$;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- content of these categories carries password reset links and one-time passwords,
+-- new messages of them are saved as entity.RedactedContent
+UPDATE notification.email_sent
+SET content = '[REDACTED]'
+WHERE category IN ('PASSWORD_RESET', 'EMAIL_VERIFICATION');
+
+UPDATE notification.sms_sent
+SET content = '[REDACTED]'
+WHERE category = 'PHONE_OTP';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS users.refresh_tokens;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users.refresh_tokens
+(
+    created_by  VARCHAR(200),
+    updated_by  VARCHAR(200),
+    deleted_by  VARCHAR(200),
+    created_at  TIMESTAMP,
+    updated_at  TIMESTAMP,
+    deleted_at  TIMESTAMP,
+    id          uuid PRIMARY KEY,
+    user_id     uuid               NOT NULL REFERENCES users.users (id),
+    family_id   uuid               NOT NULL,
+    token_hash  VARCHAR(64) UNIQUE NOT NULL,
+    expires_at  TIMESTAMP          NOT NULL,
+    used_at     TIMESTAMP          NULL,
+    replaced_by uuid               NULL,
+    revoked_at  TIMESTAMP          NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON users.refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON users.refresh_tokens (family_id);
+
+COMMIT;
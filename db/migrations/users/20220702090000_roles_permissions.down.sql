@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS users.user_roles;
+DROP TABLE IF EXISTS users.role_permissions;
+DROP TABLE IF EXISTS users.permissions;
+DROP TABLE IF EXISTS users.roles;
+
+COMMIT;
@@ -0,0 +1,66 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users.roles
+(
+    created_by  VARCHAR(200),
+    updated_by  VARCHAR(200),
+    deleted_by  VARCHAR(200),
+    created_at  TIMESTAMP,
+    updated_at  TIMESTAMP,
+    deleted_at  TIMESTAMP,
+    id          uuid PRIMARY KEY,
+    name        VARCHAR(100) UNIQUE NOT NULL,
+    description VARCHAR(255)        NULL
+);
+
+CREATE TABLE IF NOT EXISTS users.permissions
+(
+    created_by  VARCHAR(200),
+    updated_by  VARCHAR(200),
+    deleted_by  VARCHAR(200),
+    created_at  TIMESTAMP,
+    updated_at  TIMESTAMP,
+    deleted_at  TIMESTAMP,
+    id          uuid PRIMARY KEY,
+    name        VARCHAR(100) UNIQUE NOT NULL,
+    description VARCHAR(255)        NULL
+);
+
+CREATE TABLE IF NOT EXISTS users.role_permissions
+(
+    created_by    VARCHAR(200),
+    created_at    TIMESTAMP,
+    role_id       uuid NOT NULL REFERENCES users.roles (id) ON DELETE CASCADE,
+    permission_id uuid NOT NULL REFERENCES users.permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS users.user_roles
+(
+    created_by VARCHAR(200),
+    created_at TIMESTAMP,
+    user_id    uuid NOT NULL REFERENCES users.users (id) ON DELETE CASCADE,
+    role_id    uuid NOT NULL REFERENCES users.roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_roles_role_id_idx ON users.user_roles (role_id);
+
+INSERT INTO "users"."roles" ("created_by", "updated_by", "created_at", "updated_at", "id", "name", "description") VALUES
+    ('system', 'system', now(), now(), 'b1d3c1b6-4f0e-4d6b-9a57-3e0c2f6d9a01', 'admin', 'Administrator'),
+    ('system', 'system', now(), now(), 'b1d3c1b6-4f0e-4d6b-9a57-3e0c2f6d9a02', 'user', 'Regular user');
+
+INSERT INTO "users"."permissions" ("created_by", "updated_by", "created_at", "updated_at", "id", "name", "description") VALUES
+    ('system', 'system', now(), now(), 'c2e4d2c7-5a1f-4e7c-8b68-4f1d3a7e0b01', 'users.read', 'Read any user'),
+    ('system', 'system', now(), now(), 'c2e4d2c7-5a1f-4e7c-8b68-4f1d3a7e0b02', 'users.write', 'Manage any user'),
+    ('system', 'system', now(), now(), 'c2e4d2c7-5a1f-4e7c-8b68-4f1d3a7e0b03', 'roles.assign', 'Assign and revoke roles of users');
+
+INSERT INTO "users"."role_permissions" ("created_by", "created_at", "role_id", "permission_id") VALUES
+    ('system', now(), 'b1d3c1b6-4f0e-4d6b-9a57-3e0c2f6d9a01', 'c2e4d2c7-5a1f-4e7c-8b68-4f1d3a7e0b01'),
+    ('system', now(), 'b1d3c1b6-4f0e-4d6b-9a57-3e0c2f6d9a01', 'c2e4d2c7-5a1f-4e7c-8b68-4f1d3a7e0b02'),
+    ('system', now(), 'b1d3c1b6-4f0e-4d6b-9a57-3e0c2f6d9a01', 'c2e4d2c7-5a1f-4e7c-8b68-4f1d3a7e0b03');
+
+INSERT INTO "users"."user_roles" ("created_by", "created_at", "user_id", "role_id") VALUES
+    ('system', now(), '0abc6437-bb96-4dc7-a8a1-04f4e3038741', 'b1d3c1b6-4f0e-4d6b-9a57-3e0c2f6d9a01');
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS users.users_created_at_id_idx;
+DROP INDEX IF EXISTS users.users_search_vector_idx;
+
+ALTER TABLE users.users DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE users.users DROP COLUMN IF EXISTS disabled_at;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP NULL;
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(username, '') || ' ' || coalesce(email, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS users_search_vector_idx ON users.users USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS users_created_at_id_idx ON users.users (created_at, id);
+
+COMMIT;
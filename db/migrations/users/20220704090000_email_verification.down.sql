@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE users.users DROP COLUMN IF EXISTS email_verified_at;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP NULL;
+
+-- accounts created before email verification existed are treated as verified
+UPDATE users.users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+COMMIT;
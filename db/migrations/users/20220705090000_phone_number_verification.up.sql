@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS phone_number_verified_at TIMESTAMP NULL;
+
+COMMIT;
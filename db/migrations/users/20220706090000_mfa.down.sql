@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS users.user_recovery_codes;
+DROP TABLE IF EXISTS users.user_mfa;
+
+COMMIT;
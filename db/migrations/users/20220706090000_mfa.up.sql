@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users.user_mfa
+(
+    created_by   VARCHAR(200),
+    updated_by   VARCHAR(200),
+    deleted_by   VARCHAR(200),
+    created_at   TIMESTAMP,
+    updated_at   TIMESTAMP,
+    deleted_at   TIMESTAMP,
+    user_id      uuid PRIMARY KEY REFERENCES users.users (id) ON DELETE CASCADE,
+    secret       VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP   NULL
+);
+
+CREATE TABLE IF NOT EXISTS users.user_recovery_codes
+(
+    created_by VARCHAR(200),
+    created_at TIMESTAMP,
+    id         uuid PRIMARY KEY,
+    user_id    uuid         NOT NULL REFERENCES users.users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(255) NOT NULL,
+    used_at    TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_recovery_codes_user_id_idx ON users.user_recovery_codes (user_id);
+
+COMMIT;
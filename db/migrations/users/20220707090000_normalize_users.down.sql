@@ -0,0 +1,9 @@
+BEGIN;
+
+-- the case and spacing emails were entered with is not kept, and empty phone numbers are indistinguishable from missing ones
+DO $$
+BEGIN
+    RAISE NOTICE 'normalised emails and phone numbers cleared from empty strings can not be restored, they stay normalised';
+END $$;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- users sharing an email once normalised would violate users_email_key,
+-- they must be resolved by hand since there is no telling which of them owns the email
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT STRING_AGG(ids, '; ') INTO duplicates
+    FROM (
+        SELECT STRING_AGG(id::TEXT, ', ') AS ids
+        FROM users.users
+        GROUP BY LOWER(TRIM(email))
+        HAVING COUNT(*) > 1
+    ) AS duplicated;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'users share the same email once normalised, resolve them before migrating again: %', duplicates;
+    END IF;
+END $$;
+
+UPDATE users.users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+UPDATE users.users SET phone_number = NULL WHERE phone_number = '';
+
+COMMIT;
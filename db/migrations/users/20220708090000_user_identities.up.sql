@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users.user_identities
+(
+    created_by VARCHAR(200),
+    updated_by VARCHAR(200),
+    deleted_by VARCHAR(200),
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    id         uuid PRIMARY KEY,
+    user_id    uuid         NOT NULL REFERENCES users.users (id) ON DELETE CASCADE,
+    provider   VARCHAR(50)  NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) NULL,
+    CONSTRAINT user_identities_provider_subject_key UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON users.user_identities (user_id);
+
+COMMIT;
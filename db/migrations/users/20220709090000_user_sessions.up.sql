@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users.user_sessions
+(
+    created_by   VARCHAR(200),
+    updated_by   VARCHAR(200),
+    deleted_by   VARCHAR(200),
+    created_at   TIMESTAMP,
+    updated_at   TIMESTAMP,
+    deleted_at   TIMESTAMP,
+    id           uuid PRIMARY KEY,
+    user_id      uuid         NOT NULL REFERENCES users.users (id) ON DELETE CASCADE,
+    device_name  VARCHAR(100) NULL,
+    user_agent   VARCHAR(500) NULL,
+    ip_address   VARCHAR(45)  NULL,
+    last_seen_at TIMESTAMP    NOT NULL,
+    expires_at   TIMESTAMP    NOT NULL,
+    revoked_at   TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_sessions_user_id_idx ON users.user_sessions (user_id);
+
+COMMIT;
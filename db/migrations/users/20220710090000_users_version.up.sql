@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users.users
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS users.users_deleted_at_idx;
+
+ALTER TABLE users.users
+    DROP COLUMN IF EXISTS erased_at;
+
+COMMIT;
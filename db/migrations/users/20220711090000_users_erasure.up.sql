@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE users.users
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users.users (deleted_at) WHERE deleted_at IS NOT NULL AND erased_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- the format phone numbers were entered in is not kept, so they stay normalised
+DO $$
+BEGIN
+    RAISE NOTICE 'phone numbers normalised to E.164 can not be restored to the format they were entered in';
+END $$;
+
+COMMIT;
@@ -0,0 +1,45 @@
+BEGIN;
+
+-- phone numbers are stored in E.164, the same way entity.NormalizePhoneNumber normalises them
+CREATE TEMPORARY TABLE normalized_phone_numbers ON COMMIT DROP AS
+SELECT id, phone_number,
+       CASE
+           WHEN stripped ~ '^\+[0-9]+$' THEN stripped
+           WHEN stripped ~ '^00[0-9]+$' THEN '+' || SUBSTRING(stripped FROM 3)
+           WHEN stripped LIKE '00%' THEN TRIM(phone_number)
+           WHEN stripped ~ '^0[0-9]*$' THEN '+62' || SUBSTRING(stripped FROM 2)
+           WHEN stripped ~ '^[0-9]+$' THEN '+' || stripped
+           ELSE TRIM(phone_number)
+       END AS normalized
+FROM (
+    SELECT id, phone_number, REGEXP_REPLACE(TRIM(phone_number), '[ .()-]', '', 'g') AS stripped
+    FROM users.users
+    WHERE phone_number IS NOT NULL
+) AS phone_numbers;
+
+-- users sharing a phone number once normalised would violate users_phone_number_key,
+-- they must be resolved by hand since there is no telling which of them owns the number
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT STRING_AGG(ids, '; ') INTO duplicates
+    FROM (
+        SELECT STRING_AGG(id::TEXT, ', ') AS ids
+        FROM normalized_phone_numbers
+        GROUP BY normalized
+        HAVING COUNT(*) > 1
+    ) AS duplicated;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'users share the same phone number once normalised, resolve them before migrating again: %', duplicates;
+    END IF;
+END $$;
+
+UPDATE users.users
+SET phone_number = normalized_phone_numbers.normalized
+FROM normalized_phone_numbers
+WHERE users.id = normalized_phone_numbers.id
+  AND users.phone_number <> normalized_phone_numbers.normalized;
+
+COMMIT;
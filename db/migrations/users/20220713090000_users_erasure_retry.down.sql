@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users.users
+    DROP COLUMN IF EXISTS erase_failed_at;
+
+COMMIT;
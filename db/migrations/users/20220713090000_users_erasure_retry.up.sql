@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users.users
+    ADD COLUMN IF NOT EXISTS erase_failed_at TIMESTAMP NULL;
+
+COMMIT;
@@ -0,0 +1,44 @@
+Feature: Password Reset
+      In order to regain access to my account, I need to reset
+      my password using a link sent to my email
+
+  Background:
+  This section runs before every Scenario. Its main purpose is to generate random user data
+  and save it under provided key in scenario cache.
+
+    Given I save "http://localhost:8081" as "APP_URL"
+    Given I save "unregistered@starter.com" as "UNREGISTERED_EMAIL"
+    Given I save "invalid-reset-token" as "INVALID_RESET_TOKEN"
+    Given I save "testing1234" as "NEW_PASSWORD"
+
+  Scenario: Forgot password with unregistered email
+  As application user
+  I would like to receive the same response whether my email is registered or not
+
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/auth/forgot-password" and save it as "FORGOT_PASSWORD_REQUEST"
+    Given I set following body for prepared request "FORGOT_PASSWORD_REQUEST":
+    """
+    {
+        "email": "{{.UNREGISTERED_EMAIL}}"
+    }
+    """
+    When I send request "FORGOT_PASSWORD_REQUEST"
+    Then the response status code should be 200
+    And the response body should have format "JSON"
+    And the "JSON" node "message" should be "string" of value "success"
+
+  Scenario: Change password with invalid token
+  As application user
+  I should not be able to change my password using an invalid reset token
+
+    Given I prepare new "PUT" request to "{{.APP_URL}}/v1/auth/change-password/{{.INVALID_RESET_TOKEN}}" and save it as "CHANGE_PASSWORD_REQUEST"
+    Given I set following body for prepared request "CHANGE_PASSWORD_REQUEST":
+    """
+    {
+        "password": "{{.NEW_PASSWORD}}"
+    }
+    """
+    When I send request "CHANGE_PASSWORD_REQUEST"
+    Then the response status code should not be 200
+    But the response status code should be 400
+    And the response body should have format "JSON"
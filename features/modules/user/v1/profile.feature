@@ -0,0 +1,152 @@
+Feature: Profile
+      In order to manage my account, I need to see and update my profile
+
+  Background:
+  This section runs before every Scenario. Its main purpose is to login
+  and save the access token under provided key in scenario cache.
+
+    Given I save "http://localhost:8081" as "APP_URL"
+    Given I save "rifqiakram57@gmail.com" as "USER_EMAIL"
+    Given I save "testing1234" as "USER_PASSWORD"
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/auth/login" and save it as "LOGIN_REQUEST"
+    Given I set following body for prepared request "LOGIN_REQUEST":
+    """
+    {
+        "email": "{{.USER_EMAIL}}",
+        "password": "{{.USER_PASSWORD}}"
+    }
+    """
+    When I send request "LOGIN_REQUEST"
+    Then the response status code should be 200
+    And I save from the last response "JSON" node "data.token" as "AUTH_TOKEN"
+
+  Scenario: Get my profile
+  As application user
+  I would like to see my profile
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/users/me" and save it as "GET_ME_REQUEST"
+    Given I set following headers for prepared request "GET_ME_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "GET_ME_REQUEST"
+    Then the response status code should be 200
+    And the response body should have format "JSON"
+    And the "JSON" node "data.email" should be "string" of value "{{.USER_EMAIL}}"
+
+  Scenario: Export my data
+  As application user
+  I would like to download the personal data kept about me
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/users/me/export" and save it as "EXPORT_MY_DATA_REQUEST"
+    Given I set following headers for prepared request "EXPORT_MY_DATA_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "EXPORT_MY_DATA_REQUEST"
+    Then the response status code should be 200
+    And the "JSON" node "data.user.email" should be "string" of value "{{.USER_EMAIL}}"
+    And the "JSON" response should not have node "data.user.password"
+    And the "JSON" node "data.sessions" should be "slice"
+
+  Scenario: Update only my phone number
+  As application user
+  I would like to update a single field of my profile without touching the others
+
+    Given I prepare new "PATCH" request to "{{.APP_URL}}/v1/users/me?update_mask=phone_number" and save it as "UPDATE_PROFILE_REQUEST"
+    Given I set following headers for prepared request "UPDATE_PROFILE_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    Given I set following body for prepared request "UPDATE_PROFILE_REQUEST":
+    """
+    {
+        "phone_number": "0895346419497"
+    }
+    """
+    When I send request "UPDATE_PROFILE_REQUEST"
+    Then the response status code should be 200
+    And the "JSON" node "data.phone_number" should be "string" of value "+62895346419497"
+    And the "JSON" node "data.username" should be "string" of value "rifqiakrm"
+
+  Scenario: Update a field that is not part of profile
+  As application user
+  I would like to be told when I try to update a field that is not part of my profile
+
+    Given I prepare new "PATCH" request to "{{.APP_URL}}/v1/users/me?update_mask=email" and save it as "UPDATE_PROFILE_REQUEST"
+    Given I set following headers for prepared request "UPDATE_PROFILE_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    Given I set following body for prepared request "UPDATE_PROFILE_REQUEST":
+    """
+    {
+        "username": "someone"
+    }
+    """
+    When I send request "UPDATE_PROFILE_REQUEST"
+    Then the response status code should be 400
+
+  Scenario: Update my profile based on an outdated version
+  As application user
+  I would like to be told when my profile was changed by another request since I have read it
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/users/me" and save it as "GET_ME_REQUEST"
+    Given I set following headers for prepared request "GET_ME_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "GET_ME_REQUEST"
+    Then the response status code should be 200
+    And I save from the last response "JSON" node "data.version" as "USER_VERSION"
+
+    Given I prepare new "PATCH" request to "{{.APP_URL}}/v1/users/me?update_mask=phone_number&version={{.USER_VERSION}}" and save it as "UPDATE_PROFILE_REQUEST"
+    Given I set following headers for prepared request "UPDATE_PROFILE_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    Given I set following body for prepared request "UPDATE_PROFILE_REQUEST":
+    """
+    {
+        "phone_number": "0895346419497"
+    }
+    """
+    When I send request "UPDATE_PROFILE_REQUEST"
+    Then the response status code should be 200
+
+    #---------------------------------------------------------------------------------------------------
+    # The same request is now based on a version that is no longer the current one.
+    When I send request "UPDATE_PROFILE_REQUEST"
+    Then the response status code should be 409
+
+  Scenario: Verify an email that is already verified
+  As application user with verified email
+  I would like to be told that my email does not need to be verified again
+
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/users/me/verify-email" and save it as "VERIFY_EMAIL_REQUEST"
+    Given I set following headers for prepared request "VERIFY_EMAIL_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    Given I set following body for prepared request "VERIFY_EMAIL_REQUEST":
+    """
+    {
+        "code": "123456"
+    }
+    """
+    When I send request "VERIFY_EMAIL_REQUEST"
+    Then the response status code should be 400
@@ -0,0 +1,82 @@
+Feature: Role
+      In order to manage access of users, as an administrator
+      I need to assign and revoke roles of users
+
+  Background:
+  This section runs before every Scenario. Its main purpose is to login as administrator
+  and save the access token under provided key in scenario cache.
+
+    Given I save "http://localhost:8081" as "APP_URL"
+    Given I save "rifqiakram57@gmail.com" as "ADMIN_EMAIL"
+    Given I save "testing1234" as "ADMIN_PASSWORD"
+    Given I save "0abc6437-bb96-4dc7-a8a1-04f4e3038741" as "ADMIN_ID"
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/auth/login" and save it as "LOGIN_REQUEST"
+    Given I set following body for prepared request "LOGIN_REQUEST":
+    """
+    {
+        "email": "{{.ADMIN_EMAIL}}",
+        "password": "{{.ADMIN_PASSWORD}}"
+    }
+    """
+    When I send request "LOGIN_REQUEST"
+    Then the response status code should be 200
+    And I save from the last response "JSON" node "data.token" as "AUTH_TOKEN"
+
+  Scenario: Assign and revoke role
+  As an administrator
+  I would like to assign a role to a user and revoke it afterwards
+
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/admin/users/{{.ADMIN_ID}}/roles" and save it as "ASSIGN_ROLE_REQUEST"
+    Given I set following headers for prepared request "ASSIGN_ROLE_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    Given I set following body for prepared request "ASSIGN_ROLE_REQUEST":
+    """
+    {
+        "role": "user"
+    }
+    """
+    When I send request "ASSIGN_ROLE_REQUEST"
+    Then the response status code should be 200
+    And the response body should have format "JSON"
+    And the "JSON" node "data.user_id" should be "string" of value "{{.ADMIN_ID}}"
+
+    #---------------------------------------------------------------------------------------------------
+    # Changing roles invalidates access tokens of the user, so the administrator must login again.
+    When I send request "LOGIN_REQUEST"
+    Then the response status code should be 200
+    And I save from the last response "JSON" node "data.token" as "AUTH_TOKEN"
+
+    Given I prepare new "DELETE" request to "{{.APP_URL}}/v1/admin/users/{{.ADMIN_ID}}/roles/user" and save it as "REVOKE_ROLE_REQUEST"
+    Given I set following headers for prepared request "REVOKE_ROLE_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "REVOKE_ROLE_REQUEST"
+    Then the response status code should be 200
+    And the response body should have format "JSON"
+
+  Scenario: Assign unknown role
+  As an administrator
+  I would like to be told when the role does not exist
+
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/admin/users/{{.ADMIN_ID}}/roles" and save it as "ASSIGN_ROLE_REQUEST"
+    Given I set following headers for prepared request "ASSIGN_ROLE_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    Given I set following body for prepared request "ASSIGN_ROLE_REQUEST":
+    """
+    {
+        "role": "unknown"
+    }
+    """
+    When I send request "ASSIGN_ROLE_REQUEST"
+    Then the response status code should be 404
@@ -0,0 +1,73 @@
+Feature: Session
+      In order to keep my account safe, I need to see the devices I am logged in from
+      and logout from the ones I do not use anymore
+
+  Background:
+  This section runs before every Scenario. Its main purpose is to login from a named device
+  and save the access token under provided key in scenario cache.
+
+    Given I save "http://localhost:8081" as "APP_URL"
+    Given I save "rifqiakram57@gmail.com" as "USER_EMAIL"
+    Given I save "testing1234" as "USER_PASSWORD"
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/auth/login" and save it as "LOGIN_REQUEST"
+    Given I set following headers for prepared request "LOGIN_REQUEST":
+    """
+    {
+        "X-Device-Name": "godog"
+    }
+    """
+    Given I set following body for prepared request "LOGIN_REQUEST":
+    """
+    {
+        "email": "{{.USER_EMAIL}}",
+        "password": "{{.USER_PASSWORD}}"
+    }
+    """
+    When I send request "LOGIN_REQUEST"
+    Then the response status code should be 200
+    And I save from the last response "JSON" node "data.token" as "AUTH_TOKEN"
+
+  Scenario: List and revoke my current session
+  As application user
+  I would like to see the session of this device and logout from it
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/users/me/sessions" and save it as "LIST_SESSIONS_REQUEST"
+    Given I set following headers for prepared request "LIST_SESSIONS_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "LIST_SESSIONS_REQUEST"
+    Then the response status code should be 200
+    And the "JSON" node "data.#(current==true).device_name" should be "string" of value "godog"
+    And I save from the last response "JSON" node "data.#(current==true).id" as "SESSION_ID"
+
+    Given I prepare new "DELETE" request to "{{.APP_URL}}/v1/users/me/sessions/{{.SESSION_ID}}" and save it as "REVOKE_SESSION_REQUEST"
+    Given I set following headers for prepared request "REVOKE_SESSION_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "REVOKE_SESSION_REQUEST"
+    Then the response status code should be 200
+
+    #---------------------------------------------------------------------------------------------------
+    # Access tokens of a revoked session must not be accepted anymore.
+    When I send request "LIST_SESSIONS_REQUEST"
+    Then the response status code should be 401
+
+  Scenario: Revoke a session that does not exist
+  As application user
+  I would like to be told when the session I want to logout from does not exist
+
+    Given I prepare new "DELETE" request to "{{.APP_URL}}/v1/users/me/sessions/9b2b6a1e-4b8f-4a5e-9d55-2f4c1f3a7e10" and save it as "REVOKE_SESSION_REQUEST"
+    Given I set following headers for prepared request "REVOKE_SESSION_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "REVOKE_SESSION_REQUEST"
+    Then the response status code should be 404
@@ -0,0 +1,61 @@
+Feature: User Management
+      In order to manage users, as an administrator
+      I need to list, disable and restore users
+
+  Background:
+  This section runs before every Scenario. Its main purpose is to login as administrator
+  and save the access token under provided key in scenario cache.
+
+    Given I save "http://localhost:8081" as "APP_URL"
+    Given I save "rifqiakram57@gmail.com" as "ADMIN_EMAIL"
+    Given I save "testing1234" as "ADMIN_PASSWORD"
+    Given I prepare new "POST" request to "{{.APP_URL}}/v1/auth/login" and save it as "LOGIN_REQUEST"
+    Given I set following body for prepared request "LOGIN_REQUEST":
+    """
+    {
+        "email": "{{.ADMIN_EMAIL}}",
+        "password": "{{.ADMIN_PASSWORD}}"
+    }
+    """
+    When I send request "LOGIN_REQUEST"
+    Then the response status code should be 200
+    And I save from the last response "JSON" node "data.token" as "AUTH_TOKEN"
+
+  Scenario: Search users page by page
+  As an administrator
+  I would like to search users by username or email and browse the result page by page
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/admin/users?search=rifqi&sort_by=created_at&sort_order=asc&page_size=1" and save it as "LIST_USERS_REQUEST"
+    Given I set following headers for prepared request "LIST_USERS_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "LIST_USERS_REQUEST"
+    Then the response status code should be 200
+    And the response body should have format "JSON"
+    And the "JSON" node "data" should be "slice"
+    And the "JSON" node "data[0].email" should be "string" of value "{{.ADMIN_EMAIL}}"
+
+  Scenario: Use page token with another sorting
+  As an administrator
+  I would like to be told when a page token does not belong to the requested sorting
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/admin/users?sort_by=email&page_token=invalid" and save it as "LIST_USERS_REQUEST"
+    Given I set following headers for prepared request "LIST_USERS_REQUEST":
+    """
+    {
+        "Authorization": "Bearer {{.AUTH_TOKEN}}"
+    }
+    """
+    When I send request "LIST_USERS_REQUEST"
+    Then the response status code should be 400
+
+  Scenario: List users without permission
+  As application user without administrator role
+  I must not be able to list users
+
+    Given I prepare new "GET" request to "{{.APP_URL}}/v1/admin/users" and save it as "LIST_USERS_REQUEST"
+    When I send request "LIST_USERS_REQUEST"
+    Then the response status code should be 401
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"grpc-starter/common/oidc/oidctest"
 	"grpc-starter/test/defs"
 	"io/ioutil"
 	"log"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strings"
@@ -29,11 +31,20 @@ const (
 
 	// envJsonSchemaDir path to JSON schemas dir - relative path from this file's directory.
 	envJsonSchemaDir = "GODOG_JSON_SCHEMA_DIR"
+
+	// envOIDCIssuer describes URL of the OpenID Connect issuer trusted by "My app", the suite serves a local issuer on it.
+	envOIDCIssuer = "OIDC_PROVIDER_ISSUER"
+
+	// envOIDCClientIDs describes client ids accepted by "My app", ID tokens are signed for the first of them.
+	envOIDCClientIDs = "OIDC_PROVIDER_CLIENT_IDS"
 )
 
 // opt defines options for godog CLI while running tests from "go test" command.
 var opt = godog.Options{Output: colors.Colored(os.Stdout), Format: "progress", Randomize: time.Now().UTC().UnixNano()}
 
+// oidcIssuer is the local OpenID Connect issuer signing ID tokens for scenarios, it is started before the suite.
+var oidcIssuer *oidctest.Issuer
+
 func init() {
 	godog.BindCommandLineFlags("godog.", &opt)
 	checkErr(godotenv.Load()) // loading environment variables from .env file
@@ -42,11 +53,34 @@ func init() {
 func TestMain(m *testing.M) {
 	pflag.Parse()
 	opt.Paths = pflag.Args()
-	status := godog.TestSuite{Name: "godogs", ScenarioInitializer: InitializeScenario, Options: &opt}.Run()
+	status := godog.TestSuite{Name: "godogs", TestSuiteInitializer: InitializeTestSuite, ScenarioInitializer: InitializeScenario, Options: &opt}.Run()
 
 	os.Exit(status)
 }
 
+func InitializeTestSuite(ctx *godog.TestSuiteContext) {
+	var oidcServer *httptest.Server
+
+	// The local issuer is served on the issuer URL "My app" trusts, so it accepts ID tokens signed by the suite.
+	// Both read it from the same .env file, when it is empty the suite runs without an issuer.
+	ctx.BeforeSuite(func() {
+		issuerURL := os.Getenv(envOIDCIssuer)
+		if issuerURL == "" {
+			return
+		}
+
+		var err error
+		oidcIssuer, oidcServer, err = oidctest.NewServerOn(issuerURL)
+		checkErr(err)
+	})
+
+	ctx.AfterSuite(func() {
+		if oidcServer != nil {
+			oidcServer.Close()
+		}
+	})
+}
+
 func InitializeScenario(ctx *godog.ScenarioContext) {
 	isDebug := strings.ToLower(os.Getenv(envDebug)) == "true"
 	wd, err := os.Getwd()
@@ -58,7 +92,11 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 		If you would like to replace any of default state's utility services with your own, read:
 		https://github.com/pawelWritesCode/godog-http-api/wiki/Steps-development#utilityServices
 	*/
-	scenario := defs.Scenario{APIContext: gdutils.NewDefaultAPIContext(isDebug, path.Join(wd, os.Getenv(envJsonSchemaDir)))}
+	scenario := defs.Scenario{
+		APIContext:   gdutils.NewDefaultAPIContext(isDebug, path.Join(wd, os.Getenv(envJsonSchemaDir))),
+		OIDCIssuer:   oidcIssuer,
+		OIDCClientID: strings.Split(os.Getenv(envOIDCClientIDs), ";")[0],
+	}
 
 	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 		scenario.APIContext.ResetState(isDebug)
@@ -103,6 +141,7 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^I generate a random "(int|float)" in the range from "([^"]*)" to "([^"]*)" and save it as "([^"]*)"$`, scenario.IGenerateARandomNumberInTheRangeFromToAndSaveItAs)
 	ctx.Step(`^I generate a random bool value and save it as "([^"]*)"$`, scenario.IGenerateRandomBoolValueAndSaveItAs)
 	ctx.Step(`^I generate current time and travel "(backward|forward)" "([^"]*)" in time and save it as "([^"]*)"$`, scenario.IGenerateCurrentTimeAndTravelByAndSaveItAs)
+	ctx.Step(`^I sign an id token for subject "([^"]*)" with email "([^"]*)" and save it as "([^"]*)"$`, scenario.ISignAnIDTokenForSubjectWithEmailAndSaveItAs)
 
 	/*
 	   |----------------------------------------------------------------------------------------------------------------
@@ -1,11 +1,21 @@
 package entity
 
+const (
+	// SendEmailTopicName is the pubsub topic consumed by the send email subscription
+	SendEmailTopicName = "send-email"
+	// RedactedContent replaces secret content of notifications in the sent logs
+	RedactedContent = "[REDACTED]"
+)
+
 // EmailPayload is the payload for sending email
 type EmailPayload struct {
 	To       string `json:"to"`
 	Subject  string `json:"subject"`
 	Content  string `json:"content"`
 	Category string `json:"category"`
+	// Secret marks content carrying credentials such as links with tokens or one-time passwords,
+	// secret content is sent but never logged nor stored
+	Secret bool `json:"secret"`
 }
 
 // NewEmailPayload is the constructor for EmailPayload
@@ -17,3 +27,10 @@ func NewEmailPayload(to, subject, content, category string) *EmailPayload {
 		Category: category,
 	}
 }
+
+// NewSecretEmailPayload is the constructor for EmailPayload whose content carries credentials
+func NewSecretEmailPayload(to, subject, content, category string) *EmailPayload {
+	payload := NewEmailPayload(to, subject, content, category)
+	payload.Secret = true
+	return payload
+}
@@ -31,3 +31,32 @@ func TestNewEmailPayloadEntity(t *testing.T) {
 		}
 	}
 }
+
+func TestNewSecretEmailPayloadEntity(t *testing.T) {
+	t.Log("TestNewSecretEmailPayloadEntity")
+
+	to := "to"
+	subject := "subject"
+	body := "body"
+	category := "category"
+	e := entity.NewSecretEmailPayload(to, subject, body, category)
+	if e == nil {
+		t.Error("NewSecretEmailPayload() returned nil")
+	} else {
+		if e.To != to {
+			t.Error("NewSecretEmailPayload() returned incorrect To")
+		}
+		if e.Subject != subject {
+			t.Error("NewSecretEmailPayload() returned incorrect Subject")
+		}
+		if e.Content != body {
+			t.Error("NewSecretEmailPayload() returned incorrect Content")
+		}
+		if e.Category != category {
+			t.Error("NewSecretEmailPayload() returned incorrect Category")
+		}
+		if !e.Secret {
+			t.Error("NewSecretEmailPayload() returned payload that is not secret")
+		}
+	}
+}
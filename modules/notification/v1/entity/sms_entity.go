@@ -2,11 +2,35 @@ package entity
 
 import "time"
 
-// SMSPayload is the payload for sending email
+const (
+	// SendSMSTopicName is the pubsub topic consumed by the send sms subscription
+	SendSMSTopicName = "send-sms"
+)
+
+// SMSPayload is the payload for sending sms
 type SMSPayload struct {
 	To       string `json:"to"`
 	Content  string `json:"content"`
 	Category string `json:"category"`
+	// Secret marks content carrying credentials such as one-time passwords,
+	// secret content is sent but never logged nor stored
+	Secret bool `json:"secret"`
+}
+
+// NewSMSPayload is the constructor for SMSPayload
+func NewSMSPayload(to, content, category string) *SMSPayload {
+	return &SMSPayload{
+		To:       to,
+		Content:  content,
+		Category: category,
+	}
+}
+
+// NewSecretSMSPayload is the constructor for SMSPayload whose content carries credentials
+func NewSecretSMSPayload(to, content, category string) *SMSPayload {
+	payload := NewSMSPayload(to, content, category)
+	payload.Secret = true
+	return payload
 }
 
 // SMSCallback is the callback response from wave cell
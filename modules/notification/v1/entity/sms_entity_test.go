@@ -0,0 +1,54 @@
+package entity_test
+
+import (
+	"testing"
+
+	"grpc-starter/modules/notification/v1/entity"
+)
+
+func TestNewSMSPayloadEntity(t *testing.T) {
+	t.Log("TestNewSMSPayloadEntity")
+
+	to := "to"
+	body := "body"
+	category := "category"
+	e := entity.NewSMSPayload(to, body, category)
+	if e == nil {
+		t.Error("NewSMSPayloadEntity() returned nil")
+	} else {
+		if e.To != to {
+			t.Error("NewSMSPayloadEntity() returned incorrect To")
+		}
+		if e.Content != body {
+			t.Error("NewSMSPayloadEntity() returned incorrect Body")
+		}
+		if e.Category != category {
+			t.Error("NewSMSPayloadEntity() returned incorrect Category")
+		}
+	}
+}
+
+func TestNewSecretSMSPayloadEntity(t *testing.T) {
+	t.Log("TestNewSecretSMSPayloadEntity")
+
+	to := "to"
+	body := "body"
+	category := "category"
+	e := entity.NewSecretSMSPayload(to, body, category)
+	if e == nil {
+		t.Error("NewSecretSMSPayload() returned nil")
+	} else {
+		if e.To != to {
+			t.Error("NewSecretSMSPayload() returned incorrect To")
+		}
+		if e.Content != body {
+			t.Error("NewSecretSMSPayload() returned incorrect Content")
+		}
+		if e.Category != category {
+			t.Error("NewSecretSMSPayload() returned incorrect Category")
+		}
+		if !e.Secret {
+			t.Error("NewSecretSMSPayload() returned payload that is not secret")
+		}
+	}
+}
@@ -9,6 +9,17 @@ import (
 	"grpc-starter/common/tools"
 )
 
+const (
+	// SMSSentStatusNoRecipient is a constant for no recipient sms status
+	SMSSentStatusNoRecipient = "NO_RECIPIENT"
+	// SMSSentStatusOutgoing is a constant for outgoing sms status
+	SMSSentStatusOutgoing = "OUTGOING"
+	// SMSSentStatusSuccess is a constant for success sms status
+	SMSSentStatusSuccess = "SUCCESS"
+	// SMSSentStatusFailed is a constant for failed sms status
+	SMSSentStatusFailed = "FAILED"
+)
+
 // SMSSent represents table on db
 type SMSSent struct {
 	ID          int
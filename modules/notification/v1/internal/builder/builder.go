@@ -17,3 +17,11 @@ func BuildSendEmailPubSubHandler(db *gorm.DB, config config.Config) *handler.Sen
 
 	return handler.NewSendEmailPubSubHandler(config, svc)
 }
+
+// BuildSendSMSPubSubHandler is used to build the send sms pubsub handler.
+func BuildSendSMSPubSubHandler(db *gorm.DB, config config.Config) *handler.SendSMSPubSubHandler {
+	repo := repository.NewSMSSent(db)
+	svc := service.NewSMSSender(repo, config.Wavecell)
+
+	return handler.NewSendSMSPubSubHandler(svc)
+}
@@ -45,8 +45,8 @@ func (pubsub *SendEmailPubSubHandler) SubscriptionName() string {
 
 // ProcessMessage is a function for processing message from pubsub
 func (pubsub *SendEmailPubSubHandler) ProcessMessage(ctx context.Context, m *pubsub.Message) {
-	// log message from pubsub
-	logger.Info(fmt.Sprintf("Received message: %s", m.Data))
+	// log message id only, the content may carry reset links and one-time passwords
+	logger.Info(fmt.Sprintf("Received message: %s", m.ID))
 
 	ctxSpan, span := trace.StartSpan(ctx, "Notification-SendEmailPubSubHandler-ProcessMessage")
 	defer span.End()
@@ -67,7 +67,7 @@ func (pubsub *SendEmailPubSubHandler) ProcessMessage(ctx context.Context, m *pub
 	// send email
 	err := pubsub.emailSenderSvc.SendWithSendgridAPI(
 		ctxSpan, m.ID, pubsub.cfg.SMTP.FromName, pubsub.cfg.SMTP.FromEmail,
-		payload.To, payload.Subject, payload.Content, payload.Category, pubsub.SubscriptionName(), m)
+		payload.To, payload.Subject, payload.Content, payload.Category, payload.Secret, pubsub.SubscriptionName(), m)
 	if err != nil {
 		log.Print(errors.Wrap(err, fmt.Sprintf("[SendEmailPubSubHandler-ProcessMessage] error send email svc: %s", m.Attributes)))
 		span.SetStatus(trace.Status{
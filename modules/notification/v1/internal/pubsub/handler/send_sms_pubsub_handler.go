@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"grpc-starter/common/logger"
+	"grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/notification/v1/service"
+)
+
+// SendSMSPubSubHandler struct
+type SendSMSPubSubHandler struct {
+	smsSenderSvc service.SMSSenderUsecase
+}
+
+const (
+	// SendSMSSubName is a subscriber name for SendSMSSub
+	SendSMSSubName = "send-sms-sub"
+)
+
+// NewSendSMSPubSubHandler create send sms pubsub handler
+func NewSendSMSPubSubHandler(
+	smsSenderSvc service.SMSSenderUsecase,
+) *SendSMSPubSubHandler {
+	return &SendSMSPubSubHandler{
+		smsSenderSvc: smsSenderSvc,
+	}
+}
+
+// SubscriptionName is a function for getting subscription name
+func (pubsub *SendSMSPubSubHandler) SubscriptionName() string {
+	return SendSMSSubName
+}
+
+// ProcessMessage is a function for processing message from pubsub
+func (pubsub *SendSMSPubSubHandler) ProcessMessage(ctx context.Context, m *pubsub.Message) {
+	// log message id only, the content carries one-time passwords
+	logger.Info(fmt.Sprintf("Received message: %s", m.ID))
+
+	ctxSpan, span := trace.StartSpan(ctx, "Notification-SendSMSPubSubHandler-ProcessMessage")
+	defer span.End()
+
+	var payload entity.SMSPayload
+
+	// parsing json payload
+	if err := json.Unmarshal(m.Data, &payload); err != nil {
+		log.Print(errors.Wrap(err, fmt.Sprintf("[SendSMSPubSubHandler-ProcessMessage] error unmarshal: %s", m.Attributes)))
+		m.Ack()
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInternal,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// send sms
+	err := pubsub.smsSenderSvc.SendWithWavecellAPI(
+		ctxSpan, m.ID, payload.To, payload.Content, payload.Category, payload.Secret, pubsub.SubscriptionName(), m)
+	if err != nil {
+		log.Print(errors.Wrap(err, fmt.Sprintf("[SendSMSPubSubHandler-ProcessMessage] error send sms svc: %s", m.Attributes)))
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInternal,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(trace.Status{
+		Code: trace.StatusCodeOK,
+	})
+}
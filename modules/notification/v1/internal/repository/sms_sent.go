@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"gorm.io/gorm"
+
+	"grpc-starter/modules/notification/v1/entity"
+)
+
+// SMSSent struct
+type SMSSent struct {
+	gormDB *gorm.DB
+}
+
+// NewSMSSent will create new sms sent repository
+func NewSMSSent(db *gorm.DB) *SMSSent {
+	return &SMSSent{db}
+}
+
+// Insert will insert notification sms sent to database, or mark it outgoing again when the message is redelivered
+func (r *SMSSent) Insert(ctx context.Context, smsSent *entity.SMSSent) error {
+	ctxSpan, span := trace.StartSpan(ctx, "Notification-SMSSentRepository-Insert")
+	defer span.End()
+
+	exist := &entity.SMSSent{}
+	err := r.gormDB.
+		WithContext(ctxSpan).
+		Where("m_id = ?", smsSent.MId).
+		First(exist).
+		Error
+	if err == nil {
+		smsSent.ClientMId = exist.ClientMId
+		return r.gormDB.
+			WithContext(ctxSpan).
+			Model(&entity.SMSSent{}).
+			Where("m_id = ?", smsSent.MId).
+			Update("status", entity.SMSSentStatusOutgoing).
+			Error
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	return r.gormDB.
+		WithContext(ctxSpan).
+		Model(&entity.SMSSent{}).
+		Create(smsSent).
+		Error
+}
+
+// UpdateStatus will update status and status notes of sms sent
+func (r *SMSSent) UpdateStatus(ctx context.Context, smsSent *entity.SMSSent) error {
+	ctxSpan, span := trace.StartSpan(ctx, "Notification-SMSSentRepository-UpdateStatus")
+	defer span.End()
+
+	return r.gormDB.
+		WithContext(ctxSpan).
+		Model(&entity.SMSSent{}).
+		Where("m_id = ?", smsSent.MId).
+		UpdateColumns(map[string]interface{}{
+			"status":       smsSent.Status,
+			"status_notes": smsSent.StatusNotes,
+		}).
+		Error
+}
@@ -15,3 +15,9 @@ func InitSendEmailSubscription(ctx context.Context, db *gorm.DB, config config.C
 	sendEmailHandler := builder.BuildSendEmailPubSubHandler(db, config)
 	return sendEmailHandler
 }
+
+// InitSendSMSSubscription initialize subscription for sending sms
+func InitSendSMSSubscription(ctx context.Context, db *gorm.DB, config config.Config) pubsubSDK.Subscriber {
+	sendSMSHandler := builder.BuildSendSMSPubSubHandler(db, config)
+	return sendSMSHandler
+}
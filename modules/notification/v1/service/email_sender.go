@@ -20,10 +20,10 @@ import (
 
 // EmailSenderUsecase is use case for creating new ptk
 type EmailSenderUsecase interface {
-	// SendWithMailgunAPI send email using mailgun api
-	SendWithMailgunAPI(ctx context.Context, mID, from, to, subject, message, category, creator string, pubsubMessage *pubsub.Message) error
-	// SendWithSendgridAPI send email using sendgrid api
-	SendWithSendgridAPI(ctx context.Context, mID, senderName, senderEmail, to, subject, message, category, creator string, pubsubMessage *pubsub.Message) error
+	// SendWithMailgunAPI send email using mailgun api, secret message is not stored
+	SendWithMailgunAPI(ctx context.Context, mID, from, to, subject, message, category string, secret bool, creator string, pubsubMessage *pubsub.Message) error
+	// SendWithSendgridAPI send email using sendgrid api, secret message is not stored
+	SendWithSendgridAPI(ctx context.Context, mID, senderName, senderEmail, to, subject, message, category string, secret bool, creator string, pubsubMessage *pubsub.Message) error
 }
 
 // EmailSentRepository is use case for creating new ptk
@@ -50,8 +50,8 @@ func NewEmailSender(repository EmailSentRepository, mailgunConfig config.Mailgun
 	}
 }
 
-// SendWithMailgunAPI send email using mailgun api and save to database
-func (s *EmailSender) SendWithMailgunAPI(ctx context.Context, mID, from, to, subject, message, category, creator string, pubsubMessage *pubsub.Message) error {
+// SendWithMailgunAPI send email using mailgun api and save to database, secret message is saved redacted
+func (s *EmailSender) SendWithMailgunAPI(ctx context.Context, mID, from, to, subject, message, category string, secret bool, creator string, pubsubMessage *pubsub.Message) error {
 	ctxSpan, span := trace.StartSpan(ctx, "Notification-EmailSenderService-SendWithMailgunAPI")
 	defer span.End()
 
@@ -63,7 +63,7 @@ func (s *EmailSender) SendWithMailgunAPI(ctx context.Context, mID, from, to, sub
 	}
 
 	// save sent message to repository
-	emailSent := entity.NewEmailSent(mID, from, to, subject, message,
+	emailSent := entity.NewEmailSent(mID, from, to, subject, storedContent(message, secret),
 		status, category, creator)
 
 	err := s.emailSentRepo.Insert(ctxSpan, emailSent)
@@ -115,8 +115,8 @@ func (s *EmailSender) SendWithMailgunAPI(ctx context.Context, mID, from, to, sub
 	return s.emailSentRepo.UpdateStatus(ctxSpan, updateEmailSent)
 }
 
-// SendWithSendgridAPI send email using mailgun api and save to database
-func (s *EmailSender) SendWithSendgridAPI(ctx context.Context, mID, senderName, senderEmail, to, subject, message, category, creator string, pubsubMessage *pubsub.Message) error {
+// SendWithSendgridAPI send email using mailgun api and save to database, secret message is saved redacted
+func (s *EmailSender) SendWithSendgridAPI(ctx context.Context, mID, senderName, senderEmail, to, subject, message, category string, secret bool, creator string, pubsubMessage *pubsub.Message) error {
 	ctxSpan, span := trace.StartSpan(ctx, "Notification-EmailSenderService-SendWithSendgridAPI")
 	defer span.End()
 
@@ -129,7 +129,7 @@ func (s *EmailSender) SendWithSendgridAPI(ctx context.Context, mID, senderName,
 
 	// save sent message to repository
 	from := fmt.Sprintf("%s <%s>", senderName, senderEmail)
-	emailSent := entity.NewEmailSent(mID, from, to, subject, message,
+	emailSent := entity.NewEmailSent(mID, from, to, subject, storedContent(message, secret),
 		status, category, creator)
 
 	err := s.emailSentRepo.Insert(ctxSpan, emailSent)
@@ -183,3 +183,12 @@ func (s *EmailSender) SendWithSendgridAPI(ctx context.Context, mID, senderName,
 	}
 	return s.emailSentRepo.UpdateStatus(ctxSpan, updateEmailSent)
 }
+
+// storedContent returns content to be saved in the sent logs, secret content is redacted
+func storedContent(content string, secret bool) string {
+	if secret {
+		return entity.RedactedContent
+	}
+
+	return content
+}
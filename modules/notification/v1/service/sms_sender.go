@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/uuid"
+	"go.opencensus.io/trace"
+
+	"grpc-starter/common/config"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/notification/v1/entity"
+)
+
+const (
+	// wavecellSingleSMSPath is the wavecell endpoint for sending single sms, formatted with sub account id
+	wavecellSingleSMSPath = "%s/sms/v1/%s/single"
+	// wavecellEncoding lets wavecell pick the encoding based on the content
+	wavecellEncoding = "AUTO"
+)
+
+// SMSSenderUsecase is use case for sending sms
+type SMSSenderUsecase interface {
+	// SendWithWavecellAPI send sms using wavecell api, secret message is not stored
+	SendWithWavecellAPI(ctx context.Context, mID, to, message, category string, secret bool, creator string, pubsubMessage *pubsub.Message) error
+}
+
+// SMSSentRepository is repository for sms sent log
+type SMSSentRepository interface {
+	// Insert insert sms sent log to database
+	Insert(ctx context.Context, ent *entity.SMSSent) error
+	// UpdateStatus update status sms sent
+	UpdateStatus(ctx context.Context, smsSent *entity.SMSSent) error
+}
+
+// SMSSender is use case for sending sms
+type SMSSender struct {
+	smsSentRepo    SMSSentRepository
+	wavecellConfig config.Wavecell
+}
+
+// NewSMSSender is constructor for SMSSender
+func NewSMSSender(repository SMSSentRepository, wavecellConfig config.Wavecell) *SMSSender {
+	return &SMSSender{
+		smsSentRepo:    repository,
+		wavecellConfig: wavecellConfig,
+	}
+}
+
+// SendWithWavecellAPI send sms using wavecell api and save to database, secret message is saved redacted
+func (s *SMSSender) SendWithWavecellAPI(ctx context.Context, mID, to, message, category string, secret bool, creator string, pubsubMessage *pubsub.Message) error {
+	ctxSpan, span := trace.StartSpan(ctx, "Notification-SMSSenderService-SendWithWavecellAPI")
+	defer span.End()
+
+	var status string
+	if len(strings.TrimSpace(to)) == 0 {
+		status = entity.SMSSentStatusNoRecipient
+	} else {
+		status = entity.SMSSentStatusOutgoing
+	}
+
+	// save sent message to repository
+	smsSent := entity.NewSMSSent(mID, uuid.New(), to, storedContent(message, secret), status, "", category, creator)
+
+	if err := s.smsSentRepo.Insert(ctxSpan, smsSent); err != nil {
+		pubsubMessage.Nack()
+		log.Print("failed to save sms sent", err)
+		return err
+	}
+
+	if status == entity.SMSSentStatusNoRecipient {
+		pubsubMessage.Ack()
+		return errors.New("no recipient")
+	}
+
+	if err := s.callWavecell(smsSent, message); err != nil {
+		failedSMSSent := &entity.SMSSent{
+			MId:         mID,
+			Status:      entity.SMSSentStatusFailed,
+			StatusNotes: sql.NullString{String: err.Error(), Valid: true},
+		}
+		_ = s.smsSentRepo.UpdateStatus(ctxSpan, failedSMSSent)
+
+		pubsubMessage.Nack()
+		return err
+	}
+
+	pubsubMessage.Ack()
+
+	updateSMSSent := &entity.SMSSent{
+		MId:    mID,
+		Status: entity.SMSSentStatusSuccess,
+	}
+	return s.smsSentRepo.UpdateStatus(ctxSpan, updateSMSSent)
+}
+
+// callWavecell sends message of the sms to wavecell, the client message id lets wavecell drop redelivered messages
+func (s *SMSSender) callWavecell(smsSent *entity.SMSSent, message string) error {
+	body := entity.SMSBodyRequest{
+		Destination:     smsSent.To,
+		Country:         s.wavecellConfig.Country,
+		Text:            message,
+		Source:          s.wavecellConfig.Source,
+		ClientMessageID: smsSent.ClientMId.String(),
+		Encoding:        wavecellEncoding,
+		DlrCallbackURL:  s.wavecellConfig.CallbackURL,
+	}
+
+	headers := []tools.CallerHeader{
+		{Key: "Authorization", Value: "Bearer " + s.wavecellConfig.APIKey},
+	}
+
+	url := fmt.Sprintf(wavecellSingleSMSPath, s.wavecellConfig.BaseURL, s.wavecellConfig.SubAccountID)
+	res, err := tools.CallAPI(http.MethodPost, url, headers, body, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		var errorResponse entity.SMSErrorResponse
+		if err := json.NewDecoder(res.Body).Decode(&errorResponse); err != nil {
+			return fmt.Errorf("wavecell responded with status %d", res.StatusCode)
+		}
+		return fmt.Errorf("wavecell responded with status %d: %s", res.StatusCode, errorResponse.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"time"
+
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+)
+
+// DataExport defines personal data of a user kept across modules, exported on request of the user.
+// Secrets such as password hash, MFA secret and token hashes are never part of it, neither is the content
+// of sent notifications, which may carry reset links and one-time passwords that are still valid.
+type DataExport struct {
+	ExportedAt time.Time          `json:"exported_at"`
+	User       ExportedUser       `json:"user"`
+	Identities []ExportedIdentity `json:"identities"`
+	Sessions   []ExportedSession  `json:"sessions"`
+	EmailsSent []ExportedEmail    `json:"emails_sent"`
+	SMSSent    []ExportedSMS      `json:"sms_sent"`
+}
+
+// ExportedUser defines exported row of users table
+type ExportedUser struct {
+	ID                    string     `json:"id"`
+	Username              string     `json:"username,omitempty"`
+	Email                 string     `json:"email"`
+	PhoneNumber           string     `json:"phone_number,omitempty"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at,omitempty"`
+	PhoneNumberVerifiedAt *time.Time `json:"phone_number_verified_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// ExportedIdentity defines exported row of user identities table
+type ExportedIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportedSession defines exported row of user sessions table
+type ExportedSession struct {
+	ID         string     `json:"id"`
+	DeviceName string     `json:"device_name,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ExportedEmail defines exported row of email sent table, without its content
+type ExportedEmail struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Status    string    `json:"status"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportedSMS defines exported row of sms sent table, without its content
+type ExportedSMS struct {
+	To        string    `json:"to"`
+	Status    string    `json:"status"`
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewDataExport creates new DataExport from rows of the user across modules
+func NewDataExport(
+	user *User,
+	identities []*UserIdentity,
+	sessions []*Session,
+	emailsSent []*notificationEntity.EmailSent,
+	smsSent []*notificationEntity.SMSSent,
+) *DataExport {
+	export := &DataExport{
+		ExportedAt: time.Now(),
+		User: ExportedUser{
+			ID:                    user.ID.String(),
+			Username:              user.Username.String,
+			Email:                 user.Email,
+			PhoneNumber:           user.PhoneNumber.String,
+			EmailVerifiedAt:       nullTimePtr(user.EmailVerifiedAt.Time, user.EmailVerifiedAt.Valid),
+			PhoneNumberVerifiedAt: nullTimePtr(user.PhoneNumberVerifiedAt.Time, user.PhoneNumberVerifiedAt.Valid),
+			CreatedAt:             user.CreatedAt,
+			UpdatedAt:             user.UpdatedAt,
+		},
+		Identities: make([]ExportedIdentity, 0, len(identities)),
+		Sessions:   make([]ExportedSession, 0, len(sessions)),
+		EmailsSent: make([]ExportedEmail, 0, len(emailsSent)),
+		SMSSent:    make([]ExportedSMS, 0, len(smsSent)),
+	}
+
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, ExportedIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, ExportedSession{
+			ID:         session.ID.String(),
+			DeviceName: session.DeviceName.String,
+			UserAgent:  session.UserAgent.String,
+			IPAddress:  session.IPAddress.String,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			RevokedAt:  nullTimePtr(session.RevokedAt.Time, session.RevokedAt.Valid),
+		})
+	}
+
+	for _, email := range emailsSent {
+		export.EmailsSent = append(export.EmailsSent, ExportedEmail{
+			From:      email.From,
+			To:        email.To,
+			Subject:   email.Subject,
+			Status:    email.Status,
+			Category:  email.Category,
+			CreatedAt: email.CreatedAt,
+		})
+	}
+
+	for _, sms := range smsSent {
+		export.SMSSent = append(export.SMSSent, ExportedSMS{
+			To:        sms.To,
+			Status:    sms.Status,
+			Category:  sms.Category.String,
+			CreatedAt: sms.CreatedAt,
+		})
+	}
+
+	return export
+}
+
+// nullTimePtr returns pointer to t when it is valid, so that missing time is left out of the export
+func nullTimePtr(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+
+	return &t
+}
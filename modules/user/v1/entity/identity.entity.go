@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+
+	commonentity "grpc-starter/common/entity"
+)
+
+const (
+	// UserIdentityTableName represents table name on db
+	UserIdentityTableName = "users.user_identities"
+)
+
+// UserIdentity defines table for account of an OpenID Connect provider linked to a user.
+// The provider and subject pair identifies the account, the email is only kept for reference
+// because it may change on the provider side.
+type UserIdentity struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email"`
+	commonentity.Auditable
+}
+
+// NewUserIdentity creates new UserIdentity
+func NewUserIdentity(userID uuid.UUID, provider string, subject string, email string, createdBy string) *UserIdentity {
+	return &UserIdentity{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     NormalizeEmail(email),
+		Auditable: commonentity.NewAuditable(createdBy),
+	}
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (i *UserIdentity) TableName() string {
+	return UserIdentityTableName
+}
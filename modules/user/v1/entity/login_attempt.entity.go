@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+const (
+	// LoginAttemptScopeAccount counts failed logins of an email
+	LoginAttemptScopeAccount = "account"
+	// LoginAttemptScopeIP counts failed logins from an ip address
+	LoginAttemptScopeIP = "ip"
+)
+
+// LoginAttempt counts consecutive failed logins, it is stored in cache and never in database
+type LoginAttempt struct {
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// IsLocked checks whether login is locked at the given time
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// RegisterFailure records failures, the count of failed logins including the one failed at the given time,
+// as returned by the atomic failure counter. Once failures reach maxFailures, login is locked for baseLockout,
+// the lockout doubles with every following failure up to maxLockout.
+func (a *LoginAttempt) RegisterFailure(now time.Time, failures int, maxFailures int, baseLockout, maxLockout time.Duration) {
+	a.Failures = failures
+	if a.Failures < maxFailures {
+		return
+	}
+
+	lockout := baseLockout
+	for i := maxFailures; i < a.Failures && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+
+	a.LockedUntil = now.Add(lockout)
+}
@@ -0,0 +1,45 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+)
+
+func TestLoginAttempt_RegisterFailure(t *testing.T) {
+	now := time.Now()
+	baseLockout := 30 * time.Second
+	maxLockout := 5 * time.Minute
+
+	tests := []struct {
+		name     string
+		failures int
+		lockout  time.Duration
+	}{
+		{name: "failures below threshold do not lock", failures: 4, lockout: 0},
+		{name: "failure reaching threshold locks for base lockout", failures: 5, lockout: baseLockout},
+		{name: "lockout doubles with every following failure", failures: 6, lockout: 2 * baseLockout},
+		{name: "lockout keeps doubling", failures: 8, lockout: 8 * baseLockout},
+		{name: "lockout is capped at max lockout", failures: 20, lockout: maxLockout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt := new(entity.LoginAttempt)
+			attempt.RegisterFailure(now, tt.failures, 5, baseLockout, maxLockout)
+
+			assert.Equal(t, tt.failures, attempt.Failures)
+			if tt.lockout == 0 {
+				assert.False(t, attempt.IsLocked(now))
+				return
+			}
+
+			assert.True(t, attempt.IsLocked(now))
+			assert.Equal(t, now.Add(tt.lockout), attempt.LockedUntil)
+			assert.False(t, attempt.IsLocked(now.Add(tt.lockout)))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	commonentity "grpc-starter/common/entity"
+	"grpc-starter/common/tools"
+)
+
+const (
+	// UserMFATableName represents table name on db
+	UserMFATableName = "users.user_mfa"
+	// RecoveryCodeTableName represents table name on db
+	RecoveryCodeTableName = "users.user_recovery_codes"
+)
+
+// UserMFA defines table for time-based one-time password of a user.
+// It is only enforced on login after the user confirms it with a code from its authenticator app.
+type UserMFA struct {
+	UserID      uuid.UUID    `json:"user_id"`
+	Secret      string       `json:"-"`
+	ConfirmedAt sql.NullTime `json:"confirmed_at"`
+	commonentity.Auditable
+}
+
+// NewUserMFA creates new unconfirmed UserMFA
+func NewUserMFA(userID uuid.UUID, secret string, createdBy string) *UserMFA {
+	return &UserMFA{
+		UserID:    userID,
+		Secret:    secret,
+		Auditable: commonentity.NewAuditable(createdBy),
+	}
+}
+
+// IsConfirmed checks whether the user has confirmed the secret
+func (m *UserMFA) IsConfirmed() bool {
+	return m.ConfirmedAt.Valid
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (m *UserMFA) TableName() string {
+	return UserMFATableName
+}
+
+// RecoveryCode defines table for single use code replacing one-time password when the authenticator app is lost
+type RecoveryCode struct {
+	ID        uuid.UUID      `json:"id"`
+	UserID    uuid.UUID      `json:"user_id"`
+	CodeHash  string         `json:"-"`
+	UsedAt    sql.NullTime   `json:"used_at"`
+	CreatedBy sql.NullString `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// NewRecoveryCode creates new unused RecoveryCode from hashed code
+func NewRecoveryCode(userID uuid.UUID, codeHash string, createdBy string) *RecoveryCode {
+	return &RecoveryCode{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CodeHash:  codeHash,
+		CreatedBy: tools.StringToNullString(createdBy),
+		CreatedAt: time.Now(),
+	}
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (c *RecoveryCode) TableName() string {
+	return RecoveryCodeTableName
+}
+
+// MFAEnrollment is an unconfirmed secret with its otpauth:// URI to be rendered as QR code
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAChallenge is issued by login when password is correct but the user has to provide a one-time password.
+// It is stored in cache under hashed token and never in database.
+type MFAChallenge struct {
+	Token     string    `json:"-"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewMFAChallenge creates new MFAChallenge for user
+func NewMFAChallenge(token string, userID uuid.UUID, ttl time.Duration) *MFAChallenge {
+	return &MFAChallenge{
+		Token:     token,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// OTP is a pending one-time password, it is stored in cache and never in database
+type OTP struct {
+	CodeHash  string    `json:"code_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewOTP creates new OTP from hashed code
+func NewOTP(codeHash string, ttl time.Duration) *OTP {
+	return &OTP{
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+// OTPQuota counts one-time passwords requested within a fixed window, it is stored in cache and never in database
+type OTPQuota struct {
+	Count int `json:"count"`
+}
+
+// IsExceeded checks whether more than the given number of requests has been counted in the current window
+func (q *OTPQuota) IsExceeded(maxRequests int) bool {
+	return q.Count > maxRequests
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	commonentity "grpc-starter/common/entity"
+)
+
+const (
+	// RefreshTokenTableName represents table name on db
+	RefreshTokenTableName = "users.refresh_tokens"
+)
+
+// RefreshToken defines table for refresh token.
+// Every refresh token belongs to a family, which is created on login and carried over on every rotation.
+type RefreshToken struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	FamilyID   uuid.UUID    `json:"family_id"`
+	TokenHash  string       `json:"token_hash"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	UsedAt     sql.NullTime `json:"used_at"`
+	ReplacedBy *uuid.UUID   `json:"replaced_by"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	commonentity.Auditable
+}
+
+// NewRefreshToken creates new RefreshToken
+func NewRefreshToken(
+	id uuid.UUID,
+	userID uuid.UUID,
+	familyID uuid.UUID,
+	tokenHash string,
+	expiresAt time.Time,
+	createdBy string,
+) *RefreshToken {
+	return &RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		Auditable: commonentity.NewAuditable(createdBy),
+	}
+}
+
+// IsExpired checks whether refresh token is expired at given time
+func (r *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IsUsed checks whether refresh token has already been rotated
+func (r *RefreshToken) IsUsed() bool {
+	return r.UsedAt.Valid
+}
+
+// IsRevoked checks whether refresh token has been revoked
+func (r *RefreshToken) IsRevoked() bool {
+	return r.RevokedAt.Valid
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (r *RefreshToken) TableName() string {
+	return RefreshTokenTableName
+}
+
+// TokenPair defines access token and refresh token issued to user
+type TokenPair struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	commonentity "grpc-starter/common/entity"
+	"grpc-starter/common/tools"
+)
+
+const (
+	// RoleTableName represents table name on db
+	RoleTableName = "users.roles"
+	// PermissionTableName represents table name on db
+	PermissionTableName = "users.permissions"
+	// RolePermissionTableName represents table name on db
+	RolePermissionTableName = "users.role_permissions"
+	// UserRoleTableName represents table name on db
+	UserRoleTableName = "users.user_roles"
+
+	// RoleAdmin is the name of administrator role
+	RoleAdmin = "admin"
+	// RoleUser is the name of regular user role, assigned to every newly created user
+	RoleUser = "user"
+
+	// PermissionUsersRead allows reading any user
+	PermissionUsersRead = "users.read"
+	// PermissionUsersWrite allows managing any user
+	PermissionUsersWrite = "users.write"
+	// PermissionRolesAssign allows assigning and revoking roles of users
+	PermissionRolesAssign = "roles.assign"
+)
+
+// Role defines table for role
+type Role struct {
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	commonentity.Auditable
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (r *Role) TableName() string {
+	return RoleTableName
+}
+
+// Permission defines table for permission
+type Permission struct {
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	commonentity.Auditable
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (p *Permission) TableName() string {
+	return PermissionTableName
+}
+
+// UserRole defines table for role assigned to a user
+type UserRole struct {
+	UserID    uuid.UUID      `json:"user_id"`
+	RoleID    uuid.UUID      `json:"role_id"`
+	CreatedBy sql.NullString `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// NewUserRole creates new UserRole
+func NewUserRole(userID uuid.UUID, roleID uuid.UUID, createdBy string) *UserRole {
+	return &UserRole{
+		UserID:    userID,
+		RoleID:    roleID,
+		CreatedBy: tools.StringToNullString(createdBy),
+		CreatedAt: time.Now(),
+	}
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (ur *UserRole) TableName() string {
+	return UserRoleTableName
+}
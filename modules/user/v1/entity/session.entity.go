@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	commonentity "grpc-starter/common/entity"
+)
+
+const (
+	// SessionTableName represents table name on db
+	SessionTableName = "users.user_sessions"
+
+	// maxDeviceNameLength is the maximum length of device name kept for a session
+	maxDeviceNameLength = 100
+	// maxUserAgentLength is the maximum length of user agent kept for a session
+	maxUserAgentLength = 500
+	// maxIPAddressLength is the maximum length of an IPv6 address in text form
+	maxIPAddressLength = 45
+)
+
+// Session defines table for device a user is logged in from.
+// A session shares its id with the refresh token family issued on login,
+// it expires together with the latest refresh token of the family.
+type Session struct {
+	ID         uuid.UUID      `json:"id"`
+	UserID     uuid.UUID      `json:"user_id"`
+	DeviceName sql.NullString `json:"device_name"`
+	UserAgent  sql.NullString `json:"user_agent"`
+	IPAddress  sql.NullString `json:"ip_address"`
+	LastSeenAt time.Time      `json:"last_seen_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	RevokedAt  sql.NullTime   `json:"revoked_at"`
+	commonentity.Auditable
+}
+
+// NewSession creates new Session
+func NewSession(
+	id uuid.UUID,
+	userID uuid.UUID,
+	deviceName string,
+	userAgent string,
+	ipAddress string,
+	expiresAt time.Time,
+	createdBy string,
+) *Session {
+	return &Session{
+		ID:         id,
+		UserID:     userID,
+		DeviceName: truncatedNullString(deviceName, maxDeviceNameLength),
+		UserAgent:  truncatedNullString(userAgent, maxUserAgentLength),
+		IPAddress:  truncatedNullString(ipAddress, maxIPAddressLength),
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+		Auditable:  commonentity.NewAuditable(createdBy),
+	}
+}
+
+// IsActive checks whether session can still be used at given time
+func (s *Session) IsActive(now time.Time) bool {
+	return !s.RevokedAt.Valid && now.Before(s.ExpiresAt)
+}
+
+// TableName represents table name on db, need to define it because the db has multi schema
+func (s *Session) TableName() string {
+	return SessionTableName
+}
+
+// truncatedNullString converts client supplied value to null string, cutting it to fit its column
+func truncatedNullString(value string, maxLength int) sql.NullString {
+	if runes := []rune(value); len(runes) > maxLength {
+		value = string(runes[:maxLength])
+	}
+
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -3,6 +3,8 @@ package entity
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,15 +16,53 @@ import (
 const (
 	// UserTableName represents table name on db
 	UserTableName = "users.users"
+	// EmailCategoryPasswordReset represents email category for password reset email
+	EmailCategoryPasswordReset = "PASSWORD_RESET"
+	// EmailCategoryEmailVerification represents email category for email verification email
+	EmailCategoryEmailVerification = "EMAIL_VERIFICATION"
+	// SMSCategoryPhoneOTP represents sms category for phone number login code
+	SMSCategoryPhoneOTP = "PHONE_OTP"
+	// PhoneNumberDefaultCountryCode is the country calling code of phone numbers given in national format
+	PhoneNumberDefaultCountryCode = "62"
+
+	// ProfileFieldUsername is the update mask path of username
+	ProfileFieldUsername = "username"
+	// ProfileFieldPhoneNumber is the update mask path of phone number
+	ProfileFieldPhoneNumber = "phone_number"
+
+	// UserStatusActive represents user that can login
+	UserStatusActive = "ACTIVE"
+	// UserStatusDisabled represents user disabled by an administrator
+	UserStatusDisabled = "DISABLED"
+	// UserStatusDeleted represents soft deleted user
+	UserStatusDeleted = "DELETED"
 )
 
+// phoneNumberSeparators removes characters commonly used to group digits of phone numbers
+var phoneNumberSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// userUniqueConstraints maps unique constraints of users table to the field they guard
+var userUniqueConstraints = map[string]string{
+	"users_email_key":        "email",
+	"users_username_key":     "username",
+	"users_phone_number_key": "phone_number",
+}
+
 // User defines table for user
 type User struct {
-	ID          uuid.UUID      `json:"id"`
-	Username    sql.NullString `json:"username"`
-	Email       string         `json:"email"`
-	Password    string         `json:"password"`
-	PhoneNumber sql.NullString `json:"phone_number"`
+	ID                    uuid.UUID      `json:"id"`
+	Username              sql.NullString `json:"username"`
+	Email                 string         `json:"email"`
+	Password              string         `json:"password"`
+	PhoneNumber           sql.NullString `json:"phone_number"`
+	DisabledAt            sql.NullTime   `json:"disabled_at"`
+	EmailVerifiedAt       sql.NullTime   `json:"email_verified_at"`
+	PhoneNumberVerifiedAt sql.NullTime   `json:"phone_number_verified_at"`
+	ErasedAt              sql.NullTime   `json:"erased_at"`
+	// EraseFailedAt is the last time erasing the user failed, the user is retried once the retry delay has passed
+	EraseFailedAt sql.NullTime `json:"erase_failed_at"`
+	// Version is incremented on every update, an update only succeeds against the version it was read with
+	Version int64 `json:"version"`
 	commonentity.Auditable
 }
 
@@ -31,29 +71,38 @@ func NewUser(
 	id uuid.UUID,
 	username string,
 	email string,
-	password string,
+	passwordHash string,
 	phoneNumber string,
 	createdBy string,
 ) *User {
-	encrypted, _ := tools.BcryptEncrypt(password)
-
 	return &User{
 		ID:          id,
-		Username:    tools.StringToNullString(username),
-		Email:       email,
-		Password:    encrypted,
-		PhoneNumber: tools.StringToNullString(phoneNumber),
+		Username:    tools.EmptyStringToNullString(username),
+		Email:       NormalizeEmail(email),
+		Password:    passwordHash,
+		PhoneNumber: tools.EmptyStringToNullString(NormalizePhoneNumber(phoneNumber)),
+		Version:     1,
 		Auditable:   commonentity.NewAuditable(createdBy),
 	}
 }
 
-// MapUpdateFrom mapping from model
+// NewUserFromIdentity creates new User without password and username from the verified email of a linked identity
+func NewUserFromIdentity(id uuid.UUID, email string, createdBy string) *User {
+	user := NewUser(id, "", email, "", "", createdBy)
+	user.EmailVerifiedAt = tools.TimeToNullTime(time.Now())
+
+	return user
+}
+
+// MapUpdateFrom mapping from model.
+// updated_by is taken from the given model, so the caller must set it to the actor of the update.
 func (u *User) MapUpdateFrom(from *User) *map[string]interface{} {
 	if from == nil {
 		return &map[string]interface{}{
 			"username":     u.Username,
 			"password":     u.Password,
 			"phone_number": u.PhoneNumber,
+			"updated_by":   u.UpdatedBy,
 		}
 	}
 
@@ -61,17 +110,115 @@ func (u *User) MapUpdateFrom(from *User) *map[string]interface{} {
 	if u.Username != from.Username {
 		mapped["username"] = from.Username
 	}
-	if u.Password != from.Password {
+	// users read through cache have no password hash, which keeps the stored one
+	if from.Password != "" && u.Password != from.Password {
 		mapped["password"] = from.Password
 	}
 	if u.PhoneNumber != from.PhoneNumber {
 		mapped["phone_number"] = from.PhoneNumber
+		// a new phone number has not received any code yet
+		mapped["phone_number_verified_at"] = nil
+	}
+
+	if from.UpdatedBy.Valid {
+		mapped["updated_by"] = from.UpdatedBy
 	}
 
 	mapped["updated_at"] = time.Now()
 	return &mapped
 }
 
+// IsDisabled checks whether user is disabled by an administrator
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt.Valid
+}
+
+// IsErased checks whether personal data of the deleted user has been erased
+func (u *User) IsErased() bool {
+	return u.ErasedAt.Valid
+}
+
+// IsEmailVerified checks whether user has verified its email
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt.Valid
+}
+
+// IsPhoneNumberVerified checks whether user has proven it owns its phone number
+func (u *User) IsPhoneNumberVerified() bool {
+	return u.PhoneNumberVerifiedAt.Valid
+}
+
+// Status returns status of the user
+func (u *User) Status() string {
+	switch {
+	case u.DeletedAt.Valid:
+		return UserStatusDeleted
+	case u.IsDisabled():
+		return UserStatusDisabled
+	default:
+		return UserStatusActive
+	}
+}
+
+// ApplyProfile copies profile fields listed in paths from the given user.
+// It returns false when one of the paths is not a profile field.
+func (u *User) ApplyProfile(from *User, paths []string) bool {
+	for _, path := range paths {
+		switch path {
+		case ProfileFieldUsername:
+			u.Username = from.Username
+		case ProfileFieldPhoneNumber:
+			u.PhoneNumber = tools.EmptyStringToNullString(NormalizePhoneNumber(from.PhoneNumber.String))
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// NormalizeEmail lower cases and trims email, so the same address is always stored and looked up the same way
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhoneNumber converts phone number into E.164, so the same number is always stored and looked up the same way.
+// Separators are removed, the international prefix 00 is replaced with + and a number in national format,
+// starting with the trunk prefix 0, gets PhoneNumberDefaultCountryCode. Other numbers are assumed to start
+// with their country calling code. A number that is not made of digits is only trimmed.
+func NormalizePhoneNumber(phoneNumber string) string {
+	trimmed := strings.TrimSpace(phoneNumber)
+
+	digits := phoneNumberSeparators.Replace(trimmed)
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		digits = PhoneNumberDefaultCountryCode + digits[1:]
+	}
+
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return trimmed
+	}
+
+	return "+" + digits
+}
+
+// ErasedEmail returns the email replacing the email of an erased user,
+// it stays unique per user and can never receive any email
+func ErasedEmail(id uuid.UUID) string {
+	return fmt.Sprintf("erased-%s@erased.invalid", id)
+}
+
+// UniqueFieldOf returns the field guarded by unique constraint of users table,
+// it returns false when the constraint is not a unique constraint of users table
+func UniqueFieldOf(constraint string) (string, bool) {
+	field, ok := userUniqueConstraints[constraint]
+	return field, ok
+}
+
 // TableName represents table name on db, need to define it because the db has multi schema
 func (u *User) TableName() string {
 	return UserTableName
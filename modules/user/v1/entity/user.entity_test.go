@@ -0,0 +1,31 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		phoneNumber string
+		normalized  string
+	}{
+		{name: "national format gets default country code", phoneNumber: "0895346419497", normalized: "+62895346419497"},
+		{name: "separators are removed", phoneNumber: " 0895-3464 (194) 97 ", normalized: "+62895346419497"},
+		{name: "international prefix becomes plus", phoneNumber: "0062895346419497", normalized: "+62895346419497"},
+		{name: "e164 is kept", phoneNumber: "+62895346419497", normalized: "+62895346419497"},
+		{name: "country code without plus gets plus", phoneNumber: "62895346419497", normalized: "+62895346419497"},
+		{name: "number with letters is only trimmed", phoneNumber: " 0895abc ", normalized: "0895abc"},
+		{name: "empty number stays empty", phoneNumber: " ", normalized: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.normalized, entity.NormalizePhoneNumber(tt.phoneNumber))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"grpc-starter/common/constant"
+)
+
+const (
+	// UserSortByCreatedAt sorts users by creation time
+	UserSortByCreatedAt = "created_at"
+	// UserSortByUsername sorts users by username
+	UserSortByUsername = "username"
+	// UserSortByEmail sorts users by email
+	UserSortByEmail = "email"
+)
+
+// ErrInvalidUserCursor is returned when cursor is malformed or does not belong to the requested sorting
+var ErrInvalidUserCursor = errors.New("invalid user cursor")
+
+// UserFilter defines filter, sorting and pagination of user listing
+type UserFilter struct {
+	// Search is free text searched over username and email
+	Search string
+	// Status is one of UserStatus constants, empty means every user that is not deleted
+	Status string
+	// CreatedFrom is the inclusive lower bound of creation time, zero means unbounded
+	CreatedFrom time.Time
+	// CreatedTo is the exclusive upper bound of creation time, zero means unbounded
+	CreatedTo time.Time
+	// SortBy is one of UserSortBy constants
+	SortBy string
+	// SortOrder is either constant.Ascending or constant.Descending
+	SortOrder string
+	// Limit is the maximum number of users returned
+	Limit int
+	// Cursor is the position after which users are returned, nil means the first page
+	Cursor *UserCursor
+}
+
+// UserCursor is the position of a user in a sorted listing.
+// It is given to clients as an opaque page token.
+type UserCursor struct {
+	SortBy    string    `json:"s"`
+	SortOrder string    `json:"o"`
+	Value     string    `json:"v"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// NewUserFilter creates UserFilter with defaults applied to sorting and limit
+func NewUserFilter(search, status, sortBy, sortOrder string, createdFrom, createdTo time.Time, limit int) *UserFilter {
+	if sortBy == "" {
+		sortBy = UserSortByCreatedAt
+	}
+	if sortOrder == "" {
+		sortOrder = constant.Descending
+	}
+	if limit <= 0 {
+		limit = constant.DefaultLimit
+	}
+	if limit > constant.Hundred {
+		limit = constant.Hundred
+	}
+
+	return &UserFilter{
+		Search:      search,
+		Status:      status,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		SortBy:      sortBy,
+		SortOrder:   sortOrder,
+		Limit:       limit,
+	}
+}
+
+// CursorOf builds the cursor pointing at user in the sorting of the filter
+func (f *UserFilter) CursorOf(user *User) *UserCursor {
+	var value string
+	switch f.SortBy {
+	case UserSortByUsername:
+		value = user.Username.String
+	case UserSortByEmail:
+		value = user.Email
+	default:
+		value = user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return &UserCursor{
+		SortBy:    f.SortBy,
+		SortOrder: f.SortOrder,
+		Value:     value,
+		ID:        user.ID,
+	}
+}
+
+// Encode encodes cursor into an opaque page token
+func (c *UserCursor) Encode() string {
+	raw, _ := json.Marshal(c) // error is impossible, hence ignored.
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeUserCursor decodes page token into cursor and makes sure it belongs to the sorting of the filter
+func DecodeUserCursor(token string, filter *UserFilter) (*UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidUserCursor
+	}
+
+	cursor := &UserCursor{}
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return nil, ErrInvalidUserCursor
+	}
+
+	if cursor.SortBy != filter.SortBy || cursor.SortOrder != filter.SortOrder {
+		return nil, ErrInvalidUserCursor
+	}
+
+	if cursor.SortBy == UserSortByCreatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return nil, ErrInvalidUserCursor
+		}
+	}
+
+	return cursor, nil
+}
@@ -1,13 +1,21 @@
 package builder
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"gorm.io/gorm"
 
 	"grpc-starter/common/config"
+	"grpc-starter/common/hasher"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/common/oidc"
 	commonredis "grpc-starter/common/redis"
 	"grpc-starter/modules/user/v1/internal/grpc/handler"
+	"grpc-starter/modules/user/v1/internal/job"
 	"grpc-starter/modules/user/v1/internal/repository"
 	"grpc-starter/modules/user/v1/service"
 )
@@ -18,21 +26,49 @@ func BuildUserHandler(
 	db *gorm.DB,
 	redisPool *redis.Pool,
 	grpcConn *grpc.ClientConn,
-) *handler.UserHandler {
+	keyManager *commonJwt.KeyManager,
+) (*handler.UserHandler, error) {
 	// Cache
-	cache := commonredis.NewClient(redisPool)
+	cache := commonredis.NewContextClient(redisPool, cfg.Redis.CommandTimeout)
+	revocationStore := commonJwt.NewRevocationStore(cache)
+	tokenIssuer := commonJwt.NewTokenIssuer(cfg, keyManager, commonJwt.SystemClock)
+
+	// Password hasher
+	passwordHasher, err := buildPasswordHasher(cfg.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
 
 	// Repositories
-	userFinderRepo := repository.NewUserFinderRepository(db, cache)
+	userFinderRepo := repository.NewUserFinderRepository(db, cache, cfg.UserCache)
 	userCreatorRepo := repository.NewUserCreatorRepository(db, cache)
 	userUpdaterRepo := repository.NewUserUpdaterRepository(db, cache)
 	userDeleterRepo := repository.NewUserDeleterRepository(db, cache)
+	userPasswordResetRepo := repository.NewUserPasswordResetRepository(cache)
+	userEmailVerificationRepo := repository.NewUserEmailVerificationRepository(cache)
+	userPhoneOTPRepo := repository.NewUserPhoneOTPRepository(cache)
+	userRefreshTokenRepo := repository.NewUserRefreshTokenRepository(db, cache)
+	userRoleRepo := repository.NewUserRoleRepository(db, cache)
+	userMFARepo := repository.NewUserMFARepository(db, cache)
+	userLoginAttemptRepo := repository.NewUserLoginAttemptRepository(cache)
+	userIdentityRepo := repository.NewUserIdentityRepository(db, cache)
+	userSessionRepo := repository.NewUserSessionRepository(db)
+	userExporterRepo := repository.NewUserExporterRepository(db)
 
 	// Services
-	userFinderSvc := service.NewUserFinder(cfg, userFinderRepo)
-	userCreatorSvc := service.NewUserCreator(cfg, userCreatorRepo)
-	userUpdaterSvc := service.NewUserUpdater(cfg, userUpdaterRepo)
-	userDeleterSvc := service.NewUserDeleter(cfg, userDeleterRepo)
+	userTokenSvc := service.NewUserToken(cfg, userRefreshTokenRepo, userSessionRepo, userRoleRepo, tokenIssuer, revocationStore)
+	userMFASvc := service.NewUserMFA(cfg, userFinderRepo, userMFARepo, userTokenSvc)
+	userFinderSvc := service.NewUserFinder(cfg, userFinderRepo, userUpdaterRepo, userTokenSvc, userMFASvc, userLoginAttemptRepo, passwordHasher)
+	userEmailVerificationSvc := service.NewUserEmailVerification(cfg, userFinderRepo, userUpdaterRepo, userEmailVerificationRepo)
+	userPasswordPolicySvc := service.NewUserPasswordPolicy(cfg)
+	userCreatorSvc := service.NewUserCreator(cfg, userCreatorRepo, userTokenSvc, userEmailVerificationSvc, userPasswordPolicySvc, passwordHasher)
+	userUpdaterSvc := service.NewUserUpdater(cfg, userUpdaterRepo, userFinderRepo, userPasswordResetRepo, userTokenSvc, userPasswordPolicySvc, passwordHasher)
+	userDeleterSvc := service.NewUserDeleter(cfg, userDeleterRepo, userTokenSvc)
+	userPhoneOTPSvc := service.NewUserPhoneOTP(cfg, userFinderRepo, userUpdaterRepo, userPhoneOTPRepo, userTokenSvc, userMFASvc)
+	userRoleSvc := service.NewUserRole(cfg, userRoleRepo, userFinderRepo, revocationStore)
+	userExporterSvc := service.NewUserExporter(cfg, userFinderRepo, userExporterRepo)
+	userSessionSvc := service.NewUserSession(cfg, userSessionRepo, userRefreshTokenRepo, revocationStore)
+	userIdentitySvc := service.NewUserIdentity(cfg, userFinderRepo, userIdentityRepo, buildIDTokenVerifier(cfg.OIDC), userTokenSvc, userMFASvc)
 
 	return handler.NewUserHandler(
 		cfg,
@@ -40,5 +76,64 @@ func BuildUserHandler(
 		userCreatorSvc,
 		userUpdaterSvc,
 		userDeleterSvc,
+		userTokenSvc,
+		userRoleSvc,
+		userEmailVerificationSvc,
+		userPhoneOTPSvc,
+		userMFASvc,
+		userIdentitySvc,
+		userSessionSvc,
+		userExporterSvc,
+	), nil
+}
+
+// BuildErasureJob builds job erasing personal data of deleted users
+func BuildErasureJob(cfg config.Config, db *gorm.DB, redisPool *redis.Pool) *job.ErasureJob {
+	cache := commonredis.NewContextClient(redisPool, cfg.Redis.CommandTimeout)
+	userEraserRepo := repository.NewUserEraserRepository(db, cache)
+	userEraserSvc := service.NewUserEraser(cfg, userEraserRepo)
+
+	return job.NewErasureJob(cfg.AccountErasure, userEraserSvc)
+}
+
+// buildPasswordHasher builds password hasher preferring the configured algorithm,
+// hashes of the other algorithms are still verified so they can be upgraded on login
+func buildPasswordHasher(cfg config.PasswordHash) (hasher.PasswordHasher, error) {
+	passwordHasher, err := hasher.NewChain(
+		string(cfg.Algorithm),
+		hasher.NewBcrypt(cfg.BcryptCost),
+		hasher.NewArgon2id(hasher.Argon2idParams{
+			Memory:      cfg.Argon2Memory,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+			SaltLength:  cfg.Argon2SaltLength,
+			KeyLength:   cfg.Argon2KeyLength,
+		}),
 	)
+	if err != nil {
+		return nil, errors.Wrap(err, "[buildPasswordHasher] error building password hasher")
+	}
+
+	return passwordHasher, nil
+}
+
+// buildIDTokenVerifier builds id token verifier for google and the generic provider,
+// a provider without client ids or issuer configured is left out
+func buildIDTokenVerifier(cfg config.OIDC) *oidc.Verifier {
+	var providers []oidc.Provider
+
+	if len(cfg.GoogleClientIDs) > 0 {
+		providers = append(providers, oidc.NewGoogleProvider(cfg.GoogleClientIDs))
+	}
+
+	if cfg.ProviderIssuer != "" {
+		providers = append(providers, oidc.Provider{
+			Name:      cfg.ProviderName,
+			Issuers:   []string{cfg.ProviderIssuer},
+			ClientIDs: cfg.ProviderClientIDs,
+			JWKSURL:   cfg.ProviderJWKSURL,
+		})
+	}
+
+	return oidc.NewVerifier(providers, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.KeysCacheTTL, cfg.ClockSkew, time.Now)
 }
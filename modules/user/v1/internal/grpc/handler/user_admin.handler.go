@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// ListUsers handles the request to list users with filtering, sorting and cursor pagination.
+func (ah *UserHandler) ListUsers(ctx context.Context, request *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	var createdFrom, createdTo time.Time
+	if request.GetCreatedFrom() != nil {
+		createdFrom = request.GetCreatedFrom().AsTime()
+	}
+	if request.GetCreatedTo() != nil {
+		createdTo = request.GetCreatedTo().AsTime()
+	}
+
+	filter := entity.NewUserFilter(
+		request.GetSearch(),
+		fromUserStatus(request.GetStatus()),
+		request.GetSortBy(),
+		request.GetSortOrder(),
+		createdFrom,
+		createdTo,
+		int(request.GetPageSize()),
+	)
+
+	users, nextPageToken, err := ah.userFinderSvc.FindAll(ctx, filter, request.GetPageToken())
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	data := make([]*userv1.UserData, 0, len(users))
+	for _, user := range users {
+		data = append(data, toUserData(user))
+	}
+
+	return &userv1.ListUsersResponse{
+		Code:          http.StatusOK,
+		Message:       constant.SuccessMessage,
+		Data:          data,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// GetUser handles the request to get any user, including soft deleted one.
+func (ah *UserHandler) GetUser(ctx context.Context, request *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	userID, err := uuid.Parse(request.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "user id tidak valid")
+	}
+
+	user, err := ah.userFinderSvc.FindByIDWithDeleted(ctx, userID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.GetUserResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserData(user),
+	}, nil
+}
+
+// DisableUser handles the request to disable a user.
+func (ah *UserHandler) DisableUser(ctx context.Context, request *userv1.DisableUserRequest) (*userv1.DisableUserResponse, error) {
+	userID, err := uuid.Parse(request.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "user id tidak valid")
+	}
+
+	user, err := ah.userUpdaterSvc.Disable(ctx, userID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.DisableUserResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserData(user),
+	}, nil
+}
+
+// RestoreUser handles the request to enable a disabled user or restore a soft deleted user.
+func (ah *UserHandler) RestoreUser(ctx context.Context, request *userv1.RestoreUserRequest) (*userv1.RestoreUserResponse, error) {
+	userID, err := uuid.Parse(request.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "user id tidak valid")
+	}
+
+	user, err := ah.userUpdaterSvc.Restore(ctx, userID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.RestoreUserResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserData(user),
+	}, nil
+}
+
+// fromUserStatus maps gRPC user status into user status, unspecified status means no filter
+func fromUserStatus(status userv1.UserStatus) string {
+	switch status {
+	case userv1.UserStatus_USER_STATUS_ACTIVE:
+		return entity.UserStatusActive
+	case userv1.UserStatus_USER_STATUS_DISABLED:
+		return entity.UserStatusDisabled
+	case userv1.UserStatus_USER_STATUS_DELETED:
+		return entity.UserStatusDeleted
+	default:
+		return ""
+	}
+}
@@ -4,24 +4,37 @@ package handler
 import (
 	"context"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	userv1 "grpc-starter/api/user/v1"
 	"grpc-starter/common/config"
 	"grpc-starter/common/constant"
 	"grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/modules/user/v1/entity"
 	"grpc-starter/modules/user/v1/service"
 )
 
 // UserHandler is a gRPC handler for the user auth service.
 type UserHandler struct {
 	userv1.UnimplementedUserServiceServer
-	config         config.Config
-	userFinderSvc  service.UserFinderUseCase
-	userCreatorSvc service.UserCreatorUseCase
-	userUpdaterSvc service.UserUpdaterUseCase
-	userDeleterSvc service.UserDeleterUseCase
+	config                   config.Config
+	userFinderSvc            service.UserFinderUseCase
+	userCreatorSvc           service.UserCreatorUseCase
+	userUpdaterSvc           service.UserUpdaterUseCase
+	userDeleterSvc           service.UserDeleterUseCase
+	userTokenSvc             service.UserTokenUseCase
+	userRoleSvc              service.UserRoleUseCase
+	userEmailVerificationSvc service.UserEmailVerificationUseCase
+	userPhoneOTPSvc          service.UserPhoneOTPUseCase
+	userMFASvc               service.UserMFAUseCase
+	userIdentitySvc          service.UserIdentityUseCase
+	userSessionSvc           service.UserSessionUseCase
+	userExporterSvc          service.UserExporterUseCase
 }
 
 // NewUserHandler returns a new UserHandler.
@@ -31,19 +44,35 @@ func NewUserHandler(
 	userCreatorSvc service.UserCreatorUseCase,
 	userUpdaterSvc service.UserUpdaterUseCase,
 	userDeleterSvc service.UserDeleterUseCase,
+	userTokenSvc service.UserTokenUseCase,
+	userRoleSvc service.UserRoleUseCase,
+	userEmailVerificationSvc service.UserEmailVerificationUseCase,
+	userPhoneOTPSvc service.UserPhoneOTPUseCase,
+	userMFASvc service.UserMFAUseCase,
+	userIdentitySvc service.UserIdentityUseCase,
+	userSessionSvc service.UserSessionUseCase,
+	userExporterSvc service.UserExporterUseCase,
 ) *UserHandler {
 	return &UserHandler{
-		config:         config,
-		userFinderSvc:  userFinderSvc,
-		userCreatorSvc: userCreatorSvc,
-		userUpdaterSvc: userUpdaterSvc,
-		userDeleterSvc: userDeleterSvc,
+		config:                   config,
+		userFinderSvc:            userFinderSvc,
+		userCreatorSvc:           userCreatorSvc,
+		userUpdaterSvc:           userUpdaterSvc,
+		userDeleterSvc:           userDeleterSvc,
+		userTokenSvc:             userTokenSvc,
+		userRoleSvc:              userRoleSvc,
+		userEmailVerificationSvc: userEmailVerificationSvc,
+		userPhoneOTPSvc:          userPhoneOTPSvc,
+		userMFASvc:               userMFASvc,
+		userIdentitySvc:          userIdentitySvc,
+		userSessionSvc:           userSessionSvc,
+		userExporterSvc:          userExporterSvc,
 	}
 }
 
 // Login define gRPC handler login for user modules
 func (ah *UserHandler) Login(ctx context.Context, request *userv1.LoginRequest) (*userv1.LoginResponse, error) {
-	user, token, err := ah.userFinderSvc.Login(ctx, request.Email, request.Password)
+	user, token, challenge, err := ah.userFinderSvc.Login(ctx, request.Email, request.Password)
 
 	if err != nil {
 		parseError := errors.ParseError(err)
@@ -53,12 +82,157 @@ func (ah *UserHandler) Login(ctx context.Context, request *userv1.LoginRequest)
 		)
 	}
 
+	if challenge != nil {
+		return &userv1.LoginResponse{
+			Code:    http.StatusOK,
+			Message: constant.SuccessMessage,
+			Mfa:     toMFAChallengeData(challenge),
+		}, nil
+	}
+
 	return &userv1.LoginResponse{
 		Code:    http.StatusOK,
 		Message: constant.SuccessMessage,
-		Data: &userv1.TokenData{
-			UserId: user.ID.String(),
-			Token:  token,
-		},
+		Data:    toTokenData(user.ID, token),
+	}, nil
+}
+
+// RequestPhoneOTP define gRPC handler requesting login code sent to phone number for user modules
+func (ah *UserHandler) RequestPhoneOTP(ctx context.Context, request *userv1.RequestPhoneOTPRequest) (*userv1.RequestPhoneOTPResponse, error) {
+	if err := ah.userPhoneOTPSvc.Request(ctx, request.GetPhoneNumber()); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.RequestPhoneOTPResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.RequestPhoneOTPMessage,
+	}, nil
+}
+
+// LoginWithPhoneOTP define gRPC handler login with code sent to phone number for user modules
+func (ah *UserHandler) LoginWithPhoneOTP(ctx context.Context, request *userv1.LoginWithPhoneOTPRequest) (*userv1.LoginWithPhoneOTPResponse, error) {
+	user, token, challenge, err := ah.userPhoneOTPSvc.Login(ctx, request.GetPhoneNumber(), request.GetCode())
+
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	if challenge != nil {
+		return &userv1.LoginWithPhoneOTPResponse{
+			Code:    http.StatusOK,
+			Message: constant.SuccessMessage,
+			Mfa:     toMFAChallengeData(challenge),
+		}, nil
+	}
+
+	return &userv1.LoginWithPhoneOTPResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toTokenData(user.ID, token),
+	}, nil
+}
+
+// LoginWithIDToken define gRPC handler login with id token of an OpenID Connect provider for user modules
+func (ah *UserHandler) LoginWithIDToken(ctx context.Context, request *userv1.LoginWithIDTokenRequest) (*userv1.LoginWithIDTokenResponse, error) {
+	user, token, challenge, err := ah.userIdentitySvc.LoginWithIDToken(ctx, request.GetIdToken())
+
+	if err != nil {
+		return nil, errors.ToGRPCError(err)
+	}
+
+	if challenge != nil {
+		return &userv1.LoginWithIDTokenResponse{
+			Code:    http.StatusOK,
+			Message: constant.SuccessMessage,
+			Mfa:     toMFAChallengeData(challenge),
+		}, nil
+	}
+
+	return &userv1.LoginWithIDTokenResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toTokenData(user.ID, token),
+	}, nil
+}
+
+// RefreshToken define gRPC handler refresh token for user modules
+func (ah *UserHandler) RefreshToken(ctx context.Context, request *userv1.RefreshTokenRequest) (*userv1.RefreshTokenResponse, error) {
+	userID, token, err := ah.userTokenSvc.Refresh(ctx, request.GetRefreshToken())
+
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.RefreshTokenResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toTokenData(userID, token),
+	}, nil
+}
+
+// toTokenData maps token pair into gRPC token data
+func toTokenData(userID uuid.UUID, token *entity.TokenPair) *userv1.TokenData {
+	return &userv1.TokenData{
+		UserId:       userID.String(),
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    int64(time.Until(token.AccessTokenExpiresAt).Seconds()),
+	}
+}
+
+// Logout define gRPC handler logout for user modules
+func (ah *UserHandler) Logout(ctx context.Context, request *userv1.LogoutRequest) (*userv1.LogoutResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	if err := ah.userTokenSvc.Logout(ctx, principal, request.GetRefreshToken()); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.LogoutResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.LogoutMessage,
+	}, nil
+}
+
+// LogoutAll define gRPC handler logout from all devices for user modules
+func (ah *UserHandler) LogoutAll(ctx context.Context, _ *userv1.LogoutAllRequest) (*userv1.LogoutAllResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	if err := ah.userTokenSvc.LogoutAll(ctx, principal.UserID); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.LogoutAllResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.LogoutAllMessage,
 	}, nil
 }
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// EnrollMFA handles the request to generate a new two-factor authentication secret for the authenticated user.
+func (ah *UserHandler) EnrollMFA(ctx context.Context, _ *userv1.EnrollMFARequest) (*userv1.EnrollMFAResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	enrollment, err := ah.userMFASvc.Enroll(ctx, principal.UserID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.EnrollMFAResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data: &userv1.MFAEnrollmentData{
+			Secret:     enrollment.Secret,
+			OtpauthUri: enrollment.URI,
+		},
+	}, nil
+}
+
+// ConfirmMFA handles the request to enable two-factor authentication of the authenticated user.
+func (ah *UserHandler) ConfirmMFA(ctx context.Context, request *userv1.ConfirmMFARequest) (*userv1.ConfirmMFAResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	recoveryCodes, err := ah.userMFASvc.Confirm(ctx, principal.UserID, request.GetCode())
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.ConfirmMFAResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data: &userv1.MFARecoveryCodesData{
+			RecoveryCodes: recoveryCodes,
+		},
+	}, nil
+}
+
+// VerifyMFA handles the request to exchange mfa challenge of login for token pair.
+func (ah *UserHandler) VerifyMFA(ctx context.Context, request *userv1.VerifyMFARequest) (*userv1.VerifyMFAResponse, error) {
+	user, token, err := ah.userMFASvc.Verify(ctx, request.GetMfaToken(), request.GetCode())
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.VerifyMFAResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toTokenData(user.ID, token),
+	}, nil
+}
+
+// toMFAChallengeData maps mfa challenge into gRPC mfa challenge data
+func toMFAChallengeData(challenge *entity.MFAChallenge) *userv1.MFAChallengeData {
+	return &userv1.MFAChallengeData{
+		MfaToken:  challenge.Token,
+		ExpiresIn: int64(time.Until(challenge.ExpiresAt).Seconds()),
+	}
+}
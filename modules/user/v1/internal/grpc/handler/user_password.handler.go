@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/status"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+)
+
+// ForgotPassword handles the request to send password reset link to user email.
+func (ah *UserHandler) ForgotPassword(ctx context.Context, request *userv1.ForgotPasswordRequest) (*userv1.ForgotPasswordResponse, error) {
+	if err := ah.userUpdaterSvc.ForgotPassword(ctx, request.GetEmail()); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.ForgotPasswordResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.ForgotPasswordMessage,
+	}, nil
+}
+
+// ChangePassword handles the request to change user password using password reset token.
+func (ah *UserHandler) ChangePassword(ctx context.Context, request *userv1.ChangePasswordRequest) (*userv1.ChangePasswordResponse, error) {
+	if err := ah.userUpdaterSvc.ChangePassword(ctx, request.GetToken(), request.GetPassword()); err != nil {
+		return nil, errors.ToGRPCError(err)
+	}
+
+	return &userv1.ChangePasswordResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.ChangePasswordMessage,
+	}, nil
+}
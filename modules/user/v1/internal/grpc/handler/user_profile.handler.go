@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// GetMe handles the request to get profile of the authenticated user.
+func (ah *UserHandler) GetMe(ctx context.Context, _ *userv1.GetMeRequest) (*userv1.GetMeResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	user, err := ah.userFinderSvc.FindByID(ctx, principal.UserID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.GetMeResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserData(user),
+	}, nil
+}
+
+// UpdateProfile handles the request to update profile of the authenticated user.
+// Only fields listed in update mask are updated, or every non-empty field when the mask is empty.
+func (ah *UserHandler) UpdateProfile(ctx context.Context, request *userv1.UpdateProfileRequest) (*userv1.UpdateProfileResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	profile := request.GetProfile()
+	paths := request.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = populatedProfilePaths(profile)
+	}
+
+	user, err := ah.userUpdaterSvc.UpdateProfile(ctx, principal.UserID, &entity.User{
+		Username:    tools.EmptyStringToNullString(profile.GetUsername()),
+		PhoneNumber: tools.EmptyStringToNullString(profile.GetPhoneNumber()),
+	}, paths, request.GetVersion())
+	if err != nil {
+		return nil, errors.ToGRPCError(err)
+	}
+
+	return &userv1.UpdateProfileResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserData(user),
+	}, nil
+}
+
+// DeleteAccount handles the request to delete account of the authenticated user.
+func (ah *UserHandler) DeleteAccount(ctx context.Context, _ *userv1.DeleteAccountRequest) (*userv1.DeleteAccountResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	if err := ah.userDeleterSvc.DeleteAccount(ctx, principal.UserID); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.DeleteAccountResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.DeleteAccountMessage,
+	}, nil
+}
+
+// ExportMyData handles the request to export personal data of the authenticated user as a JSON archive.
+func (ah *UserHandler) ExportMyData(ctx context.Context, _ *userv1.ExportMyDataRequest) (*userv1.ExportMyDataResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	export, err := ah.userExporterSvc.Export(ctx, principal.UserID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	data, err := toStruct(export)
+	if err != nil {
+		log.Println("[UserHandler - ExportMyData] Error while encoding data export :", err)
+		return nil, status.Error(codes.Internal, errors.ErrInternalServerError.Message)
+	}
+
+	return &userv1.ExportMyDataResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    data,
+	}, nil
+}
+
+// toStruct converts value into protobuf struct through its JSON encoding
+func toStruct(value interface{}) (*structpb.Struct, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &structpb.Struct{}
+	if err := protojson.Unmarshal(encoded, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// populatedProfilePaths lists non-empty fields of profile as update mask paths
+func populatedProfilePaths(profile *userv1.Profile) []string {
+	var paths []string
+	if profile.GetUsername() != "" {
+		paths = append(paths, entity.ProfileFieldUsername)
+	}
+	if profile.GetPhoneNumber() != "" {
+		paths = append(paths, entity.ProfileFieldPhoneNumber)
+	}
+
+	return paths
+}
+
+// toUserData maps user into gRPC user data
+func toUserData(user *entity.User) *userv1.UserData {
+	data := &userv1.UserData{
+		Id:          user.ID.String(),
+		Username:    user.Username.String,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber.String,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		Status:      toUserStatus(user.Status()),
+		Version:     user.Version,
+	}
+	if user.IsEmailVerified() {
+		data.EmailVerifiedAt = timestamppb.New(user.EmailVerifiedAt.Time)
+	}
+	if user.IsPhoneNumberVerified() {
+		data.PhoneNumberVerifiedAt = timestamppb.New(user.PhoneNumberVerifiedAt.Time)
+	}
+
+	return data
+}
+
+// toUserStatus maps user status into gRPC user status
+func toUserStatus(status string) userv1.UserStatus {
+	switch status {
+	case entity.UserStatusActive:
+		return userv1.UserStatus_USER_STATUS_ACTIVE
+	case entity.UserStatusDisabled:
+		return userv1.UserStatus_USER_STATUS_DISABLED
+	case entity.UserStatusDeleted:
+		return userv1.UserStatus_USER_STATUS_DELETED
+	default:
+		return userv1.UserStatus_USER_STATUS_UNSPECIFIED
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+)
+
+// AssignRole handles the request to assign role to a user.
+func (ah *UserHandler) AssignRole(ctx context.Context, request *userv1.AssignRoleRequest) (*userv1.AssignRoleResponse, error) {
+	userID, err := uuid.Parse(request.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "user id tidak valid")
+	}
+
+	roles, err := ah.userRoleSvc.AssignRole(ctx, userID, request.GetRole())
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.AssignRoleResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserRolesData(userID, roles),
+	}, nil
+}
+
+// RevokeRole handles the request to revoke role from a user.
+func (ah *UserHandler) RevokeRole(ctx context.Context, request *userv1.RevokeRoleRequest) (*userv1.RevokeRoleResponse, error) {
+	userID, err := uuid.Parse(request.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "user id tidak valid")
+	}
+
+	roles, err := ah.userRoleSvc.RevokeRole(ctx, userID, request.GetRole())
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.RevokeRoleResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    toUserRolesData(userID, roles),
+	}, nil
+}
+
+// toUserRolesData maps roles of a user into gRPC user roles data
+func toUserRolesData(userID uuid.UUID, roles []string) *userv1.UserRolesData {
+	return &userv1.UserRolesData{
+		UserId: userID.String(),
+		Roles:  roles,
+	}
+}
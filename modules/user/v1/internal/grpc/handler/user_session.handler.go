@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// ListSessions handles the request to list sessions of the authenticated user.
+func (ah *UserHandler) ListSessions(ctx context.Context, _ *userv1.ListSessionsRequest) (*userv1.ListSessionsResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	sessions, err := ah.userSessionSvc.ListSessions(ctx, principal.UserID)
+	if err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	data := make([]*userv1.SessionData, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, toSessionData(session, principal.SessionID))
+	}
+
+	return &userv1.ListSessionsResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    data,
+	}, nil
+}
+
+// RevokeSession handles the request to revoke a session of the authenticated user.
+func (ah *UserHandler) RevokeSession(ctx context.Context, request *userv1.RevokeSessionRequest) (*userv1.RevokeSessionResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	sessionID, err := uuid.Parse(request.GetSessionId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "session id tidak valid")
+	}
+
+	if err := ah.userSessionSvc.RevokeSession(ctx, principal.UserID, sessionID); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.RevokeSessionResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.RevokeSessionMessage,
+	}, nil
+}
+
+// toSessionData maps session into gRPC session data, marking the session the request is made from
+func toSessionData(session *entity.Session, currentSessionID uuid.UUID) *userv1.SessionData {
+	return &userv1.SessionData{
+		Id:         session.ID.String(),
+		DeviceName: session.DeviceName.String,
+		UserAgent:  session.UserAgent.String,
+		IpAddress:  session.IPAddress.String,
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		LastSeenAt: timestamppb.New(session.LastSeenAt),
+		Current:    session.ID == currentSessionID,
+	}
+}
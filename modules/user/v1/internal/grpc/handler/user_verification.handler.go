@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userv1 "grpc-starter/api/user/v1"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+)
+
+// VerifyEmail handles the request to verify email of the authenticated user with the code sent to the email.
+func (ah *UserHandler) VerifyEmail(ctx context.Context, request *userv1.VerifyEmailRequest) (*userv1.VerifyEmailResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	if err := ah.userEmailVerificationSvc.Verify(ctx, principal.UserID, request.GetCode()); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.VerifyEmailResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.VerifyEmailMessage,
+	}, nil
+}
+
+// ResendVerification handles the request to send a new email verification code to the authenticated user.
+func (ah *UserHandler) ResendVerification(ctx context.Context, _ *userv1.ResendVerificationRequest) (*userv1.ResendVerificationResponse, error) {
+	principal, ok := commonJwt.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Sesi anda telah berakhir. Silahkan login kembali")
+	}
+
+	if err := ah.userEmailVerificationSvc.Resend(ctx, principal.UserID); err != nil {
+		parseError := errors.ParseError(err)
+		return nil, status.Errorf(
+			parseError.Code,
+			parseError.Message,
+		)
+	}
+
+	return &userv1.ResendVerificationResponse{
+		Code:    http.StatusOK,
+		Message: constant.SuccessMessage,
+		Data:    constant.ResendVerificationMessage,
+	}, nil
+}
@@ -0,0 +1,67 @@
+// Package job runs background jobs of user modules
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"grpc-starter/common/config"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/service"
+)
+
+// ErasureJob periodically erases personal data of users deleted longer than the retention period
+type ErasureJob struct {
+	cfg           config.AccountErasure
+	userEraserSvc service.UserEraserUseCase
+}
+
+// NewErasureJob creates an instance of ErasureJob
+func NewErasureJob(cfg config.AccountErasure, userEraserSvc service.UserEraserUseCase) *ErasureJob {
+	return &ErasureJob{
+		cfg:           cfg,
+		userEraserSvc: userEraserSvc,
+	}
+}
+
+// Run erases deleted users right away and then every interval, until ctx is done
+func (j *ErasureJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		j.erase(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// erase erases batches of deleted users until a batch is not full, which means none is left
+func (j *ErasureJob) erase(ctx context.Context) {
+	jobID := uuid.New().String()
+	ctx = context.WithValue(ctx, tools.ContextKeyJobID, jobID)
+
+	total := 0
+	for ctx.Err() == nil {
+		erased, err := j.userEraserSvc.EraseDeleted(ctx)
+		total += erased
+		if err != nil {
+			log.Println("[ErasureJob - erase] Error while erasing deleted users :", jobID, err)
+			break
+		}
+		if erased < j.cfg.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Println("[ErasureJob - erase] Erased deleted users :", jobID, total)
+	}
+}
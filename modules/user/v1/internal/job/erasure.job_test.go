@@ -0,0 +1,72 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/config"
+	"grpc-starter/modules/user/v1/internal/job"
+)
+
+// fakeUserEraser answers every call with the next of the batches, cancelling the job once they run out
+type fakeUserEraser struct {
+	batches []int
+	err     error
+	calls   int
+	cancel  context.CancelFunc
+}
+
+func (e *fakeUserEraser) EraseDeleted(ctx context.Context) (int, error) {
+	e.calls++
+	if e.calls >= len(e.batches) {
+		e.cancel()
+	}
+	if e.calls > len(e.batches) {
+		return 0, e.err
+	}
+
+	return e.batches[e.calls-1], e.err
+}
+
+func TestErasureJob_Run(t *testing.T) {
+	cfg := config.AccountErasure{Interval: time.Hour, BatchSize: 10}
+
+	t.Run("batches are erased until one is not full", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		eraser := &fakeUserEraser{batches: []int{10, 10, 3}, cancel: cancel}
+
+		job.NewErasureJob(cfg, eraser).Run(ctx)
+		assert.Equal(t, 3, eraser.calls)
+	})
+
+	t.Run("failed batch stops the run until the next interval", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		eraser := &fakeUserEraser{batches: []int{10}, err: errors.New("connection refused"), cancel: cancel}
+
+		job.NewErasureJob(cfg, eraser).Run(ctx)
+		assert.Equal(t, 1, eraser.calls)
+	})
+
+	t.Run("job stops once ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		eraser := &fakeUserEraser{cancel: cancel}
+
+		done := make(chan struct{})
+		go func() {
+			job.NewErasureJob(cfg, eraser).Run(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("job did not stop once ctx was done")
+		}
+		assert.Equal(t, 0, eraser.calls)
+	})
+}
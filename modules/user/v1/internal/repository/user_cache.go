@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/common/config"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+const (
+	// userCacheLookupID is the lookup label of finding user by id
+	userCacheLookupID = "id"
+	// userCacheLookupEmail is the lookup label of finding user by email
+	userCacheLookupEmail = "email"
+
+	// userCacheResultHit is the result label of a lookup served from cache
+	userCacheResultHit = "hit"
+	// userCacheResultNegativeHit is the result label of a lookup served from a cached miss
+	userCacheResultNegativeHit = "negative_hit"
+	// userCacheResultMiss is the result label of a lookup that reached the database
+	userCacheResultMiss = "miss"
+
+	// userCacheTombstoneTTL is how long a written user is not cached again,
+	// so a lookup that read the user before the write can not cache the overwritten user
+	userCacheTombstoneTTL = 10 * time.Second
+)
+
+var (
+	// userIDKeys are the cache keys of users, identified by their id
+	userIDKeys = cache.NewKeys("user", "id")
+	// userEmailKeys are the cache keys of ids of users, identified by their normalised email
+	userEmailKeys = cache.NewKeys("user", "email")
+)
+
+// userCacheLookups counts user lookups by the way they were served
+var userCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "user_cache_lookups_total",
+	Help: "Number of user lookups by lookup and result, either hit, negative_hit or miss.",
+}, []string{"lookup", "result"})
+
+// userCache reads and writes users through cache.
+// A nil user or id is cached when a lookup finds no user, for the negative ttl.
+// Users are cached without their password hash, see userCodec.
+type userCache struct {
+	enabled     bool
+	users       *cache.Typed[*entity.User]
+	ids         *cache.Typed[*uuid.UUID]
+	negativeTTL time.Duration
+}
+
+// newUserCache creates user cache, a nil cache or a zero ttl disables it
+func newUserCache(c cache.ContextCacheable, cfg config.UserCache) *userCache {
+	return &userCache{
+		enabled:     c != nil && cfg.TTL > 0,
+		users:       cache.NewTyped[*entity.User](c, userIDKeys, userCodec{}, cfg.TTL, cfg.TTLJitter, cfg.LoadTimeout),
+		ids:         cache.NewTyped[*uuid.UUID](c, userEmailKeys, cache.JSONCodec[*uuid.UUID]{}, cfg.TTL, cfg.TTLJitter, cfg.LoadTimeout),
+		negativeTTL: cfg.NegativeTTL,
+	}
+}
+
+// userCodec encodes users as JSON without their password hash, so credentials are never stored in cache
+// and users read through cache have no password hash, see UserFinderRepository.FindCredentialsByEmail.
+type userCodec struct {
+	cache.JSONCodec[*entity.User]
+}
+
+// Marshal encodes user as JSON without its password hash
+func (c userCodec) Marshal(user *entity.User) ([]byte, error) {
+	if user != nil {
+		withoutPassword := *user
+		withoutPassword.Password = ""
+		user = &withoutPassword
+	}
+
+	return c.JSONCodec.Marshal(user)
+}
+
+// findByID reads user cached by its id.
+// ok is false on a miss, the user is nil when a miss of the database was cached.
+func (c *userCache) findByID(ctx context.Context, id uuid.UUID) (*entity.User, bool) {
+	if !c.enabled {
+		return nil, false
+	}
+
+	user, ok, err := c.users.Get(ctx, id.String())
+	if err != nil {
+		log.Println("[userCache - findByID] Error while reading cached user :", err)
+	}
+	countUserCacheLookup(userCacheLookupID, ok, user != nil)
+
+	return user, ok
+}
+
+// loadByID loads user by its id and caches it, concurrent loads of the same user share one query.
+// Every caller gets its own copy of the user, decoded like a cached user, so callers are free to modify it.
+// A user that is not found is cached as missing.
+func (c *userCache) loadByID(ctx context.Context, id uuid.UUID, load func(ctx context.Context) (*entity.User, error)) (*entity.User, error) {
+	user, err := c.users.Load(ctx, id.String(), load)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.setMissingID(ctx, id)
+	}
+
+	return user, err
+}
+
+// findIDByEmail reads id of user cached by its email.
+// ok is false on a miss, the id is nil when a miss of the database was cached.
+func (c *userCache) findIDByEmail(ctx context.Context, email string) (*uuid.UUID, bool) {
+	if !c.enabled {
+		return nil, false
+	}
+
+	id, ok, err := c.ids.Get(ctx, entity.NormalizeEmail(email))
+	if err != nil {
+		log.Println("[userCache - findIDByEmail] Error while reading cached user id :", err)
+	}
+	countUserCacheLookup(userCacheLookupEmail, ok, id != nil)
+
+	return id, ok
+}
+
+// setUser caches user by its id, unless the user was written since it was read
+func (c *userCache) setUser(ctx context.Context, user *entity.User) {
+	if err := c.users.SetIfAbsent(ctx, user.ID.String(), user); err != nil {
+		log.Println("[userCache - setUser] Error while caching user :", err)
+	}
+}
+
+// setEmail caches id of user by its email, unless the user was written since it was read
+func (c *userCache) setEmail(ctx context.Context, email string, id uuid.UUID) {
+	if err := c.ids.SetIfAbsent(ctx, entity.NormalizeEmail(email), &id); err != nil {
+		log.Println("[userCache - setEmail] Error while caching user id :", err)
+	}
+}
+
+// setMissingID caches that no user has the id
+func (c *userCache) setMissingID(ctx context.Context, id uuid.UUID) {
+	if err := c.users.SetIfAbsentWithTTL(ctx, id.String(), nil, c.negativeTTL); err != nil {
+		log.Println("[userCache - setMissingID] Error while caching missing user :", err)
+	}
+}
+
+// setMissingEmail caches that no user has the email
+func (c *userCache) setMissingEmail(ctx context.Context, email string) {
+	if err := c.ids.SetIfAbsentWithTTL(ctx, entity.NormalizeEmail(email), nil, c.negativeTTL); err != nil {
+		log.Println("[userCache - setMissingEmail] Error while caching missing user id :", err)
+	}
+}
+
+// invalidateUser replaces user cached by its id and, when given, its id cached by email with tombstones.
+// It must be called after every write to users table, once the write is committed,
+// see cache.Invalidate for how the tombstones keep lookups racing the write from caching a stale user.
+func invalidateUser(ctx context.Context, c cache.ContextCacheable, id uuid.UUID, email string) {
+	if c == nil {
+		return
+	}
+
+	keys := []string{userIDKeys.Key(id.String())}
+	if email != "" {
+		keys = append(keys, userEmailKeys.Key(entity.NormalizeEmail(email)))
+	}
+
+	for _, key := range keys {
+		if err := cache.Invalidate(ctx, c, key, userCacheTombstoneTTL); err != nil {
+			log.Println("[invalidateUser] Error while invalidating cached user :", key, err)
+		}
+	}
+}
+
+// countUserCacheLookup counts lookup by the way it was served
+func countUserCacheLookup(lookup string, ok bool, found bool) {
+	result := userCacheResultMiss
+	switch {
+	case ok && found:
+		result = userCacheResultHit
+	case ok:
+		result = userCacheResultNegativeHit
+	}
+
+	userCacheLookups.WithLabelValues(lookup, result).Inc()
+}
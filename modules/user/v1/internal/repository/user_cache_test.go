@@ -0,0 +1,525 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"grpc-starter/common/config"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+// errFakeCacheMiss is returned by fakeCache for a missing key, like redis does
+var errFakeCacheMiss = errors.New("redigo: nil returned")
+
+// fakeCache is an in memory cache.ContextCacheable, storing values marshalled as JSON like redis.ContextClient.
+// Expiry is not simulated, ttls are only recorded.
+type fakeCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]int
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{
+		values: map[string][]byte{},
+		ttls:   map[string]int{},
+	}
+}
+
+func (c *fakeCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.values[key]
+	if !ok {
+		return nil, errFakeCacheMiss
+	}
+	return value, nil
+}
+
+func (c *fakeCache) GetDel(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.values[key]
+	if !ok {
+		return nil, errFakeCacheMiss
+	}
+	delete(c.values, key)
+	delete(c.ttls, key)
+	return value, nil
+}
+
+func (c *fakeCache) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		values[i] = c.values[key]
+	}
+	return values, nil
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.set(key, value, ttl)
+}
+
+func (c *fakeCache) MSet(ctx context.Context, values map[string]interface{}, ttl int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, value := range values {
+		if err := c.set(key, value, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fakeCache) SetWithExpireAt(ctx context.Context, key string, value interface{}, ttl time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.set(key, value, int(time.Until(ttl).Seconds()))
+}
+
+func (c *fakeCache) SetNX(ctx context.Context, key string, value interface{}, ttl int) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.values[key]; ok {
+		return false, nil
+	}
+	return true, c.set(key, value, ttl)
+}
+
+func (c *fakeCache) Incr(ctx context.Context, key string, ttl int) (int64, error) {
+	return c.incrBy(key, 1, ttl)
+}
+
+func (c *fakeCache) Decr(ctx context.Context, key string, ttl int) (int64, error) {
+	return c.incrBy(key, -1, ttl)
+}
+
+func (c *fakeCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.values[key]
+	return ok, nil
+}
+
+func (c *fakeCache) Remove(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.values, key)
+	delete(c.ttls, key)
+	return nil
+}
+
+func (c *fakeCache) BulkRemove(ctx context.Context, pattern string) error {
+	keys, _ := c.Scan(ctx, pattern)
+	for _, key := range keys {
+		_ = c.Remove(ctx, key)
+	}
+	return nil
+}
+
+func (c *fakeCache) Scan(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := []string{}
+	for key := range c.values {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (c *fakeCache) set(key string, value interface{}, ttl int) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.values[key] = data
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *fakeCache) incrBy(key string, delta int64, ttl int) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value int64
+	if data, ok := c.values[key]; ok {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return 0, err
+		}
+	} else {
+		c.ttls[key] = ttl
+	}
+
+	value += delta
+	data, _ := json.Marshal(value)
+	c.values[key] = data
+	return value, nil
+}
+
+// raw returns the JSON stored under key, empty when key is missing
+func (c *fakeCache) raw(key string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return string(c.values[key])
+}
+
+// fakeUser is a row of users table served by fakeDB
+type fakeUser struct {
+	id       uuid.UUID
+	email    string
+	password string
+}
+
+// fakeDB is a database/sql connector answering the queries gorm makes for users by id or by email
+// and conditional password updates, counting the queries and statements it answers.
+type fakeDB struct {
+	mu         sync.Mutex
+	users      []fakeUser
+	queries    int
+	statements int
+}
+
+// newFakeGorm opens gorm on db
+func newFakeGorm(t *testing.T, db *fakeDB) *gorm.DB {
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(db)}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.Nil(t, err)
+
+	return gormDB
+}
+
+func (db *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{db: db}, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// queryCount returns the number of queries answered so far
+func (db *fakeDB) queryCount() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.queries
+}
+
+// query answers query with the users whose id or email equals the first argument
+func (db *fakeDB) query(query string, args []driver.NamedValue) *fakeRows {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.queries++
+	rows := &fakeRows{columns: []string{"id", "email", "password"}}
+	for _, user := range db.users {
+		if len(args) == 0 {
+			continue
+		}
+
+		arg, _ := args[0].Value.(string)
+		if (strings.Contains(query, "id = $1") && arg == user.id.String()) ||
+			(strings.Contains(query, "email = $1") && arg == user.email) {
+			rows.values = append(rows.values, []driver.Value{user.id.String(), user.email, user.password})
+		}
+	}
+
+	return rows
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("fakeDriver is only opened through fakeDB")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakeConn does not prepare statements")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(query, args), nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.statements++
+	if !strings.Contains(query, "password = $3") {
+		return driver.RowsAffected(1), nil
+	}
+
+	// conditional password update, the new hash is the first argument, followed by id and the expected hash
+	password, _ := args[0].Value.(string)
+	id, _ := args[1].Value.(string)
+	expected, _ := args[2].Value.(string)
+	for i, user := range c.db.users {
+		if user.id.String() == id && user.password == expected {
+			c.db.users[i].password = password
+			return driver.RowsAffected(1), nil
+		}
+	}
+
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+var testUserCacheConfig = config.UserCache{
+	TTL:         5 * time.Minute,
+	NegativeTTL: 30 * time.Second,
+	LoadTimeout: time.Second,
+}
+
+func TestUserFinderRepository_FindByID(t *testing.T) {
+	t.Run("user is cached without its password hash", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com", password: "$2a$10$hash"}
+		db := &fakeDB{users: []fakeUser{user}}
+		c := newFakeCache()
+		repo := repository.NewUserFinderRepository(newFakeGorm(t, db), c, testUserCacheConfig)
+
+		for i := 0; i < 2; i++ {
+			found, err := repo.FindByID(context.Background(), user.id)
+			assert.Nil(t, err)
+			assert.Equal(t, user.email, found.Email)
+			assert.Empty(t, found.Password)
+		}
+
+		assert.Equal(t, 1, db.queryCount())
+
+		var cached []byte
+		assert.Nil(t, json.Unmarshal([]byte(c.raw("user:id:"+user.id.String())), &cached))
+		assert.Contains(t, string(cached), user.email)
+		assert.NotContains(t, string(cached), user.password)
+
+		credentials, err := repo.FindCredentialsByEmail(context.Background(), user.email)
+		assert.Nil(t, err)
+		assert.Equal(t, user.password, credentials.Password)
+		assert.Equal(t, 2, db.queryCount())
+	})
+
+	t.Run("missing user is cached as missing for the negative ttl", func(t *testing.T) {
+		db := &fakeDB{}
+		c := newFakeCache()
+		repo := repository.NewUserFinderRepository(newFakeGorm(t, db), c, testUserCacheConfig)
+		id := uuid.New()
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.FindByID(context.Background(), id)
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}
+
+		assert.Equal(t, 1, db.queryCount())
+		assert.InDelta(t, testUserCacheConfig.NegativeTTL.Seconds(), c.ttls["user:id:"+id.String()], 1)
+	})
+}
+
+func TestUserFinderRepository_FindByEmail(t *testing.T) {
+	t.Run("id cached by email is used to find user", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com"}
+		db := &fakeDB{users: []fakeUser{user}}
+		repo := repository.NewUserFinderRepository(newFakeGorm(t, db), newFakeCache(), testUserCacheConfig)
+
+		for i := 0; i < 2; i++ {
+			found, err := repo.FindByEmail(context.Background(), " User@Example.com ")
+			assert.Nil(t, err)
+			assert.Equal(t, user.id, found.ID)
+		}
+
+		assert.Equal(t, 1, db.queryCount())
+	})
+
+	t.Run("stale id cached by email falls back to database", func(t *testing.T) {
+		previous := fakeUser{id: uuid.New(), email: "previous@example.com"}
+		current := fakeUser{id: uuid.New(), email: "user@example.com"}
+		db := &fakeDB{users: []fakeUser{previous, current}}
+		c := newFakeCache()
+		repo := repository.NewUserFinderRepository(newFakeGorm(t, db), c, testUserCacheConfig)
+
+		// the email was cached before previous user changed it and current user took it
+		stale, _ := json.Marshal(previous.id)
+		assert.Nil(t, c.Set(context.Background(), "user:email:user@example.com", stale, 60))
+
+		found, err := repo.FindByEmail(context.Background(), "user@example.com")
+		assert.Nil(t, err)
+		assert.Equal(t, current.id, found.ID)
+		assert.Equal(t, "null", c.raw("user:email:user@example.com"))
+	})
+
+	t.Run("missing email is cached as missing", func(t *testing.T) {
+		db := &fakeDB{}
+		repo := repository.NewUserFinderRepository(newFakeGorm(t, db), newFakeCache(), testUserCacheConfig)
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.FindByEmail(context.Background(), "missing@example.com")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}
+
+		assert.Equal(t, 1, db.queryCount())
+	})
+}
+
+func TestUserFinderRepository_FindCredentialsByEmail(t *testing.T) {
+	t.Run("known and unknown emails are looked up with one query each and never cached", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com", password: "$2a$10$hash"}
+		db := &fakeDB{users: []fakeUser{user}}
+		c := newFakeCache()
+		repo := repository.NewUserFinderRepository(newFakeGorm(t, db), c, testUserCacheConfig)
+
+		found, err := repo.FindCredentialsByEmail(context.Background(), " User@Example.com ")
+		assert.Nil(t, err)
+		assert.Equal(t, user.id, found.ID)
+		assert.Equal(t, user.password, found.Password)
+		assert.Equal(t, 1, db.queryCount())
+
+		_, err = repo.FindCredentialsByEmail(context.Background(), "missing@example.com")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Equal(t, 2, db.queryCount())
+
+		keys, _ := c.Scan(context.Background(), "*")
+		assert.Empty(t, keys)
+	})
+}
+
+func TestUserUpdaterRepository_Invalidation(t *testing.T) {
+	t.Run("written user is not cached again until its tombstone expires", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com"}
+		db := &fakeDB{users: []fakeUser{user}}
+		c := newFakeCache()
+		gormDB := newFakeGorm(t, db)
+		finder := repository.NewUserFinderRepository(gormDB, c, testUserCacheConfig)
+		updater := repository.NewUserUpdaterRepository(gormDB, c)
+
+		_, err := finder.FindByID(context.Background(), user.id)
+		assert.Nil(t, err)
+
+		assert.Nil(t, updater.MarkEmailVerified(context.Background(), user.id))
+		assert.Equal(t, "null", c.raw("user:id:"+user.id.String()))
+
+		// lookups racing the write read the database instead of caching what they read
+		for i := 0; i < 2; i++ {
+			_, err = finder.FindByID(context.Background(), user.id)
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, 3, db.queryCount())
+		assert.Equal(t, "null", c.raw("user:id:"+user.id.String()))
+
+		// once the tombstone expires the user is cached again
+		assert.Nil(t, c.Remove(context.Background(), "user:id:"+user.id.String()))
+		for i := 0; i < 2; i++ {
+			_, err = finder.FindByID(context.Background(), user.id)
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, 4, db.queryCount())
+	})
+}
+
+func TestUserUpdaterRepository_UpdatePasswordHash(t *testing.T) {
+	t.Run("hash is replaced while it is still the verified one", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com", password: "old hash"}
+		db := &fakeDB{users: []fakeUser{user}}
+		c := newFakeCache()
+		updater := repository.NewUserUpdaterRepository(newFakeGorm(t, db), c)
+
+		assert.Nil(t, updater.UpdatePasswordHash(context.Background(), user.id, "old hash", "upgraded hash"))
+		assert.Equal(t, "upgraded hash", db.users[0].password)
+		assert.Equal(t, "null", c.raw("user:id:"+user.id.String()))
+	})
+
+	t.Run("password changed in the meantime is kept", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com", password: "new password hash"}
+		db := &fakeDB{users: []fakeUser{user}}
+		c := newFakeCache()
+		updater := repository.NewUserUpdaterRepository(newFakeGorm(t, db), c)
+
+		assert.Nil(t, updater.UpdatePasswordHash(context.Background(), user.id, "old hash", "upgraded hash"))
+		assert.Equal(t, "new password hash", db.users[0].password)
+		assert.Equal(t, "", c.raw("user:id:"+user.id.String()))
+	})
+}
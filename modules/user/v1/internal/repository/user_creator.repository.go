@@ -7,19 +7,20 @@ import (
 	"gorm.io/gorm"
 
 	"grpc-starter/common/cache"
+	commonGorm "grpc-starter/common/gorm"
 	"grpc-starter/modules/user/v1/entity"
 )
 
 // UserCreatorRepository defines dependencies for UserCreator
 type UserCreatorRepository struct {
 	db    *gorm.DB
-	cache cache.Cacheable
+	cache cache.ContextCacheable
 }
 
 // NewUserCreatorRepository creates a new UserCreator repository
 func NewUserCreatorRepository(
 	db *gorm.DB,
-	cache cache.Cacheable,
+	cache cache.ContextCacheable,
 ) *UserCreatorRepository {
 	return &UserCreatorRepository{
 		db:    db,
@@ -29,15 +30,25 @@ func NewUserCreatorRepository(
 
 // UserCreatorRepositoryUseCase is use case for creating in user table
 type UserCreatorRepositoryUseCase interface {
-	// Create creates user
+	// Create creates user and assigns it the regular user role
 	Create(ctx context.Context, user *entity.User) error
 }
 
-// Create creates user
+// Create creates user and assigns it the regular user role
 func (r *UserCreatorRepository) Create(ctx context.Context, user *entity.User) error {
-	if err := r.db.Create(user).Error; err != nil {
-		return errors.Wrap(err, "[UserCreatorRepository - Create] Error while creating user data")
+	if err := r.db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			return assignDefaultRole(tx, user.ID)
+		}); err != nil {
+		return errors.Wrap(commonGorm.TranslateError(err), "[UserCreatorRepository - Create] Error while creating user data")
 	}
 
+	// clears cached misses of the new user
+	invalidateUser(ctx, r.cache, user.ID, user.Email)
+
 	return nil
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -14,13 +15,13 @@ import (
 // UserDeleterRepository defines dependencies for UserDeleter
 type UserDeleterRepository struct {
 	db    *gorm.DB
-	cache cache.Cacheable
+	cache cache.ContextCacheable
 }
 
 // NewUserDeleterRepository creates a new UserDeleter repository
 func NewUserDeleterRepository(
 	db *gorm.DB,
-	cache cache.Cacheable,
+	cache cache.ContextCacheable,
 ) *UserDeleterRepository {
 	return &UserDeleterRepository{
 		db:    db,
@@ -30,15 +31,32 @@ func NewUserDeleterRepository(
 
 // UserDeleterRepositoryUseCase is use case for deleting in user table
 type UserDeleterRepositoryUseCase interface {
-	// Delete deletes user
-	Delete(ctx context.Context, refID uuid.UUID) error
+	// Delete soft deletes user, recording who deleted it
+	Delete(ctx context.Context, refID uuid.UUID, deletedBy string) error
 }
 
-// Delete deletes user
-func (r *UserDeleterRepository) Delete(ctx context.Context, refID uuid.UUID) error {
-	if err := r.db.WithContext(ctx).Model(&entity.User{}).Delete(&entity.User{}, "ref_id = ?", refID).Error; err != nil {
-		return errors.Wrap(err, "[UserDeleterRepository - Delete] Error while deleting user data")
+// Delete soft deletes user, recording who deleted it.
+// It returns gorm.ErrRecordNotFound when the user does not exist or is already deleted.
+func (r *UserDeleterRepository) Delete(ctx context.Context, refID uuid.UUID, deletedBy string) error {
+	now := time.Now()
+	res := r.db.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ?", refID).
+		UpdateColumns(map[string]interface{}{
+			"deleted_at": now,
+			"deleted_by": deletedBy,
+			"updated_at": now,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return errors.Wrap(res.Error, "[UserDeleterRepository - Delete] Error while deleting user data")
 	}
+	if res.RowsAffected == 0 {
+		return errors.Wrap(gorm.ErrRecordNotFound, "[UserDeleterRepository - Delete] Error while deleting user data")
+	}
+
+	invalidateUser(ctx, r.cache, refID, "")
 
 	return nil
 }
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+const (
+	// emailVerificationKeyPrefix is the cache key prefix for pending email verification code
+	emailVerificationKeyPrefix = "users:email-verification:%s"
+	// emailVerificationAttemptsKeyPrefix is the cache key prefix for attempts of pending email verification code
+	emailVerificationAttemptsKeyPrefix = "users:email-verification-attempts:%s"
+	// emailVerificationCooldownKeyPrefix is the cache key prefix for email verification resend cooldown
+	emailVerificationCooldownKeyPrefix = "users:email-verification-cooldown:%s"
+)
+
+// UserEmailVerificationRepository defines dependencies for email verification code
+type UserEmailVerificationRepository struct {
+	cache cache.ContextCacheable
+}
+
+// NewUserEmailVerificationRepository creates a new UserEmailVerification repository
+func NewUserEmailVerificationRepository(
+	cache cache.ContextCacheable,
+) *UserEmailVerificationRepository {
+	return &UserEmailVerificationRepository{
+		cache: cache,
+	}
+}
+
+// UserEmailVerificationRepositoryUseCase is use case for storing email verification code
+type UserEmailVerificationRepositoryUseCase interface {
+	// Save stores pending email verification of user until it expires, resetting its attempts
+	Save(ctx context.Context, userID uuid.UUID, otp *entity.OTP) error
+	// Find finds pending email verification of user
+	Find(ctx context.Context, userID uuid.UUID) (*entity.OTP, error)
+	// CountAttempt atomically counts an attempt of pending email verification of user and returns the count of attempts
+	CountAttempt(ctx context.Context, userID uuid.UUID, otp *entity.OTP) (int, error)
+	// Remove removes pending email verification of user together with its attempts
+	Remove(ctx context.Context, userID uuid.UUID) error
+	// StartCooldown marks that email verification code has just been sent to user,
+	// it returns false when user is already in cooldown
+	StartCooldown(ctx context.Context, userID uuid.UUID, ttl time.Duration) (bool, error)
+}
+
+// Save stores pending email verification of user until it expires, resetting its attempts
+func (r *UserEmailVerificationRepository) Save(ctx context.Context, userID uuid.UUID, otp *entity.OTP) error {
+	if err := r.cache.SetWithExpireAt(ctx, emailVerificationKey(userID), otp, otp.ExpiresAt); err != nil {
+		return errors.Wrap(err, "[UserEmailVerificationRepository - Save] Error while saving email verification")
+	}
+
+	if err := r.cache.Remove(ctx, fmt.Sprintf(emailVerificationAttemptsKeyPrefix, userID)); err != nil {
+		return errors.Wrap(err, "[UserEmailVerificationRepository - Save] Error while resetting email verification attempts")
+	}
+
+	return nil
+}
+
+// Find finds pending email verification of user
+func (r *UserEmailVerificationRepository) Find(ctx context.Context, userID uuid.UUID) (*entity.OTP, error) {
+	data, err := r.cache.Get(ctx, emailVerificationKey(userID))
+	if err != nil {
+		return nil, errors.Wrap(err, "[UserEmailVerificationRepository - Find] Error while finding email verification")
+	}
+
+	verification := new(entity.OTP)
+	if err := json.Unmarshal(data, verification); err != nil {
+		return nil, errors.Wrap(err, "[UserEmailVerificationRepository - Find] Error while decoding email verification")
+	}
+
+	return verification, nil
+}
+
+// CountAttempt atomically counts an attempt of pending email verification of user and returns the count of attempts,
+// so concurrent attempts can never exceed the maximum attempts. Attempts expire together with the code.
+func (r *UserEmailVerificationRepository) CountAttempt(ctx context.Context, userID uuid.UUID, otp *entity.OTP) (int, error) {
+	attempts, err := r.cache.Incr(ctx, fmt.Sprintf(emailVerificationAttemptsKeyPrefix, userID), ttlUntil(otp.ExpiresAt))
+	if err != nil {
+		return 0, errors.Wrap(err, "[UserEmailVerificationRepository - CountAttempt] Error while counting email verification attempt")
+	}
+
+	return int(attempts), nil
+}
+
+// Remove removes pending email verification of user together with its attempts
+func (r *UserEmailVerificationRepository) Remove(ctx context.Context, userID uuid.UUID) error {
+	if err := r.cache.Remove(ctx, emailVerificationKey(userID)); err != nil {
+		return errors.Wrap(err, "[UserEmailVerificationRepository - Remove] Error while removing email verification")
+	}
+
+	if err := r.cache.Remove(ctx, fmt.Sprintf(emailVerificationAttemptsKeyPrefix, userID)); err != nil {
+		return errors.Wrap(err, "[UserEmailVerificationRepository - Remove] Error while removing email verification attempts")
+	}
+
+	return nil
+}
+
+// StartCooldown marks that email verification code has just been sent to user, it returns false when
+// user is already in cooldown. The cooldown is checked and started at once, so concurrent resends start it once.
+func (r *UserEmailVerificationRepository) StartCooldown(ctx context.Context, userID uuid.UUID, ttl time.Duration) (bool, error) {
+	started, err := r.cache.SetNX(ctx, fmt.Sprintf(emailVerificationCooldownKeyPrefix, userID), true, int(ttl.Seconds()))
+	if err != nil {
+		return false, errors.Wrap(err, "[UserEmailVerificationRepository - StartCooldown] Error while saving email verification cooldown")
+	}
+
+	return started, nil
+}
+
+// emailVerificationKey builds cache key of pending email verification of user
+func emailVerificationKey(userID uuid.UUID) string {
+	return fmt.Sprintf(emailVerificationKeyPrefix, userID)
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+func TestUserEmailVerificationRepository(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("attempts are counted until a new code is saved", func(t *testing.T) {
+		repo := repository.NewUserEmailVerificationRepository(newFakeCache())
+		ctx := context.Background()
+		otp := entity.NewOTP("hash", time.Minute)
+		assert.Nil(t, repo.Save(ctx, userID, otp))
+
+		for i := 1; i <= 3; i++ {
+			attempts, err := repo.CountAttempt(ctx, userID, otp)
+			assert.Nil(t, err)
+			assert.Equal(t, i, attempts)
+		}
+
+		assert.Nil(t, repo.Save(ctx, userID, entity.NewOTP("new hash", time.Minute)))
+		attempts, err := repo.CountAttempt(ctx, userID, otp)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("code is removed together with its attempts", func(t *testing.T) {
+		repo := repository.NewUserEmailVerificationRepository(newFakeCache())
+		ctx := context.Background()
+		otp := entity.NewOTP("hash", time.Minute)
+		assert.Nil(t, repo.Save(ctx, userID, otp))
+
+		_, err := repo.CountAttempt(ctx, userID, otp)
+		assert.Nil(t, err)
+
+		assert.Nil(t, repo.Remove(ctx, userID))
+		_, err = repo.Find(ctx, userID)
+		assert.NotNil(t, err)
+
+		attempts, err := repo.CountAttempt(ctx, userID, otp)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("cooldown is started only once", func(t *testing.T) {
+		repo := repository.NewUserEmailVerificationRepository(newFakeCache())
+		ctx := context.Background()
+
+		started, err := repo.StartCooldown(ctx, userID, time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, started)
+
+		started, err = repo.StartCooldown(ctx, userID, time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, started)
+	})
+}
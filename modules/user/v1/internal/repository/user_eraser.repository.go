@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/cache"
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// ErrUserAlreadyErased is returned when user has been erased by another job since it was found
+var ErrUserAlreadyErased = errors.New("user has already been erased")
+
+// UserEraserRepository defines dependencies for erasing personal data of deleted users
+type UserEraserRepository struct {
+	db    *gorm.DB
+	cache cache.ContextCacheable
+}
+
+// NewUserEraserRepository creates a new UserEraser repository
+func NewUserEraserRepository(
+	db *gorm.DB,
+	cache cache.ContextCacheable,
+) *UserEraserRepository {
+	return &UserEraserRepository{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// UserEraserRepositoryUseCase is use case for erasing personal data of deleted users
+type UserEraserRepositoryUseCase interface {
+	// FindErasable finds users deleted before the given time whose personal data has not been erased yet,
+	// leaving out users whose erasure failed after the given time
+	FindErasable(ctx context.Context, deletedBefore time.Time, failedBefore time.Time, limit int) ([]*entity.User, error)
+	// Erase anonymises user and the notifications sent to it, and deletes the rest of its rows
+	Erase(ctx context.Context, user *entity.User) error
+	// MarkEraseFailed marks that erasing user has failed, so it is retried later
+	MarkEraseFailed(ctx context.Context, user *entity.User) error
+}
+
+// FindErasable finds users deleted before the given time whose personal data has not been erased yet,
+// leaving out users whose erasure failed after the given time. Users that have never failed come first,
+// the earliest deleted first, followed by the users that failed the longest ago.
+func (r *UserEraserRepository) FindErasable(ctx context.Context, deletedBefore time.Time, failedBefore time.Time, limit int) ([]*entity.User, error) {
+	var result []*entity.User
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Model(&entity.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND erased_at IS NULL", deletedBefore).
+		Where("erase_failed_at IS NULL OR erase_failed_at < ?", failedBefore).
+		Order("erase_failed_at ASC NULLS FIRST, deleted_at ASC").
+		Limit(limit).
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserEraserRepository - FindErasable] Error while finding erasable user data")
+	}
+
+	return result, nil
+}
+
+// Erase anonymises user and the notifications sent to it, and deletes the rest of its rows in one transaction.
+// The user row is kept so that references to it stay valid, only its personal data is replaced.
+// The cached user and the id cached by its email are invalidated once the transaction commits.
+func (r *UserEraserRepository) Erase(ctx context.Context, user *entity.User) error {
+	now := time.Now()
+	err := r.db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			res := tx.Unscoped().
+				Model(&entity.User{}).
+				Where("id = ? AND erased_at IS NULL", user.ID).
+				UpdateColumns(map[string]interface{}{
+					"email":                    entity.ErasedEmail(user.ID),
+					"username":                 nil,
+					"phone_number":             nil,
+					"password":                 "",
+					"email_verified_at":        nil,
+					"phone_number_verified_at": nil,
+					"erased_at":                now,
+					"updated_at":               now,
+					"version":                  gorm.Expr("version + 1"),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrUserAlreadyErased
+			}
+
+			for _, model := range []interface{}{
+				&entity.UserIdentity{},
+				&entity.Session{},
+				&entity.RefreshToken{},
+				&entity.UserMFA{},
+				&entity.RecoveryCode{},
+			} {
+				if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&notificationEntity.EmailSent{}).
+				Where(`"to" = ?`, user.Email).
+				UpdateColumns(map[string]interface{}{
+					"to":         entity.ErasedEmail(user.ID),
+					"content":    "",
+					"updated_at": now,
+				}).Error; err != nil {
+				return err
+			}
+
+			if !user.PhoneNumber.Valid {
+				return nil
+			}
+
+			return tx.Model(&notificationEntity.SMSSent{}).
+				Where(`"to" = ?`, user.PhoneNumber.String).
+				UpdateColumns(map[string]interface{}{
+					"to":         "",
+					"content":    "",
+					"updated_at": now,
+				}).Error
+		})
+	if err != nil {
+		return errors.Wrap(err, "[UserEraserRepository - Erase] Error while erasing user data")
+	}
+
+	// clears cached personal data of the erased user
+	invalidateUser(ctx, r.cache, user.ID, user.Email)
+
+	return nil
+}
+
+// MarkEraseFailed marks that erasing user has failed, so it is left out until the retry delay has passed
+func (r *UserEraserRepository) MarkEraseFailed(ctx context.Context, user *entity.User) error {
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Model(&entity.User{}).
+		Where("id = ? AND erased_at IS NULL", user.ID).
+		UpdateColumn("erase_failed_at", time.Now()).Error; err != nil {
+		return errors.Wrap(err, "[UserEraserRepository - MarkEraseFailed] Error while marking user erasure as failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+func TestUserEraserRepository_Erase(t *testing.T) {
+	t.Run("erased user is no longer served from cache", func(t *testing.T) {
+		user := fakeUser{id: uuid.New(), email: "user@example.com"}
+		db := &fakeDB{users: []fakeUser{user}}
+		c := newFakeCache()
+		gormDB := newFakeGorm(t, db)
+		finder := repository.NewUserFinderRepository(gormDB, c, testUserCacheConfig)
+		eraser := repository.NewUserEraserRepository(gormDB, c)
+
+		_, err := finder.FindByEmail(context.Background(), user.email)
+		assert.Nil(t, err)
+		for _, key := range []string{"user:id:" + user.id.String(), "user:email:" + user.email} {
+			assert.NotEmpty(t, c.raw(key))
+			assert.NotEqual(t, "null", c.raw(key))
+		}
+
+		assert.Nil(t, eraser.Erase(context.Background(), &entity.User{ID: user.id, Email: user.email}))
+		assert.Equal(t, "null", c.raw("user:id:"+user.id.String()))
+		assert.Equal(t, "null", c.raw("user:email:"+user.email))
+	})
+}
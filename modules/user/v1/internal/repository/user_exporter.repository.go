@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// UserExporterRepository defines dependencies for exporting personal data of users across modules
+type UserExporterRepository struct {
+	db *gorm.DB
+}
+
+// NewUserExporterRepository creates a new UserExporter repository
+func NewUserExporterRepository(
+	db *gorm.DB,
+) *UserExporterRepository {
+	return &UserExporterRepository{
+		db: db,
+	}
+}
+
+// UserExporterRepositoryUseCase is use case for exporting personal data of users across modules
+type UserExporterRepositoryUseCase interface {
+	// FindIdentities finds identities linked to a user
+	FindIdentities(ctx context.Context, userID uuid.UUID) ([]*entity.UserIdentity, error)
+	// FindSessions finds every session of a user, including revoked and expired ones
+	FindSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error)
+	// FindEmailsSent finds emails sent to an email address, without their content
+	FindEmailsSent(ctx context.Context, email string) ([]*notificationEntity.EmailSent, error)
+	// FindSMSSent finds sms sent to a phone number, without their content
+	FindSMSSent(ctx context.Context, phoneNumber string) ([]*notificationEntity.SMSSent, error)
+}
+
+// FindIdentities finds identities linked to a user
+func (r *UserExporterRepository) FindIdentities(ctx context.Context, userID uuid.UUID) ([]*entity.UserIdentity, error) {
+	var result []*entity.UserIdentity
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.UserIdentity{}).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserExporterRepository - FindIdentities] Error while finding user identity data")
+	}
+
+	return result, nil
+}
+
+// FindSessions finds every session of a user, including revoked and expired ones
+func (r *UserExporterRepository) FindSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error) {
+	var result []*entity.Session
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserExporterRepository - FindSessions] Error while finding session data")
+	}
+
+	return result, nil
+}
+
+// FindEmailsSent finds emails sent to an email address, without their content
+func (r *UserExporterRepository) FindEmailsSent(ctx context.Context, email string) ([]*notificationEntity.EmailSent, error) {
+	var result []*notificationEntity.EmailSent
+	if err := r.db.
+		WithContext(ctx).
+		Model(&notificationEntity.EmailSent{}).
+		Omit("content").
+		Where(`"to" = ?`, email).
+		Order("created_at ASC").
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserExporterRepository - FindEmailsSent] Error while finding email sent data")
+	}
+
+	return result, nil
+}
+
+// FindSMSSent finds sms sent to a phone number, without their content
+func (r *UserExporterRepository) FindSMSSent(ctx context.Context, phoneNumber string) ([]*notificationEntity.SMSSent, error) {
+	var result []*notificationEntity.SMSSent
+	if err := r.db.
+		WithContext(ctx).
+		Model(&notificationEntity.SMSSent{}).
+		Omit("content").
+		Where(`"to" = ?`, phoneNumber).
+		Order("created_at ASC").
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserExporterRepository - FindSMSSent] Error while finding sms sent data")
+	}
+
+	return result, nil
+}
@@ -2,56 +2,219 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 
 	"grpc-starter/common/cache"
+	"grpc-starter/common/config"
+	"grpc-starter/common/constant"
+	"grpc-starter/common/tools"
 	"grpc-starter/modules/user/v1/entity"
 )
 
 // UserFinderRepository defines dependencies for UserFinder
 type UserFinderRepository struct {
-	db    *gorm.DB
-	cache cache.Cacheable
+	db        *gorm.DB
+	cache     cache.ContextCacheable
+	userCache *userCache
 }
 
 // NewUserFinderRepository creates a new UserFinder repository
 func NewUserFinderRepository(
 	db *gorm.DB,
-	cache cache.Cacheable,
+	cache cache.ContextCacheable,
+	cacheConfig config.UserCache,
 ) *UserFinderRepository {
 	return &UserFinderRepository{
-		db:    db,
-		cache: cache,
+		db:        db,
+		cache:     cache,
+		userCache: newUserCache(cache, cacheConfig),
 	}
 }
 
 // UserFinderRepositoryUseCase is use case for finding in user table
 type UserFinderRepositoryUseCase interface {
-	// FindByID finds user
+	// FindByID finds user without its password hash, reading through cache
 	FindByID(ctx context.Context, refID uuid.UUID) (*entity.User, error)
-	// FindByEmail finds user by email
+	// FindByEmail finds user by email without its password hash, reading through cache
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	// FindCredentialsByEmail finds user by email with its password hash, never reading through cache
+	FindCredentialsByEmail(ctx context.Context, email string) (*entity.User, error)
+	// FindByPhoneNumber finds user by phone number, in any format entity.NormalizePhoneNumber understands
+	FindByPhoneNumber(ctx context.Context, phoneNumber string) (*entity.User, error)
+	// FindByIDWithDeleted finds user including soft deleted one
+	FindByIDWithDeleted(ctx context.Context, refID uuid.UUID) (*entity.User, error)
+	// FindAll finds users matching filter, one more user than the limit is returned when there is a next page
+	FindAll(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error)
 }
 
-// FindByID finds user
+// FindByID finds user without its password hash, reading through cache.
+// A user that is not found is cached as well, so that repeated lookups of it do not reach the database,
+// and concurrent lookups of a user missing in cache share a single query.
 func (r *UserFinderRepository) FindByID(ctx context.Context, refID uuid.UUID) (*entity.User, error) {
-	var result *entity.User
-	if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", refID).First(&result).Error; err != nil {
+	if cached, ok := r.userCache.findByID(ctx, refID); ok {
+		if cached == nil {
+			return nil, errors.Wrap(gorm.ErrRecordNotFound, "[UserFinderRepository - FindByID] Error while finding cached user data")
+		}
+		return cached, nil
+	}
+
+	result, err := r.userCache.loadByID(ctx, refID, func(ctx context.Context) (*entity.User, error) {
+		var result *entity.User
+		err := r.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", refID).First(&result).Error
+		return result, err
+	})
+	if err != nil {
 		return nil, errors.Wrap(err, "[UserFinderRepository - FindByID] Error while finding user data")
 	}
 
 	return result, nil
 }
 
-// FindByEmail finds user by email
+// FindByEmail finds user by email without its password hash, reading through cache.
+// Only the id of the user is cached by email, the user itself is read through FindByID.
 func (r *UserFinderRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	email = entity.NormalizeEmail(email)
+
+	if id, ok := r.userCache.findIDByEmail(ctx, email); ok {
+		if id == nil {
+			return nil, errors.Wrap(gorm.ErrRecordNotFound, "[UserFinderRepository - FindByEmail] Error while finding cached user data")
+		}
+
+		user, err := r.FindByID(ctx, *id)
+		// the cached id is stale when its user is gone or no longer has the email
+		if err != nil || user.Email != email {
+			invalidateUser(ctx, r.cache, *id, email)
+			return r.findByEmail(ctx, email)
+		}
+
+		return user, nil
+	}
+
+	return r.findByEmail(ctx, email)
+}
+
+// findByEmail finds user by normalised email in database and caches the result
+func (r *UserFinderRepository) findByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var result *entity.User
 	if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("email = ?", email).First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.userCache.setMissingEmail(ctx, email)
+		}
 		return nil, errors.Wrap(err, "[UserFinderRepository - FindByEmail] Error while finding user data")
 	}
 
+	r.userCache.setUser(ctx, result)
+	r.userCache.setEmail(ctx, email, result.ID)
+
+	// users read through cache never have their password hash, whether they were cached or not
+	result.Password = ""
+
 	return result, nil
 }
+
+// FindCredentialsByEmail finds user by email with its password hash, never reading through cache,
+// so credentials are never cached. It makes a single query whether the email exists or not,
+// so looking up an unknown email takes as long as looking up a known one.
+func (r *UserFinderRepository) FindCredentialsByEmail(ctx context.Context, email string) (*entity.User, error) {
+	var result *entity.User
+	if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("email = ?", entity.NormalizeEmail(email)).First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserFinderRepository - FindCredentialsByEmail] Error while finding user credentials")
+	}
+
+	return result, nil
+}
+
+// FindByPhoneNumber finds user by phone number, which is normalised like stored phone numbers are
+func (r *UserFinderRepository) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*entity.User, error) {
+	var result *entity.User
+	if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("phone_number = ?", entity.NormalizePhoneNumber(phoneNumber)).First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserFinderRepository - FindByPhoneNumber] Error while finding user data")
+	}
+
+	return result, nil
+}
+
+// FindByIDWithDeleted finds user including soft deleted one
+func (r *UserFinderRepository) FindByIDWithDeleted(ctx context.Context, refID uuid.UUID) (*entity.User, error) {
+	var result *entity.User
+	if err := r.db.WithContext(ctx).Unscoped().Model(&entity.User{}).Where("id = ?", refID).First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserFinderRepository - FindByIDWithDeleted] Error while finding user data")
+	}
+
+	return result, nil
+}
+
+// FindAll finds users matching filter, one more user than the limit is returned when there is a next page.
+// Users are sorted by the sort column and then by id, so that the order is stable and the cursor is unambiguous.
+func (r *UserFinderRepository) FindAll(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error) {
+	query := r.db.WithContext(ctx).Model(&entity.User{})
+
+	switch filter.Status {
+	case entity.UserStatusActive:
+		query = query.Where("disabled_at IS NULL")
+	case entity.UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	case entity.UserStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if search := tools.ToTSQueryFormat(filter.Search); search != "" {
+		query = query.Where("search_vector @@ to_tsquery('simple', ?)", search)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+
+	sortColumn := userSortColumn(filter.SortBy)
+	direction, operator := "DESC", "<"
+	if filter.SortOrder == constant.Ascending {
+		direction, operator = "ASC", ">"
+	}
+
+	if filter.Cursor != nil {
+		value, err := userCursorValue(filter.Cursor)
+		if err != nil {
+			return nil, errors.Wrap(err, "[UserFinderRepository - FindAll] Error while reading cursor")
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortColumn, operator), value, filter.Cursor.ID)
+	}
+
+	var result []*entity.User
+	if err := query.
+		Order(fmt.Sprintf("%s %s, id %s", sortColumn, direction, direction)).
+		Limit(filter.Limit + 1).
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserFinderRepository - FindAll] Error while finding user data")
+	}
+
+	return result, nil
+}
+
+// userSortColumn maps sort field into its column, nullable columns are coalesced so that row comparison works
+func userSortColumn(sortBy string) string {
+	switch sortBy {
+	case entity.UserSortByUsername:
+		return "COALESCE(username, '')"
+	case entity.UserSortByEmail:
+		return "COALESCE(email, '')"
+	default:
+		return "created_at"
+	}
+}
+
+// userCursorValue converts cursor value into the type of its sort column
+func userCursorValue(cursor *entity.UserCursor) (interface{}, error) {
+	if cursor.SortBy == entity.UserSortByUsername || cursor.SortBy == entity.UserSortByEmail {
+		return cursor.Value, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, cursor.Value)
+}
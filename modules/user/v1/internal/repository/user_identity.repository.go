@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/cache"
+	commonGorm "grpc-starter/common/gorm"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// UserIdentityRepository defines dependencies for identities of OpenID Connect providers linked to users
+type UserIdentityRepository struct {
+	db    *gorm.DB
+	cache cache.ContextCacheable
+}
+
+// NewUserIdentityRepository creates a new UserIdentity repository
+func NewUserIdentityRepository(
+	db *gorm.DB,
+	cache cache.ContextCacheable,
+) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// UserIdentityRepositoryUseCase is use case for user identities table
+type UserIdentityRepositoryUseCase interface {
+	// FindByProviderSubject finds identity by its provider and subject
+	FindByProviderSubject(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error)
+	// Create links identity to an existing user
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	// CreateWithUser creates user with the regular user role and links identity to it in one transaction
+	CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error
+}
+
+// FindByProviderSubject finds identity by its provider and subject
+func (r *UserIdentityRepository) FindByProviderSubject(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error) {
+	var result *entity.UserIdentity
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.UserIdentity{}).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserIdentityRepository - FindByProviderSubject] Error while finding user identity data")
+	}
+
+	return result, nil
+}
+
+// Create links identity to an existing user
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return errors.Wrap(commonGorm.TranslateError(err), "[UserIdentityRepository - Create] Error while creating user identity data")
+	}
+
+	return nil
+}
+
+// CreateWithUser creates user with the regular user role and links identity to it in one transaction
+func (r *UserIdentityRepository) CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	if err := r.db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			if err := assignDefaultRole(tx, user.ID); err != nil {
+				return err
+			}
+			return tx.Create(identity).Error
+		}); err != nil {
+		return errors.Wrap(commonGorm.TranslateError(err), "[UserIdentityRepository - CreateWithUser] Error while creating user and identity data")
+	}
+
+	// clears cached misses of the new user
+	invalidateUser(ctx, r.cache, user.ID, user.Email)
+
+	return nil
+}
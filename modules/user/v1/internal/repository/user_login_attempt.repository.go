@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+const (
+	// loginAttemptKeyPrefix is the cache key prefix for failed login counters, formatted with scope and hashed identifier
+	loginAttemptKeyPrefix = "users:login-attempt:%s:%s"
+	// loginLockKeyPrefix is the cache key prefix for login lockouts, formatted with scope and hashed identifier
+	loginLockKeyPrefix = "users:login-lock:%s:%s"
+)
+
+// UserLoginAttemptRepository defines dependencies for failed login counters
+type UserLoginAttemptRepository struct {
+	cache cache.ContextCacheable
+}
+
+// NewUserLoginAttemptRepository creates a new UserLoginAttempt repository
+func NewUserLoginAttemptRepository(
+	cache cache.ContextCacheable,
+) *UserLoginAttemptRepository {
+	return &UserLoginAttemptRepository{
+		cache: cache,
+	}
+}
+
+// UserLoginAttemptRepositoryUseCase is use case for storing failed login counters.
+// Counters are identified by a scope, e.g. account or ip, and an identifier which is hashed before it is used as key.
+type UserLoginAttemptRepositoryUseCase interface {
+	// Find finds failed logins and lockout of identifier, it returns an empty counter when there is none
+	Find(ctx context.Context, scope string, identifier string) (*entity.LoginAttempt, error)
+	// RegisterFailure atomically counts a failed login of identifier and returns the count of failures,
+	// which are counted within the window starting with the first of them
+	RegisterFailure(ctx context.Context, scope string, identifier string, window time.Duration) (int, error)
+	// Lock locks login of identifier until the given time
+	Lock(ctx context.Context, scope string, identifier string, lockedUntil time.Time) error
+	// Remove removes failed logins and lockout of identifier
+	Remove(ctx context.Context, scope string, identifier string) error
+}
+
+// Find finds failed logins and lockout of identifier in a single round trip, it returns an empty counter when there is none
+func (r *UserLoginAttemptRepository) Find(ctx context.Context, scope string, identifier string) (*entity.LoginAttempt, error) {
+	values, err := r.cache.MGet(ctx, loginAttemptKey(scope, identifier), loginLockKey(scope, identifier))
+	if err != nil {
+		return nil, errors.Wrap(err, "[UserLoginAttemptRepository - Find] Error while finding login attempt")
+	}
+
+	attempt := new(entity.LoginAttempt)
+	if len(values[0]) > 0 {
+		if attempt.Failures, err = strconv.Atoi(string(values[0])); err != nil {
+			return nil, errors.Wrap(err, "[UserLoginAttemptRepository - Find] Error while decoding failed logins")
+		}
+	}
+
+	if len(values[1]) > 0 {
+		if err := json.Unmarshal(values[1], &attempt.LockedUntil); err != nil {
+			return nil, errors.Wrap(err, "[UserLoginAttemptRepository - Find] Error while decoding login lockout")
+		}
+	}
+
+	return attempt, nil
+}
+
+// RegisterFailure atomically counts a failed login of identifier and returns the count of failures.
+// The counter expires once the window passes since the first failure, later failures do not extend it.
+func (r *UserLoginAttemptRepository) RegisterFailure(ctx context.Context, scope string, identifier string, window time.Duration) (int, error) {
+	failures, err := r.cache.Incr(ctx, loginAttemptKey(scope, identifier), int(window.Seconds()))
+	if err != nil {
+		return 0, errors.Wrap(err, "[UserLoginAttemptRepository - RegisterFailure] Error while counting failed login")
+	}
+
+	return int(failures), nil
+}
+
+// Lock locks login of identifier until the given time
+func (r *UserLoginAttemptRepository) Lock(ctx context.Context, scope string, identifier string, lockedUntil time.Time) error {
+	if err := r.cache.SetWithExpireAt(ctx, loginLockKey(scope, identifier), lockedUntil, lockedUntil); err != nil {
+		return errors.Wrap(err, "[UserLoginAttemptRepository - Lock] Error while locking login")
+	}
+
+	return nil
+}
+
+// Remove removes failed logins and lockout of identifier
+func (r *UserLoginAttemptRepository) Remove(ctx context.Context, scope string, identifier string) error {
+	for _, key := range []string{loginAttemptKey(scope, identifier), loginLockKey(scope, identifier)} {
+		if err := r.cache.Remove(ctx, key); err != nil {
+			return errors.Wrap(err, "[UserLoginAttemptRepository - Remove] Error while removing login attempt")
+		}
+	}
+
+	return nil
+}
+
+// loginAttemptKey builds cache key from scope and hashed identifier, so emails and ip addresses are never stored
+func loginAttemptKey(scope string, identifier string) string {
+	return fmt.Sprintf(loginAttemptKeyPrefix, scope, tools.SHA256Hex(identifier))
+}
+
+// loginLockKey builds cache key of lockout from scope and hashed identifier
+func loginLockKey(scope string, identifier string) string {
+	return fmt.Sprintf(loginLockKeyPrefix, scope, tools.SHA256Hex(identifier))
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+func TestUserLoginAttemptRepository(t *testing.T) {
+	t.Run("failures are counted, locked and reset on success", func(t *testing.T) {
+		repo := repository.NewUserLoginAttemptRepository(newFakeCache())
+		ctx := context.Background()
+		now := time.Now()
+		attempt := new(entity.LoginAttempt)
+
+		for i := 1; i <= 3; i++ {
+			failures, err := repo.RegisterFailure(ctx, entity.LoginAttemptScopeAccount, "user@example.com", time.Hour)
+			assert.Nil(t, err)
+			assert.Equal(t, i, failures)
+			attempt.RegisterFailure(now, failures, 3, time.Minute, time.Hour)
+		}
+		assert.Nil(t, repo.Lock(ctx, entity.LoginAttemptScopeAccount, "user@example.com", attempt.LockedUntil))
+
+		found, err := repo.Find(ctx, entity.LoginAttemptScopeAccount, "user@example.com")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, found.Failures)
+		assert.True(t, found.IsLocked(now))
+
+		other, err := repo.Find(ctx, entity.LoginAttemptScopeIP, "user@example.com")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, other.Failures)
+
+		assert.Nil(t, repo.Remove(ctx, entity.LoginAttemptScopeAccount, "user@example.com"))
+
+		found, err = repo.Find(ctx, entity.LoginAttemptScopeAccount, "user@example.com")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, found.Failures)
+		assert.False(t, found.IsLocked(now))
+
+		failures, err := repo.RegisterFailure(ctx, entity.LoginAttemptScopeAccount, "user@example.com", time.Hour)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, failures)
+	})
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+const (
+	// mfaChallengeKeyPrefix is the cache key prefix for mfa challenge
+	mfaChallengeKeyPrefix = "users:mfa-challenge:%s"
+	// mfaChallengeAttemptsKeyPrefix is the cache key prefix for attempts of mfa challenge
+	mfaChallengeAttemptsKeyPrefix = "users:mfa-challenge-attempts:%s"
+	// mfaUsedStepKeyPrefix is the cache key prefix for time step of the last accepted one-time password of a user
+	mfaUsedStepKeyPrefix = "users:mfa-used-step:%s:%d"
+)
+
+// ErrMFAChallengeClaimed is returned when mfa challenge has already been claimed by another verification, or has expired
+var ErrMFAChallengeClaimed = errors.New("mfa challenge has already been claimed")
+
+// UserMFARepository defines dependencies for two-factor authentication
+type UserMFARepository struct {
+	db    *gorm.DB
+	cache cache.ContextCacheable
+}
+
+// NewUserMFARepository creates a new UserMFA repository
+func NewUserMFARepository(
+	db *gorm.DB,
+	cache cache.ContextCacheable,
+) *UserMFARepository {
+	return &UserMFARepository{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// UserMFARepositoryUseCase is use case for two-factor authentication tables and challenges
+type UserMFARepositoryUseCase interface {
+	// FindByUserID finds two-factor authentication of user
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error)
+	// Save creates or replaces unconfirmed two-factor authentication of user
+	Save(ctx context.Context, mfa *entity.UserMFA) error
+	// Confirm confirms two-factor authentication of user and replaces its recovery codes
+	Confirm(ctx context.Context, userID uuid.UUID, codes []*entity.RecoveryCode) error
+	// FindUnusedRecoveryCodes finds recovery codes of user that have not been used
+	FindUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]*entity.RecoveryCode, error)
+	// UseRecoveryCode marks recovery code as used, it returns false when the code has already been used
+	UseRecoveryCode(ctx context.Context, id uuid.UUID) (bool, error)
+	// UseStep marks time step of one-time password of user as used, it returns false when the step has already been used
+	UseStep(ctx context.Context, userID uuid.UUID, step int64, ttl time.Duration) (bool, error)
+	// SaveChallenge stores mfa challenge until it expires
+	SaveChallenge(ctx context.Context, challenge *entity.MFAChallenge) error
+	// FindChallenge finds mfa challenge by its token
+	FindChallenge(ctx context.Context, token string) (*entity.MFAChallenge, error)
+	// CountChallengeAttempt atomically counts an attempt of mfa challenge and returns the count of attempts
+	CountChallengeAttempt(ctx context.Context, challenge *entity.MFAChallenge) (int, error)
+	// ClaimChallenge atomically finds and removes mfa challenge, so it can only be claimed once
+	ClaimChallenge(ctx context.Context, token string) (*entity.MFAChallenge, error)
+}
+
+// FindByUserID finds two-factor authentication of user
+func (r *UserMFARepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	var result *entity.UserMFA
+	if err := r.db.WithContext(ctx).Model(&entity.UserMFA{}).Where("user_id = ?", userID).First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserMFARepository - FindByUserID] Error while finding user mfa data")
+	}
+
+	return result, nil
+}
+
+// Save creates or replaces unconfirmed two-factor authentication of user
+func (r *UserMFARepository) Save(ctx context.Context, mfa *entity.UserMFA) error {
+	if err := r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "updated_at", "updated_by"}),
+		}).
+		Create(mfa).Error; err != nil {
+		return errors.Wrap(err, "[UserMFARepository - Save] Error while saving user mfa data")
+	}
+
+	return nil
+}
+
+// Confirm confirms two-factor authentication of user and replaces its recovery codes
+func (r *UserMFARepository) Confirm(ctx context.Context, userID uuid.UUID, codes []*entity.RecoveryCode) error {
+	if err := r.db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			now := time.Now()
+			if err := tx.Model(&entity.UserMFA{}).
+				Where("user_id = ?", userID).
+				UpdateColumns(map[string]interface{}{
+					"confirmed_at": now,
+					"updated_at":   now,
+				}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&codes).Error
+		}); err != nil {
+		return errors.Wrap(err, "[UserMFARepository - Confirm] Error while confirming user mfa data")
+	}
+
+	return nil
+}
+
+// FindUnusedRecoveryCodes finds recovery codes of user that have not been used
+func (r *UserMFARepository) FindUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]*entity.RecoveryCode, error) {
+	var result []*entity.RecoveryCode
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserMFARepository - FindUnusedRecoveryCodes] Error while finding recovery codes")
+	}
+
+	return result, nil
+}
+
+// UseRecoveryCode marks recovery code as used, it returns false when the code has already been used
+func (r *UserMFARepository) UseRecoveryCode(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.
+		WithContext(ctx).
+		Model(&entity.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return false, errors.Wrap(result.Error, "[UserMFARepository - UseRecoveryCode] Error while using recovery code")
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// UseStep marks time step of one-time password of user as used, it returns false when the step has already been used.
+// The step is checked and marked at once, so concurrent verifications with the same code can only use it once.
+func (r *UserMFARepository) UseStep(ctx context.Context, userID uuid.UUID, step int64, ttl time.Duration) (bool, error) {
+	unused, err := r.cache.SetNX(ctx, fmt.Sprintf(mfaUsedStepKeyPrefix, userID, step), true, int(ttl.Seconds()))
+	if err != nil {
+		return false, errors.Wrap(err, "[UserMFARepository - UseStep] Error while saving used step")
+	}
+
+	return unused, nil
+}
+
+// SaveChallenge stores mfa challenge until it expires
+func (r *UserMFARepository) SaveChallenge(ctx context.Context, challenge *entity.MFAChallenge) error {
+	if err := r.cache.SetWithExpireAt(ctx, mfaChallengeKey(challenge.Token), challenge, challenge.ExpiresAt); err != nil {
+		return errors.Wrap(err, "[UserMFARepository - SaveChallenge] Error while saving mfa challenge")
+	}
+
+	return nil
+}
+
+// FindChallenge finds mfa challenge by its token
+func (r *UserMFARepository) FindChallenge(ctx context.Context, token string) (*entity.MFAChallenge, error) {
+	data, err := r.cache.Get(ctx, mfaChallengeKey(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "[UserMFARepository - FindChallenge] Error while finding mfa challenge")
+	}
+
+	challenge := new(entity.MFAChallenge)
+	if err := json.Unmarshal(data, challenge); err != nil {
+		return nil, errors.Wrap(err, "[UserMFARepository - FindChallenge] Error while decoding mfa challenge")
+	}
+	challenge.Token = token
+
+	return challenge, nil
+}
+
+// CountChallengeAttempt atomically counts an attempt of mfa challenge and returns the count of attempts,
+// so concurrent attempts can never exceed the maximum attempts. Attempts expire together with the challenge.
+func (r *UserMFARepository) CountChallengeAttempt(ctx context.Context, challenge *entity.MFAChallenge) (int, error) {
+	attempts, err := r.cache.Incr(ctx, mfaChallengeAttemptsKey(challenge.Token), ttlUntil(challenge.ExpiresAt))
+	if err != nil {
+		return 0, errors.Wrap(err, "[UserMFARepository - CountChallengeAttempt] Error while counting mfa challenge attempt")
+	}
+
+	return int(attempts), nil
+}
+
+// ClaimChallenge atomically finds and removes mfa challenge, so it can only be claimed once.
+// Concurrent verifications of the same challenge get ErrMFAChallengeClaimed, except the first one.
+func (r *UserMFARepository) ClaimChallenge(ctx context.Context, token string) (*entity.MFAChallenge, error) {
+	data, err := r.cache.GetDel(ctx, mfaChallengeKey(token))
+	if err != nil {
+		// a missing challenge can not be told from an unavailable cache, either way the challenge can not be claimed
+		return nil, errors.Wrapf(ErrMFAChallengeClaimed, "[UserMFARepository - ClaimChallenge] Error while claiming mfa challenge: %v", err)
+	}
+
+	challenge := new(entity.MFAChallenge)
+	if err := json.Unmarshal(data, challenge); err != nil {
+		return nil, errors.Wrap(err, "[UserMFARepository - ClaimChallenge] Error while decoding mfa challenge")
+	}
+	challenge.Token = token
+
+	if err := r.cache.Remove(ctx, mfaChallengeAttemptsKey(token)); err != nil {
+		return nil, errors.Wrap(err, "[UserMFARepository - ClaimChallenge] Error while removing mfa challenge attempts")
+	}
+
+	return challenge, nil
+}
+
+// mfaChallengeKey builds cache key from hashed token, so the plain token is never stored in cache
+func mfaChallengeKey(token string) string {
+	return fmt.Sprintf(mfaChallengeKeyPrefix, tools.SHA256Hex(token))
+}
+
+// mfaChallengeAttemptsKey builds cache key of attempts of mfa challenge from hashed token
+func mfaChallengeAttemptsKey(token string) string {
+	return fmt.Sprintf(mfaChallengeAttemptsKeyPrefix, tools.SHA256Hex(token))
+}
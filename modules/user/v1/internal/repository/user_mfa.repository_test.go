@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+func TestUserMFARepository(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("step is used only once", func(t *testing.T) {
+		repo := repository.NewUserMFARepository(nil, newFakeCache())
+		ctx := context.Background()
+
+		unused, err := repo.UseStep(ctx, userID, 42, time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, unused)
+
+		unused, err = repo.UseStep(ctx, userID, 42, time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, unused)
+
+		unused, err = repo.UseStep(ctx, userID, 43, time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, unused)
+	})
+
+	t.Run("challenge is claimed only once", func(t *testing.T) {
+		repo := repository.NewUserMFARepository(nil, newFakeCache())
+		ctx := context.Background()
+		challenge := entity.NewMFAChallenge("token", userID, time.Minute)
+		assert.Nil(t, repo.SaveChallenge(ctx, challenge))
+
+		claimed, err := repo.ClaimChallenge(ctx, challenge.Token)
+		assert.Nil(t, err)
+		assert.Equal(t, userID, claimed.UserID)
+
+		_, err = repo.ClaimChallenge(ctx, challenge.Token)
+		assert.ErrorIs(t, err, repository.ErrMFAChallengeClaimed)
+
+		_, err = repo.FindChallenge(ctx, challenge.Token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("attempts are counted per challenge", func(t *testing.T) {
+		repo := repository.NewUserMFARepository(nil, newFakeCache())
+		ctx := context.Background()
+		challenge := entity.NewMFAChallenge("token", userID, time.Minute)
+		other := entity.NewMFAChallenge("other token", userID, time.Minute)
+
+		for i := 1; i <= 3; i++ {
+			attempts, err := repo.CountChallengeAttempt(ctx, challenge)
+			assert.Nil(t, err)
+			assert.Equal(t, i, attempts)
+		}
+
+		attempts, err := repo.CountChallengeAttempt(ctx, other)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/common/tools"
+)
+
+const (
+	// passwordResetKeyPrefix is the cache key prefix for password reset token
+	passwordResetKeyPrefix = "users:password-reset:%s"
+)
+
+// UserPasswordResetRepository defines dependencies for password reset token
+type UserPasswordResetRepository struct {
+	cache cache.ContextCacheable
+}
+
+// NewUserPasswordResetRepository creates a new UserPasswordReset repository
+func NewUserPasswordResetRepository(
+	cache cache.ContextCacheable,
+) *UserPasswordResetRepository {
+	return &UserPasswordResetRepository{
+		cache: cache,
+	}
+}
+
+// UserPasswordResetRepositoryUseCase is use case for storing password reset token
+type UserPasswordResetRepositoryUseCase interface {
+	// Save stores password reset token for user with time-to-live
+	Save(ctx context.Context, token string, userID uuid.UUID, ttl time.Duration) error
+	// Find finds user id by password reset token without removing the token
+	Find(ctx context.Context, token string) (uuid.UUID, error)
+	// Consume finds user id by password reset token and removes the token so it can only be used once
+	Consume(ctx context.Context, token string) (uuid.UUID, error)
+}
+
+// Save stores password reset token for user with time-to-live
+func (r *UserPasswordResetRepository) Save(ctx context.Context, token string, userID uuid.UUID, ttl time.Duration) error {
+	if err := r.cache.Set(ctx, passwordResetKey(token), userID.String(), int(ttl.Seconds())); err != nil {
+		return errors.Wrap(err, "[UserPasswordResetRepository - Save] Error while saving password reset token")
+	}
+
+	return nil
+}
+
+// Find finds user id by password reset token without removing the token
+func (r *UserPasswordResetRepository) Find(ctx context.Context, token string) (uuid.UUID, error) {
+	data, err := r.cache.Get(ctx, passwordResetKey(token))
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "[UserPasswordResetRepository - Find] Error while finding password reset token")
+	}
+
+	var userID string
+	if err := json.Unmarshal(data, &userID); err != nil {
+		return uuid.Nil, errors.Wrap(err, "[UserPasswordResetRepository - Find] Error while decoding password reset token")
+	}
+
+	return uuid.Parse(userID)
+}
+
+// Consume finds user id by password reset token and removes the token so it can only be used once.
+// The token is read and removed at once, so concurrent password changes with the same token can only consume it once.
+func (r *UserPasswordResetRepository) Consume(ctx context.Context, token string) (uuid.UUID, error) {
+	data, err := r.cache.GetDel(ctx, passwordResetKey(token))
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "[UserPasswordResetRepository - Consume] Error while consuming password reset token")
+	}
+
+	var userID string
+	if err := json.Unmarshal(data, &userID); err != nil {
+		return uuid.Nil, errors.Wrap(err, "[UserPasswordResetRepository - Consume] Error while decoding password reset token")
+	}
+
+	return uuid.Parse(userID)
+}
+
+// passwordResetKey builds cache key from hashed token, so the plain token is never stored in cache
+func passwordResetKey(token string) string {
+	return fmt.Sprintf(passwordResetKeyPrefix, tools.SHA256Hex(token))
+}
@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+func TestUserPasswordResetRepository(t *testing.T) {
+	t.Run("token is consumed only once", func(t *testing.T) {
+		repo := repository.NewUserPasswordResetRepository(newFakeCache())
+		ctx := context.Background()
+		userID := uuid.New()
+		assert.Nil(t, repo.Save(ctx, "token", userID, time.Minute))
+
+		found, err := repo.Find(ctx, "token")
+		assert.Nil(t, err)
+		assert.Equal(t, userID, found)
+
+		consumed, err := repo.Consume(ctx, "token")
+		assert.Nil(t, err)
+		assert.Equal(t, userID, consumed)
+
+		_, err = repo.Consume(ctx, "token")
+		assert.NotNil(t, err)
+
+		_, err = repo.Find(ctx, "token")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+const (
+	// phoneOTPKeyPrefix is the cache key prefix for pending phone number login code
+	phoneOTPKeyPrefix = "users:phone-otp:%s"
+	// phoneOTPAttemptsKeyPrefix is the cache key prefix for attempts of pending phone number login code
+	phoneOTPAttemptsKeyPrefix = "users:phone-otp-attempts:%s"
+	// phoneOTPCooldownKeyPrefix is the cache key prefix for phone number login code cooldown
+	phoneOTPCooldownKeyPrefix = "users:phone-otp-cooldown:%s"
+	// phoneOTPQuotaKeyPrefix is the cache key prefix for phone number login code quota
+	phoneOTPQuotaKeyPrefix = "users:phone-otp-quota:%s"
+)
+
+// ErrPhoneOTPConsumed is returned when pending login code has been used or replaced since it was read
+var ErrPhoneOTPConsumed = errors.New("phone otp has been used or replaced")
+
+// UserPhoneOTPRepository defines dependencies for phone number login code
+type UserPhoneOTPRepository struct {
+	cache cache.ContextCacheable
+}
+
+// NewUserPhoneOTPRepository creates a new UserPhoneOTP repository
+func NewUserPhoneOTPRepository(
+	cache cache.ContextCacheable,
+) *UserPhoneOTPRepository {
+	return &UserPhoneOTPRepository{
+		cache: cache,
+	}
+}
+
+// UserPhoneOTPRepositoryUseCase is use case for storing phone number login code.
+// Phone numbers must be normalised with entity.NormalizePhoneNumber, and every key is built
+// from hashed phone number, so phone numbers are never stored in cache.
+type UserPhoneOTPRepositoryUseCase interface {
+	// Save stores pending login code of phone number until it expires, resetting its attempts
+	Save(ctx context.Context, phoneNumber string, otp *entity.OTP) error
+	// Find finds pending login code of phone number
+	Find(ctx context.Context, phoneNumber string) (*entity.OTP, error)
+	// CountAttempt atomically counts an attempt of pending login code of phone number and returns the count of attempts
+	CountAttempt(ctx context.Context, phoneNumber string, otp *entity.OTP) (int, error)
+	// Consume removes pending login code of phone number, as long as it is still the code with the given hash
+	Consume(ctx context.Context, phoneNumber string, codeHash string) error
+	// StartCooldown marks that login code has just been requested for phone number,
+	// it returns false when phone number is already in cooldown
+	StartCooldown(ctx context.Context, phoneNumber string, ttl time.Duration) (bool, error)
+	// CountRequest counts a login code request for phone number and returns the quota of the current window
+	CountRequest(ctx context.Context, phoneNumber string, window time.Duration) (*entity.OTPQuota, error)
+}
+
+// Save stores pending login code of phone number until it expires, resetting its attempts
+func (r *UserPhoneOTPRepository) Save(ctx context.Context, phoneNumber string, otp *entity.OTP) error {
+	if err := r.cache.SetWithExpireAt(ctx, phoneOTPKey(phoneOTPKeyPrefix, phoneNumber), otp, otp.ExpiresAt); err != nil {
+		return errors.Wrap(err, "[UserPhoneOTPRepository - Save] Error while saving phone otp")
+	}
+
+	if err := r.cache.Remove(ctx, phoneOTPKey(phoneOTPAttemptsKeyPrefix, phoneNumber)); err != nil {
+		return errors.Wrap(err, "[UserPhoneOTPRepository - Save] Error while resetting phone otp attempts")
+	}
+
+	return nil
+}
+
+// Find finds pending login code of phone number
+func (r *UserPhoneOTPRepository) Find(ctx context.Context, phoneNumber string) (*entity.OTP, error) {
+	data, err := r.cache.Get(ctx, phoneOTPKey(phoneOTPKeyPrefix, phoneNumber))
+	if err != nil {
+		return nil, errors.Wrap(err, "[UserPhoneOTPRepository - Find] Error while finding phone otp")
+	}
+
+	otp := new(entity.OTP)
+	if err := json.Unmarshal(data, otp); err != nil {
+		return nil, errors.Wrap(err, "[UserPhoneOTPRepository - Find] Error while decoding phone otp")
+	}
+
+	return otp, nil
+}
+
+// CountAttempt atomically counts an attempt of pending login code of phone number and returns the count of attempts,
+// so concurrent attempts can never exceed the maximum attempts. Attempts expire together with the code.
+func (r *UserPhoneOTPRepository) CountAttempt(ctx context.Context, phoneNumber string, otp *entity.OTP) (int, error) {
+	attempts, err := r.cache.Incr(ctx, phoneOTPKey(phoneOTPAttemptsKeyPrefix, phoneNumber), ttlUntil(otp.ExpiresAt))
+	if err != nil {
+		return 0, errors.Wrap(err, "[UserPhoneOTPRepository - CountAttempt] Error while counting phone otp attempt")
+	}
+
+	return int(attempts), nil
+}
+
+// Consume removes pending login code of phone number, as long as it is still the code with the given hash.
+// The code is read and removed at once, so concurrent logins with the same code can only consume it once,
+// the others get ErrPhoneOTPConsumed.
+func (r *UserPhoneOTPRepository) Consume(ctx context.Context, phoneNumber string, codeHash string) error {
+	data, err := r.cache.GetDel(ctx, phoneOTPKey(phoneOTPKeyPrefix, phoneNumber))
+	if err != nil {
+		// a missing code can not be told from an unavailable cache, either way the code can not be consumed
+		return errors.Wrapf(ErrPhoneOTPConsumed, "[UserPhoneOTPRepository - Consume] Error while consuming phone otp: %v", err)
+	}
+
+	otp := new(entity.OTP)
+	if err := json.Unmarshal(data, otp); err != nil {
+		return errors.Wrap(err, "[UserPhoneOTPRepository - Consume] Error while decoding phone otp")
+	}
+
+	if otp.CodeHash != codeHash {
+		return errors.Wrap(ErrPhoneOTPConsumed, "[UserPhoneOTPRepository - Consume] Error while consuming replaced phone otp")
+	}
+
+	if err := r.cache.Remove(ctx, phoneOTPKey(phoneOTPAttemptsKeyPrefix, phoneNumber)); err != nil {
+		return errors.Wrap(err, "[UserPhoneOTPRepository - Consume] Error while removing phone otp attempts")
+	}
+
+	return nil
+}
+
+// StartCooldown marks that login code has just been requested for phone number, it returns false when
+// phone number is already in cooldown. The cooldown is checked and started at once, so concurrent requests start it once.
+func (r *UserPhoneOTPRepository) StartCooldown(ctx context.Context, phoneNumber string, ttl time.Duration) (bool, error) {
+	started, err := r.cache.SetNX(ctx, phoneOTPKey(phoneOTPCooldownKeyPrefix, phoneNumber), true, int(ttl.Seconds()))
+	if err != nil {
+		return false, errors.Wrap(err, "[UserPhoneOTPRepository - StartCooldown] Error while saving phone otp cooldown")
+	}
+
+	return started, nil
+}
+
+// CountRequest counts a login code request for phone number and returns the quota of the current window.
+// Requests are counted atomically, a new window starts with the first request after the previous window has expired.
+func (r *UserPhoneOTPRepository) CountRequest(ctx context.Context, phoneNumber string, window time.Duration) (*entity.OTPQuota, error) {
+	count, err := r.cache.Incr(ctx, phoneOTPKey(phoneOTPQuotaKeyPrefix, phoneNumber), int(window.Seconds()))
+	if err != nil {
+		return nil, errors.Wrap(err, "[UserPhoneOTPRepository - CountRequest] Error while counting phone otp request")
+	}
+
+	return &entity.OTPQuota{Count: int(count)}, nil
+}
+
+// phoneOTPKey builds cache key with the given prefix from hashed phone number
+func phoneOTPKey(prefix string, phoneNumber string) string {
+	return fmt.Sprintf(prefix, tools.SHA256Hex(phoneNumber))
+}
+
+// ttlUntil returns the seconds left until expireAt, rounded up and at least one second
+func ttlUntil(expireAt time.Time) int {
+	ttl := int(math.Ceil(time.Until(expireAt).Seconds()))
+	if ttl < 1 {
+		return 1
+	}
+
+	return ttl
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+func TestUserPhoneOTPRepository(t *testing.T) {
+	phoneNumber := "+62895346419497"
+
+	t.Run("code is consumed only once", func(t *testing.T) {
+		repo := repository.NewUserPhoneOTPRepository(newFakeCache())
+		ctx := context.Background()
+		otp := entity.NewOTP("hash", time.Minute)
+		assert.Nil(t, repo.Save(ctx, phoneNumber, otp))
+
+		attempts, err := repo.CountAttempt(ctx, phoneNumber, otp)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, attempts)
+
+		assert.Nil(t, repo.Consume(ctx, phoneNumber, otp.CodeHash))
+		assert.ErrorIs(t, repo.Consume(ctx, phoneNumber, otp.CodeHash), repository.ErrPhoneOTPConsumed)
+
+		_, err = repo.Find(ctx, phoneNumber)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("replaced code is not consumed", func(t *testing.T) {
+		repo := repository.NewUserPhoneOTPRepository(newFakeCache())
+		ctx := context.Background()
+		assert.Nil(t, repo.Save(ctx, phoneNumber, entity.NewOTP("new hash", time.Minute)))
+
+		assert.ErrorIs(t, repo.Consume(ctx, phoneNumber, "old hash"), repository.ErrPhoneOTPConsumed)
+	})
+
+	t.Run("attempts are counted until a new code is saved", func(t *testing.T) {
+		repo := repository.NewUserPhoneOTPRepository(newFakeCache())
+		ctx := context.Background()
+		otp := entity.NewOTP("hash", time.Minute)
+		assert.Nil(t, repo.Save(ctx, phoneNumber, otp))
+
+		for i := 1; i <= 3; i++ {
+			attempts, err := repo.CountAttempt(ctx, phoneNumber, otp)
+			assert.Nil(t, err)
+			assert.Equal(t, i, attempts)
+		}
+
+		assert.Nil(t, repo.Save(ctx, phoneNumber, entity.NewOTP("new hash", time.Minute)))
+		attempts, err := repo.CountAttempt(ctx, phoneNumber, otp)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("cooldown and quota are counted atomically", func(t *testing.T) {
+		repo := repository.NewUserPhoneOTPRepository(newFakeCache())
+		ctx := context.Background()
+
+		started, err := repo.StartCooldown(ctx, phoneNumber, time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, started)
+
+		started, err = repo.StartCooldown(ctx, phoneNumber, time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, started)
+
+		for i := 1; i <= 3; i++ {
+			quota, err := repo.CountRequest(ctx, phoneNumber, time.Hour)
+			assert.Nil(t, err)
+			assert.Equal(t, i, quota.Count)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// ErrRefreshTokenAlreadyRotated is returned when refresh token was rotated or revoked by another request
+var ErrRefreshTokenAlreadyRotated = errors.New("refresh token has already been rotated")
+
+// UserRefreshTokenRepository defines dependencies for refresh token
+type UserRefreshTokenRepository struct {
+	db    *gorm.DB
+	cache cache.ContextCacheable
+}
+
+// NewUserRefreshTokenRepository creates a new UserRefreshToken repository
+func NewUserRefreshTokenRepository(
+	db *gorm.DB,
+	cache cache.ContextCacheable,
+) *UserRefreshTokenRepository {
+	return &UserRefreshTokenRepository{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// UserRefreshTokenRepositoryUseCase is use case for refresh token table
+type UserRefreshTokenRepositoryUseCase interface {
+	// Create creates refresh token
+	Create(ctx context.Context, token *entity.RefreshToken) error
+	// FindByTokenHash finds refresh token by its hash
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	// Rotate marks current refresh token as used and creates the next one in the same family
+	Rotate(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) error
+	// RevokeFamily revokes every refresh token in a family
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	// RevokeByUserID revokes every refresh token of a user
+	RevokeByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// Create creates refresh token
+func (r *UserRefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return errors.Wrap(err, "[UserRefreshTokenRepository - Create] Error while creating refresh token data")
+	}
+
+	return nil
+}
+
+// FindByTokenHash finds refresh token by its hash
+func (r *UserRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var result *entity.RefreshToken
+	if err := r.db.WithContext(ctx).Model(&entity.RefreshToken{}).Where("token_hash = ?", tokenHash).First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserRefreshTokenRepository - FindByTokenHash] Error while finding refresh token data")
+	}
+
+	return result, nil
+}
+
+// Rotate marks current refresh token as used and creates the next one in the same family.
+// The current token is only updated when it is still unused and not revoked,
+// so two concurrent rotations of the same token can not both succeed.
+func (r *UserRefreshTokenRepository) Rotate(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) error {
+	now := time.Now()
+	err := r.db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&entity.RefreshToken{}).
+				Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+				UpdateColumns(map[string]interface{}{
+					"used_at":     now,
+					"replaced_by": next.ID,
+					"updated_at":  now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrRefreshTokenAlreadyRotated
+			}
+
+			return tx.Create(next).Error
+		})
+	if err != nil {
+		return errors.Wrap(err, "[UserRefreshTokenRepository - Rotate] Error while rotating refresh token data")
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every refresh token in a family
+func (r *UserRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		UpdateColumns(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserRefreshTokenRepository - RevokeFamily] Error while revoking refresh token family")
+	}
+
+	return nil
+}
+
+// RevokeByUserID revokes every refresh token of a user
+func (r *UserRefreshTokenRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumns(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserRefreshTokenRepository - RevokeByUserID] Error while revoking refresh tokens of user")
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"grpc-starter/common/cache"
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// UserRoleRepository defines dependencies for user roles
+type UserRoleRepository struct {
+	db    *gorm.DB
+	cache cache.ContextCacheable
+}
+
+// NewUserRoleRepository creates a new UserRole repository
+func NewUserRoleRepository(
+	db *gorm.DB,
+	cache cache.ContextCacheable,
+) *UserRoleRepository {
+	return &UserRoleRepository{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// UserRoleRepositoryUseCase is use case for roles and permissions tables
+type UserRoleRepositoryUseCase interface {
+	// FindRoleByName finds role by its name
+	FindRoleByName(ctx context.Context, name string) (*entity.Role, error)
+	// FindRoleNamesByUserID finds names of roles assigned to a user
+	FindRoleNamesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// FindPermissionNamesByUserID finds names of permissions granted to a user through its roles
+	FindPermissionNamesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// Assign assigns role to a user, assigning an already assigned role does nothing
+	Assign(ctx context.Context, userRole *entity.UserRole) error
+	// Revoke revokes role from a user
+	Revoke(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+}
+
+// FindRoleByName finds role by its name
+func (r *UserRoleRepository) FindRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	var result *entity.Role
+	if err := r.db.WithContext(ctx).Model(&entity.Role{}).Where("name = ?", name).First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserRoleRepository - FindRoleByName] Error while finding role data")
+	}
+
+	return result, nil
+}
+
+// FindRoleNamesByUserID finds names of roles assigned to a user
+func (r *UserRoleRepository) FindRoleNamesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var names []string
+	if err := r.db.
+		WithContext(ctx).
+		Table(entity.RoleTableName+" AS r").
+		Joins("JOIN "+entity.UserRoleTableName+" AS ur ON ur.role_id = r.id").
+		Where("ur.user_id = ? AND r.deleted_at IS NULL", userID).
+		Order("r.name").
+		Pluck("r.name", &names).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserRoleRepository - FindRoleNamesByUserID] Error while finding role names of user")
+	}
+
+	return names, nil
+}
+
+// FindPermissionNamesByUserID finds names of permissions granted to a user through its roles
+func (r *UserRoleRepository) FindPermissionNamesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var names []string
+	if err := r.db.
+		WithContext(ctx).
+		Table(entity.PermissionTableName+" AS p").
+		Distinct("p.name").
+		Joins("JOIN "+entity.RolePermissionTableName+" AS rp ON rp.permission_id = p.id").
+		Joins("JOIN "+entity.RoleTableName+" AS r ON r.id = rp.role_id AND r.deleted_at IS NULL").
+		Joins("JOIN "+entity.UserRoleTableName+" AS ur ON ur.role_id = r.id").
+		Where("ur.user_id = ? AND p.deleted_at IS NULL", userID).
+		Order("p.name").
+		Pluck("p.name", &names).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserRoleRepository - FindPermissionNamesByUserID] Error while finding permission names of user")
+	}
+
+	return names, nil
+}
+
+// Assign assigns role to a user, assigning an already assigned role does nothing
+func (r *UserRoleRepository) Assign(ctx context.Context, userRole *entity.UserRole) error {
+	if err := r.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(userRole).Error; err != nil {
+		return errors.Wrap(err, "[UserRoleRepository - Assign] Error while assigning role to user")
+	}
+
+	return nil
+}
+
+// Revoke revokes role from a user
+func (r *UserRoleRepository) Revoke(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	if err := r.db.
+		WithContext(ctx).
+		Where("user_id = ? AND role_id = ?", userID, roleID).
+		Delete(&entity.UserRole{}).Error; err != nil {
+		return errors.Wrap(err, "[UserRoleRepository - Revoke] Error while revoking role from user")
+	}
+
+	return nil
+}
+
+// assignDefaultRole assigns the regular user role to a newly created user within tx
+func assignDefaultRole(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Exec(
+		"INSERT INTO "+entity.UserRoleTableName+" (user_id, role_id, created_at) "+
+			"SELECT ?, id, NOW() FROM "+entity.RoleTableName+" WHERE name = ? "+
+			"ON CONFLICT DO NOTHING",
+		userID, entity.RoleUser,
+	).Error
+}
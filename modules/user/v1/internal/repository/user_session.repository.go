@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"grpc-starter/modules/user/v1/entity"
+)
+
+// UserSessionRepository defines dependencies for user sessions
+type UserSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSessionRepository creates a new UserSession repository
+func NewUserSessionRepository(
+	db *gorm.DB,
+) *UserSessionRepository {
+	return &UserSessionRepository{
+		db: db,
+	}
+}
+
+// UserSessionRepositoryUseCase is use case for user sessions table
+type UserSessionRepositoryUseCase interface {
+	// Create creates session
+	Create(ctx context.Context, session *entity.Session) error
+	// FindByID finds session of a user by its id
+	FindByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.Session, error)
+	// FindActiveByUserID finds sessions of a user that are neither revoked nor expired at given time
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error)
+	// Touch records the latest use of a session and extends its expiration
+	Touch(ctx context.Context, id uuid.UUID, ipAddress string, lastSeenAt time.Time, expiresAt time.Time) error
+	// Revoke revokes a session
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeByUserID revokes every session of a user
+	RevokeByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// Create creates session
+func (r *UserSessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		return errors.Wrap(err, "[UserSessionRepository - Create] Error while creating session data")
+	}
+
+	return nil
+}
+
+// FindByID finds session of a user by its id
+func (r *UserSessionRepository) FindByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.Session, error) {
+	var result *entity.Session
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserSessionRepository - FindByID] Error while finding session data")
+	}
+
+	return result, nil
+}
+
+// FindActiveByUserID finds sessions of a user that are neither revoked nor expired at given time,
+// the most recently used session comes first
+func (r *UserSessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error) {
+	var result []*entity.Session
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&result).Error; err != nil {
+		return nil, errors.Wrap(err, "[UserSessionRepository - FindActiveByUserID] Error while finding session data")
+	}
+
+	return result, nil
+}
+
+// Touch records the latest use of a session and extends its expiration
+func (r *UserSessionRepository) Touch(ctx context.Context, id uuid.UUID, ipAddress string, lastSeenAt time.Time, expiresAt time.Time) error {
+	columns := map[string]interface{}{
+		"last_seen_at": lastSeenAt,
+		"expires_at":   expiresAt,
+		"updated_at":   lastSeenAt,
+	}
+	if ipAddress != "" {
+		columns["ip_address"] = ipAddress
+	}
+
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ?", id).
+		UpdateColumns(columns).Error; err != nil {
+		return errors.Wrap(err, "[UserSessionRepository - Touch] Error while updating session data")
+	}
+
+	return nil
+}
+
+// Revoke revokes a session
+func (r *UserSessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumns(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserSessionRepository - Revoke] Error while revoking session")
+	}
+
+	return nil
+}
+
+// RevokeByUserID revokes every session of a user
+func (r *UserSessionRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumns(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserSessionRepository - RevokeByUserID] Error while revoking sessions of user")
+	}
+
+	return nil
+}
@@ -2,26 +2,30 @@ package repository
 
 import (
 	"context"
-	"log"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 
 	"grpc-starter/common/cache"
+	commonGorm "grpc-starter/common/gorm"
 	"grpc-starter/modules/user/v1/entity"
 )
 
+// ErrUserVersionConflict is returned when user was updated by another request since it was read
+var ErrUserVersionConflict = errors.New("user has been updated by another request")
+
 // UserUpdaterRepository defines dependencies for UserUpdater
 type UserUpdaterRepository struct {
 	db    *gorm.DB
-	cache cache.Cacheable
+	cache cache.ContextCacheable
 }
 
 // NewUserUpdaterRepository creates a new UserUpdater repository
 func NewUserUpdaterRepository(
 	db *gorm.DB,
-	cache cache.Cacheable,
+	cache cache.ContextCacheable,
 ) *UserUpdaterRepository {
 	return &UserUpdaterRepository{
 		db:    db,
@@ -31,32 +35,172 @@ func NewUserUpdaterRepository(
 
 // UserUpdaterRepositoryUseCase is use case for UserUpdaterRepository
 type UserUpdaterRepositoryUseCase interface {
-	// Update updates user
+	// Update updates user as long as it has not been updated since it was read
 	Update(ctx context.Context, user *entity.User) error
+	// Disable disables user
+	Disable(ctx context.Context, refID uuid.UUID) error
+	// Restore enables disabled user and restores soft deleted user
+	Restore(ctx context.Context, refID uuid.UUID) error
+	// MarkEmailVerified marks user email as verified
+	MarkEmailVerified(ctx context.Context, refID uuid.UUID) error
+	// MarkPhoneNumberVerified marks user phone number as verified
+	MarkPhoneNumberVerified(ctx context.Context, refID uuid.UUID) error
+	// UpdatePasswordHash replaces password hash of user with an upgraded hash of the same password,
+	// as long as the password hash is still the verified one
+	UpdatePasswordHash(ctx context.Context, refID uuid.UUID, verifiedHash string, passwordHash string) error
 }
 
-// Update updates user
+// Update updates fields of user that differ from the stored user.
+// The update only succeeds when the stored user still has the version the user was read with,
+// otherwise ErrUserVersionConflict is returned. On success the version of user is incremented.
 func (r *UserUpdaterRepository) Update(ctx context.Context, user *entity.User) error {
-	oldTime := user.UpdatedAt
-	user.UpdatedAt = time.Now()
+	source := new(entity.User)
+	if err := r.db.WithContext(ctx).Where("id = ?", user.ID).First(source).Error; err != nil {
+		return errors.Wrap(err, "[UserUpdaterRepository - Update] Error while finding user data")
+	}
+
+	if source.Version != user.Version {
+		return errors.Wrap(ErrUserVersionConflict, "[UserUpdaterRepository - Update] Error while updating user data")
+	}
+
+	now := time.Now()
+	columns := *source.MapUpdateFrom(user)
+	columns["updated_at"] = now
+	columns["version"] = gorm.Expr("version + 1")
+
+	res := r.db.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ? AND version = ?", user.ID, user.Version).
+		UpdateColumns(columns)
+	if res.Error != nil {
+		return errors.Wrap(commonGorm.TranslateError(res.Error), "[UserUpdaterRepository - Update] Error while updating user data")
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrap(ErrUserVersionConflict, "[UserUpdaterRepository - Update] Error while updating user data")
+	}
+
+	user.UpdatedAt = now
+	user.Version++
+
+	// a changed email also clears a cached miss of the new email
+	invalidateUser(ctx, r.cache, user.ID, source.Email)
+	if user.Email != source.Email {
+		invalidateUser(ctx, r.cache, user.ID, user.Email)
+	}
+
+	return nil
+}
+
+// Disable disables user
+func (r *UserUpdaterRepository) Disable(ctx context.Context, refID uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ?", refID).
+		UpdateColumns(map[string]interface{}{
+			"disabled_at": now,
+			"updated_at":  now,
+			"version":     gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserUpdaterRepository - Disable] Error while disabling user data")
+	}
+
+	invalidateUser(ctx, r.cache, refID, "")
+
+	return nil
+}
+
+// Restore enables disabled user and restores soft deleted user
+func (r *UserUpdaterRepository) Restore(ctx context.Context, refID uuid.UUID) error {
+	if err := r.db.
+		WithContext(ctx).
+		Unscoped().
+		Model(&entity.User{}).
+		Where("id = ?", refID).
+		UpdateColumns(map[string]interface{}{
+			"disabled_at": nil,
+			"deleted_at":  nil,
+			"deleted_by":  nil,
+			"updated_at":  time.Now(),
+			"version":     gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserUpdaterRepository - Restore] Error while restoring user data")
+	}
+
+	// a soft deleted user may be cached as missing by its email as well
+	var emails []string
+	if err := r.db.WithContext(ctx).Unscoped().Model(&entity.User{}).Where("id = ?", refID).Pluck("email", &emails).Error; err != nil {
+		return errors.Wrap(err, "[UserUpdaterRepository - Restore] Error while finding restored user email")
+	}
+	invalidateUser(ctx, r.cache, refID, "")
+	for _, email := range emails {
+		invalidateUser(ctx, r.cache, refID, email)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified marks user email as verified
+func (r *UserUpdaterRepository) MarkEmailVerified(ctx context.Context, refID uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ?", refID).
+		UpdateColumns(map[string]interface{}{
+			"email_verified_at": now,
+			"updated_at":        now,
+			"version":           gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserUpdaterRepository - MarkEmailVerified] Error while marking user email as verified")
+	}
+
+	invalidateUser(ctx, r.cache, refID, "")
+
+	return nil
+}
+
+// MarkPhoneNumberVerified marks user phone number as verified
+func (r *UserUpdaterRepository) MarkPhoneNumberVerified(ctx context.Context, refID uuid.UUID) error {
+	now := time.Now()
 	if err := r.db.
 		WithContext(ctx).
-		Transaction(func(tx *gorm.DB) error {
-			sourceModel := new(entity.User)
-			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&sourceModel, user.ID).Error; err != nil {
-				log.Println("[UserRepository - Update]", err)
-				return err
-			}
-			if err := tx.Model(&entity.User{}).
-				Where(`id`, user.ID).
-				UpdateColumns(sourceModel.MapUpdateFrom(user)).Error; err != nil {
-				log.Println("[UserRepository - Update]", err)
-				return err
-			}
-			return nil
-		}); err != nil {
-		user.UpdatedAt = oldTime
+		Model(&entity.User{}).
+		Where("id = ?", refID).
+		UpdateColumns(map[string]interface{}{
+			"phone_number_verified_at": now,
+			"updated_at":               now,
+			"version":                  gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return errors.Wrap(err, "[UserUpdaterRepository - MarkPhoneNumberVerified] Error while marking user phone number as verified")
 	}
 
+	invalidateUser(ctx, r.cache, refID, "")
+
+	return nil
+}
+
+// UpdatePasswordHash replaces password hash of user with an upgraded hash of the same password.
+// The password itself does not change, so updated_at is kept as it is.
+// The hash is only replaced while it is still the verified one, so a password changed in the meantime is never
+// overwritten by the upgraded hash of the old password; nothing is updated in that case.
+func (r *UserUpdaterRepository) UpdatePasswordHash(ctx context.Context, refID uuid.UUID, verifiedHash string, passwordHash string) error {
+	result := r.db.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ? AND password = ?", refID, verifiedHash).
+		UpdateColumn("password", passwordHash)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "[UserUpdaterRepository - UpdatePasswordHash] Error while updating user password hash")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	invalidateUser(ctx, r.cache, refID, "")
+
 	return nil
 }
@@ -2,16 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
-	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 
 	"grpc-starter/common/config"
-	"grpc-starter/common/constant"
+	commonentity "grpc-starter/common/entity"
 	commonError "grpc-starter/common/errors"
-	commonJwt "grpc-starter/common/jwt"
+	commonGorm "grpc-starter/common/gorm"
+	"grpc-starter/common/hasher"
 	"grpc-starter/modules/user/v1/entity"
 	"grpc-starter/modules/user/v1/internal/repository"
 )
@@ -20,24 +20,36 @@ import (
 type UserCreator struct {
 	cfg                   config.Config
 	userCreatorRepository repository.UserCreatorRepositoryUseCase
+	userTokenSvc          UserTokenUseCase
+	emailVerificationSvc  UserEmailVerificationUseCase
+	passwordPolicySvc     UserPasswordPolicyUseCase
+	passwordHasher        hasher.PasswordHasher
 }
 
 // UserCreatorUseCase is use case for creating existing user
 type UserCreatorUseCase interface {
 	// Create creates user
 	Create(ctx context.Context, user *entity.User) error
-	// Register creates user and send email to user
-	Register(ctx context.Context, username string, email string, password string, phoneNumber string) (*entity.User, string, error)
+	// Register creates user and sends email verification code to user
+	Register(ctx context.Context, username string, email string, password string, phoneNumber string) (*entity.User, *entity.TokenPair, error)
 }
 
 // NewUserCreator constructs new instance of UserCreator
 func NewUserCreator(
 	cfg config.Config,
 	userCreatorRepository repository.UserCreatorRepositoryUseCase,
+	userTokenSvc UserTokenUseCase,
+	emailVerificationSvc UserEmailVerificationUseCase,
+	passwordPolicySvc UserPasswordPolicyUseCase,
+	passwordHasher hasher.PasswordHasher,
 ) *UserCreator {
 	return &UserCreator{
 		cfg:                   cfg,
 		userCreatorRepository: userCreatorRepository,
+		userTokenSvc:          userTokenSvc,
+		emailVerificationSvc:  emailVerificationSvc,
+		passwordPolicySvc:     passwordPolicySvc,
+		passwordHasher:        passwordHasher,
 	}
 }
 
@@ -45,45 +57,67 @@ func NewUserCreator(
 func (svc *UserCreator) Create(ctx context.Context, user *entity.User) error {
 	if err := svc.userCreatorRepository.Create(ctx, user); err != nil {
 		log.Print("[UserCreator - Create] Error while creating user data :", err)
-		return commonError.ErrInternalServerError.Error()
+		return createError(err)
 	}
 
 	return nil
 }
 
-// Register creates user and send email to user returns user and token
-func (svc *UserCreator) Register(ctx context.Context, username string, email string, password string, phoneNumber string) (*entity.User, string, error) {
+// Register checks password against the password policy, creates user and sends email verification code to user,
+// returns user and token pair. The email stays unverified until the code is submitted, failing to send the code does not fail
+// the registration because the user can request a new code.
+func (svc *UserCreator) Register(ctx context.Context, username string, email string, password string, phoneNumber string) (*entity.User, *entity.TokenPair, error) {
+	if err := svc.passwordPolicySvc.Validate(password, username, email); err != nil {
+		return nil, nil, err
+	}
+
+	passwordHash, err := svc.passwordHasher.Hash(password)
+	if err != nil {
+		log.Print("[UserCreator - Register] Error while hashing password :", err)
+		return nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
 	newUser := entity.NewUser(
 		uuid.New(),
 		username,
 		email,
-		password,
+		passwordHash,
 		phoneNumber,
-		"system",
+		commonentity.SystemActor,
 	)
 
 	if err := svc.userCreatorRepository.Create(ctx, newUser); err != nil {
 		log.Print("[UserCreator - Register] Error while creating user data :", err)
-		return nil, "", commonError.ErrInternalServerError.Error()
+		return nil, nil, createError(err)
 	}
 
-	claims := &commonJwt.CustomClaims{
-		ExpiresAt: time.Now().Add(time.Hour * constant.TwentyFourHour * constant.DaysInOneYear).Unix(),
-		ID:        uuid.New().String(),
-		IssuedAt:  time.Now().Unix(),
-		NotBefore: time.Now().Unix(),
-		Subject:   newUser.ID,
-		Issuer:    constant.MobileIssuer,
+	if err := svc.emailVerificationSvc.Send(ctx, newUser); err != nil {
+		log.Print("[UserCreator - Register] Error while sending email verification code :", err)
 	}
 
-	gen := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	token, err := gen.SignedString([]byte(svc.cfg.JWTConfig.SecretKey))
+	token, err := svc.userTokenSvc.Issue(ctx, newUser.ID)
 
 	if err != nil {
 		log.Print("[UserCreator - Register] Error while generating token for user :", err)
-		return nil, "", commonError.ErrInternalServerError.Error()
+		return nil, nil, err
 	}
 
 	return newUser, token, nil
 }
+
+// createError maps failure of creating user, a unique violation becomes already exists error with the conflicting field
+func createError(err error) error {
+	var uniqueErr *commonGorm.UniqueViolationError
+	if !errors.As(err, &uniqueErr) {
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	field, ok := entity.UniqueFieldOf(uniqueErr.Constraint)
+	if !ok {
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return commonError.ErrUserAlreadyExists.WithFieldViolations(
+		commonError.NewFieldViolation(field, field+" sudah digunakan"),
+	)
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"grpc-starter/common/config"
+	commonentity "grpc-starter/common/entity"
 	commonError "grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
 	"grpc-starter/modules/user/v1/internal/repository"
 )
 
@@ -15,33 +19,56 @@ import (
 type UserDeleter struct {
 	cfg                   config.Config
 	userDeleterRepository repository.UserDeleterRepositoryUseCase
+	userTokenSvc          UserTokenUseCase
 }
 
 // UserDeleterUseCase is use case for deleting existing user
 type UserDeleterUseCase interface {
-	// Delete delete user by user id
+	// Delete soft deletes user by user id
 	Delete(ctx context.Context, refID uuid.UUID) error
+	// DeleteAccount deletes user and revokes all of its tokens
+	DeleteAccount(ctx context.Context, userID uuid.UUID) error
 }
 
 // NewUserDeleter constructs new instance of UserDeleter
 func NewUserDeleter(
 	cfg config.Config,
 	userDeleterRepository repository.UserDeleterRepositoryUseCase,
+	userTokenSvc UserTokenUseCase,
 ) *UserDeleter {
 	return &UserDeleter{
 		cfg:                   cfg,
 		userDeleterRepository: userDeleterRepository,
+		userTokenSvc:          userTokenSvc,
 	}
 }
 
-// Delete deletes user
+// Delete soft deletes user, recording the authenticated principal as the one who deleted it.
+// Personal data of the user is erased once the retention period is over.
 func (svc *UserDeleter) Delete(ctx context.Context, refID uuid.UUID) error {
-	err := svc.userDeleterRepository.Delete(ctx, refID)
+	deletedBy := commonentity.SystemActor
+	if principal, ok := commonJwt.PrincipalFromContext(ctx); ok {
+		deletedBy = principal.UserID.String()
+	}
+
+	err := svc.userDeleterRepository.Delete(ctx, refID, deletedBy)
 
 	if err != nil {
 		log.Print("[UserDeleter - Delete] Error while deleting user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commonError.ErrUserNotFound.Error()
+		}
 		return commonError.ErrInternalServerError.Error()
 	}
 
 	return nil
 }
+
+// DeleteAccount deletes user and revokes all of its tokens
+func (svc *UserDeleter) DeleteAccount(ctx context.Context, userID uuid.UUID) error {
+	if err := svc.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	return svc.userTokenSvc.LogoutAll(ctx, userID)
+}
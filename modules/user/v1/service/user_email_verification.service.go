@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/tools"
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+const (
+	// emailVerificationEmailSubject is the subject of email verification email
+	emailVerificationEmailSubject = "Verifikasi Email"
+	// emailVerificationEmailContent is the html content of email verification email
+	emailVerificationEmailContent = `<p>Terima kasih telah mendaftar.</p>
+<p>Kode verifikasi email anda adalah <b>%s</b>. Kode ini berlaku selama %s.</p>
+<p>Abaikan email ini jika anda tidak merasa melakukan pendaftaran.</p>`
+)
+
+// UserEmailVerification responsible for verifying user email
+type UserEmailVerification struct {
+	cfg                             config.Config
+	userFinderRepository            repository.UserFinderRepositoryUseCase
+	userUpdaterRepository           repository.UserUpdaterRepositoryUseCase
+	userEmailVerificationRepository repository.UserEmailVerificationRepositoryUseCase
+}
+
+// UserEmailVerificationUseCase is use case for verifying user email
+type UserEmailVerificationUseCase interface {
+	// Send generates verification code and sends it to user email
+	Send(ctx context.Context, user *entity.User) error
+	// Verify checks verification code and marks user email as verified
+	Verify(ctx context.Context, userID uuid.UUID, code string) error
+	// Resend replaces verification code of user with a new one and sends it to user email
+	Resend(ctx context.Context, userID uuid.UUID) error
+}
+
+// NewUserEmailVerification constructs new instance of UserEmailVerification
+func NewUserEmailVerification(
+	cfg config.Config,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userUpdaterRepository repository.UserUpdaterRepositoryUseCase,
+	userEmailVerificationRepository repository.UserEmailVerificationRepositoryUseCase,
+) *UserEmailVerification {
+	return &UserEmailVerification{
+		cfg:                             cfg,
+		userFinderRepository:            userFinderRepository,
+		userUpdaterRepository:           userUpdaterRepository,
+		userEmailVerificationRepository: userEmailVerificationRepository,
+	}
+}
+
+// Send generates verification code and sends it to user email.
+// The code is only kept as bcrypt hash and the previous code of the user stops working.
+func (svc *UserEmailVerification) Send(ctx context.Context, user *entity.User) error {
+	// the first code is always sent, the cooldown only holds back resends that follow it
+	if _, err := svc.userEmailVerificationRepository.StartCooldown(ctx, user.ID, svc.cfg.EmailVerification.ResendCooldown); err != nil {
+		log.Println("[UserEmailVerification - Send] Error while saving resend cooldown :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return svc.send(ctx, user)
+}
+
+// Verify checks verification code and marks user email as verified.
+// Every attempt is counted before the code is checked, once the attempts are exhausted the user has to request a new code.
+func (svc *UserEmailVerification) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := svc.findUnverified(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	verification, err := svc.userEmailVerificationRepository.Find(ctx, user.ID)
+	if err != nil {
+		log.Println("[UserEmailVerification - Verify] Error while finding verification code :", err)
+		return commonError.ErrInvalidVerificationCode.Error()
+	}
+
+	attempts, err := svc.userEmailVerificationRepository.CountAttempt(ctx, user.ID, verification)
+	if err != nil {
+		log.Println("[UserEmailVerification - Verify] Error while counting verification attempt :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if attempts > svc.cfg.EmailVerification.MaxAttempts {
+		return commonError.ErrTooManyVerificationAttempts.Error()
+	}
+
+	if !tools.BcryptVerifyHash(verification.CodeHash, code) {
+		return commonError.ErrInvalidVerificationCode.Error()
+	}
+
+	if err := svc.userUpdaterRepository.MarkEmailVerified(ctx, user.ID); err != nil {
+		log.Println("[UserEmailVerification - Verify] Error while marking user email as verified :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.userEmailVerificationRepository.Remove(ctx, user.ID); err != nil {
+		log.Println("[UserEmailVerification - Verify] Error while removing verification code :", err)
+	}
+
+	return nil
+}
+
+// Resend replaces verification code of user with a new one and sends it to user email.
+// The cooldown is started before the code is replaced, so concurrent resends only send one code.
+func (svc *UserEmailVerification) Resend(ctx context.Context, userID uuid.UUID) error {
+	user, err := svc.findUnverified(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	started, err := svc.userEmailVerificationRepository.StartCooldown(ctx, user.ID, svc.cfg.EmailVerification.ResendCooldown)
+	if err != nil {
+		log.Println("[UserEmailVerification - Resend] Error while saving resend cooldown :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if !started {
+		return commonError.ErrVerificationResendTooSoon.Error()
+	}
+
+	return svc.send(ctx, user)
+}
+
+// send generates verification code, replacing the previous code of user, and sends it to user email
+func (svc *UserEmailVerification) send(ctx context.Context, user *entity.User) error {
+	code, codeHash, err := tools.GenerateOTP()
+	if err != nil {
+		log.Println("[UserEmailVerification - send] Error while generating verification code :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	verification := entity.NewOTP(codeHash, svc.cfg.EmailVerification.CodeTTL)
+	if err := svc.userEmailVerificationRepository.Save(ctx, user.ID, verification); err != nil {
+		log.Println("[UserEmailVerification - send] Error while saving verification code :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	payload := notificationEntity.NewSecretEmailPayload(
+		user.Email,
+		emailVerificationEmailSubject,
+		fmt.Sprintf(emailVerificationEmailContent, code, svc.cfg.EmailVerification.CodeTTL),
+		entity.EmailCategoryEmailVerification,
+	)
+
+	if err := tools.SendTopic(ctx, svc.cfg, notificationEntity.SendEmailTopicName, payload); err != nil {
+		log.Println("[UserEmailVerification - send] Error while publishing verification email :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return nil
+}
+
+// findUnverified finds user whose email is not verified yet
+func (svc *UserEmailVerification) findUnverified(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := svc.userFinderRepository.FindByID(ctx, userID)
+	if err != nil {
+		log.Println("[UserEmailVerification - findUnverified] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if user.IsEmailVerified() {
+		return nil, commonError.ErrEmailAlreadyVerified.Error()
+	}
+
+	return user, nil
+}
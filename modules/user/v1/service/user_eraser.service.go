@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+// UserEraser responsible for erasing personal data of deleted users
+type UserEraser struct {
+	cfg                  config.Config
+	userEraserRepository repository.UserEraserRepositoryUseCase
+}
+
+// UserEraserUseCase is use case for erasing personal data of deleted users
+type UserEraserUseCase interface {
+	// EraseDeleted erases a batch of users deleted longer than the retention period and returns how many were erased
+	EraseDeleted(ctx context.Context) (int, error)
+}
+
+// NewUserEraser constructs new instance of UserEraser
+func NewUserEraser(
+	cfg config.Config,
+	userEraserRepository repository.UserEraserRepositoryUseCase,
+) *UserEraser {
+	return &UserEraser{
+		cfg:                  cfg,
+		userEraserRepository: userEraserRepository,
+	}
+}
+
+// EraseDeleted erases a batch of users deleted longer than the retention period and returns how many were erased.
+// A user erased by another job in the meantime is skipped, a user failing to be erased is marked
+// and left out of the following batches until the retry delay has passed.
+func (svc *UserEraser) EraseDeleted(ctx context.Context) (int, error) {
+	now := time.Now()
+	deletedBefore := now.Add(-svc.cfg.AccountErasure.RetentionPeriod)
+	failedBefore := now.Add(-svc.cfg.AccountErasure.RetryDelay)
+
+	users, err := svc.userEraserRepository.FindErasable(ctx, deletedBefore, failedBefore, svc.cfg.AccountErasure.BatchSize)
+	if err != nil {
+		log.Println("[UserEraser - EraseDeleted] Error while finding erasable users :", err)
+		return 0, commonError.ErrInternalServerError.Error()
+	}
+
+	erased := 0
+	for _, user := range users {
+		if err := svc.userEraserRepository.Erase(ctx, user); err != nil {
+			if errors.Is(err, repository.ErrUserAlreadyErased) {
+				continue
+			}
+			// the remaining users are still erased, the failed one is retried after the retry delay
+			log.Println("[UserEraser - EraseDeleted] Error while erasing user :", user.ID, err)
+			if err := svc.userEraserRepository.MarkEraseFailed(ctx, user); err != nil {
+				log.Println("[UserEraser - EraseDeleted] Error while marking user erasure as failed :", user.ID, err)
+			}
+			continue
+		}
+		erased++
+	}
+
+	return erased, nil
+}
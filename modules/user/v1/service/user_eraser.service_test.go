@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+	"grpc-starter/modules/user/v1/service"
+)
+
+// fakeUserEraserRepository serves erasable users and answers erasures with the error set for the user
+type fakeUserEraserRepository struct {
+	users         []*entity.User
+	findErr       error
+	eraseErrs     map[uuid.UUID]error
+	erased        []uuid.UUID
+	failed        []uuid.UUID
+	deletedBefore time.Time
+	failedBefore  time.Time
+	limit         int
+}
+
+func (r *fakeUserEraserRepository) FindErasable(ctx context.Context, deletedBefore time.Time, failedBefore time.Time, limit int) ([]*entity.User, error) {
+	r.deletedBefore, r.failedBefore, r.limit = deletedBefore, failedBefore, limit
+	return r.users, r.findErr
+}
+
+func (r *fakeUserEraserRepository) Erase(ctx context.Context, user *entity.User) error {
+	if err := r.eraseErrs[user.ID]; err != nil {
+		return err
+	}
+
+	r.erased = append(r.erased, user.ID)
+	return nil
+}
+
+func (r *fakeUserEraserRepository) MarkEraseFailed(ctx context.Context, user *entity.User) error {
+	r.failed = append(r.failed, user.ID)
+	return nil
+}
+
+func TestUserEraser_EraseDeleted(t *testing.T) {
+	cfg := config.Config{AccountErasure: config.AccountErasure{
+		RetentionPeriod: 720 * time.Hour,
+		BatchSize:       10,
+		RetryDelay:      24 * time.Hour,
+	}}
+
+	t.Run("failed user is marked while the rest of the batch is erased", func(t *testing.T) {
+		erasable, alreadyErased, failing := &entity.User{ID: uuid.New()}, &entity.User{ID: uuid.New()}, &entity.User{ID: uuid.New()}
+		repo := &fakeUserEraserRepository{
+			users: []*entity.User{failing, alreadyErased, erasable},
+			eraseErrs: map[uuid.UUID]error{
+				failing.ID:       errors.New("deadlock detected"),
+				alreadyErased.ID: repository.ErrUserAlreadyErased,
+			},
+		}
+
+		erased, err := service.NewUserEraser(cfg, repo).EraseDeleted(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, erased)
+		assert.Equal(t, []uuid.UUID{erasable.ID}, repo.erased)
+		assert.Equal(t, []uuid.UUID{failing.ID}, repo.failed)
+	})
+
+	t.Run("users are found by retention period and retry delay", func(t *testing.T) {
+		repo := &fakeUserEraserRepository{}
+		now := time.Now()
+
+		_, err := service.NewUserEraser(cfg, repo).EraseDeleted(context.Background())
+		assert.Nil(t, err)
+		assert.WithinDuration(t, now.Add(-cfg.AccountErasure.RetentionPeriod), repo.deletedBefore, time.Second)
+		assert.WithinDuration(t, now.Add(-cfg.AccountErasure.RetryDelay), repo.failedBefore, time.Second)
+		assert.Equal(t, cfg.AccountErasure.BatchSize, repo.limit)
+	})
+
+	t.Run("fail to erase when users can not be found", func(t *testing.T) {
+		repo := &fakeUserEraserRepository{findErr: errors.New("connection refused")}
+
+		_, err := service.NewUserEraser(cfg, repo).EraseDeleted(context.Background())
+		assert.EqualError(t, err, commonError.ErrInternalServerError.Error().Error())
+	})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+// UserExporter responsible for exporting personal data of users
+type UserExporter struct {
+	cfg                    config.Config
+	userFinderRepository   repository.UserFinderRepositoryUseCase
+	userExporterRepository repository.UserExporterRepositoryUseCase
+}
+
+// UserExporterUseCase is use case for exporting personal data of users
+type UserExporterUseCase interface {
+	// Export collects personal data of a user kept across modules
+	Export(ctx context.Context, userID uuid.UUID) (*entity.DataExport, error)
+}
+
+// NewUserExporter constructs new instance of UserExporter
+func NewUserExporter(
+	cfg config.Config,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userExporterRepository repository.UserExporterRepositoryUseCase,
+) *UserExporter {
+	return &UserExporter{
+		cfg:                    cfg,
+		userFinderRepository:   userFinderRepository,
+		userExporterRepository: userExporterRepository,
+	}
+}
+
+// Export collects personal data of a user kept across modules
+func (svc *UserExporter) Export(ctx context.Context, userID uuid.UUID) (*entity.DataExport, error) {
+	user, err := svc.userFinderRepository.FindByID(ctx, userID)
+	if err != nil {
+		log.Println("[UserExporter - Export] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	identities, err := svc.userExporterRepository.FindIdentities(ctx, userID)
+	if err != nil {
+		log.Println("[UserExporter - Export] Error while finding user identities :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	sessions, err := svc.userExporterRepository.FindSessions(ctx, userID)
+	if err != nil {
+		log.Println("[UserExporter - Export] Error while finding sessions :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	emailsSent, err := svc.userExporterRepository.FindEmailsSent(ctx, user.Email)
+	if err != nil {
+		log.Println("[UserExporter - Export] Error while finding emails sent :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	smsSent, err := svc.findSMSSent(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewDataExport(user, identities, sessions, emailsSent, smsSent), nil
+}
+
+// findSMSSent finds sms sent to the phone number of user, if any
+func (svc *UserExporter) findSMSSent(ctx context.Context, user *entity.User) ([]*notificationEntity.SMSSent, error) {
+	if !user.PhoneNumber.Valid {
+		return nil, nil
+	}
+
+	smsSent, err := svc.userExporterRepository.FindSMSSent(ctx, user.PhoneNumber.String)
+	if err != nil {
+		log.Println("[UserExporter - findSMSSent] Error while finding sms sent :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return smsSent, nil
+}
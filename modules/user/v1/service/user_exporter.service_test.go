@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+	"grpc-starter/modules/user/v1/service"
+)
+
+// fakeUserFinderRepository finds users by id from a map, the methods it does not override are not expected to be called
+type fakeUserFinderRepository struct {
+	repository.UserFinderRepositoryUseCase
+	users map[uuid.UUID]*entity.User
+	err   error
+}
+
+func (r *fakeUserFinderRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+// fakeUserExporterRepository serves rows of a single user, recording the phone numbers sms are looked up for
+type fakeUserExporterRepository struct {
+	identities   []*entity.UserIdentity
+	sessions     []*entity.Session
+	emailsSent   []*notificationEntity.EmailSent
+	smsSent      []*notificationEntity.SMSSent
+	smsLookups   []string
+	sessionsErr  error
+	emailsSentTo []string
+}
+
+func (r *fakeUserExporterRepository) FindIdentities(ctx context.Context, userID uuid.UUID) ([]*entity.UserIdentity, error) {
+	return r.identities, nil
+}
+
+func (r *fakeUserExporterRepository) FindSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error) {
+	return r.sessions, r.sessionsErr
+}
+
+func (r *fakeUserExporterRepository) FindEmailsSent(ctx context.Context, email string) ([]*notificationEntity.EmailSent, error) {
+	r.emailsSentTo = append(r.emailsSentTo, email)
+	return r.emailsSent, nil
+}
+
+func (r *fakeUserExporterRepository) FindSMSSent(ctx context.Context, phoneNumber string) ([]*notificationEntity.SMSSent, error) {
+	r.smsLookups = append(r.smsLookups, phoneNumber)
+	return r.smsSent, nil
+}
+
+func TestUserExporter_Export(t *testing.T) {
+	user := &entity.User{
+		ID:          uuid.New(),
+		Email:       "user@starter.com",
+		Password:    "hash",
+		PhoneNumber: sql.NullString{String: "+62895346419497", Valid: true},
+	}
+
+	t.Run("personal data kept across modules is exported without secrets", func(t *testing.T) {
+		exporterRepo := &fakeUserExporterRepository{
+			identities: []*entity.UserIdentity{{Provider: "google", Subject: "subject-1"}},
+			sessions:   []*entity.Session{{ID: uuid.New()}},
+			emailsSent: []*notificationEntity.EmailSent{{To: user.Email, Subject: "Reset Password", Content: "https://starter.com/reset?token=reset-token"}},
+			smsSent:    []*notificationEntity.SMSSent{{To: user.PhoneNumber.String, Content: "Kode OTP anda 123456"}},
+		}
+		svc := service.NewUserExporter(config.Config{}, &fakeUserFinderRepository{users: map[uuid.UUID]*entity.User{user.ID: user}}, exporterRepo)
+
+		export, err := svc.Export(context.Background(), user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, user.ID.String(), export.User.ID)
+		assert.Equal(t, user.Email, export.User.Email)
+		assert.Len(t, export.Identities, 1)
+		assert.Len(t, export.Sessions, 1)
+		assert.Len(t, export.EmailsSent, 1)
+		assert.Len(t, export.SMSSent, 1)
+		assert.Equal(t, []string{user.Email}, exporterRepo.emailsSentTo)
+		assert.Equal(t, []string{user.PhoneNumber.String}, exporterRepo.smsLookups)
+
+		exported, err := json.Marshal(export)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(exported), "reset-token")
+		assert.NotContains(t, string(exported), "123456")
+		assert.NotContains(t, string(exported), "content")
+	})
+
+	t.Run("sms are not looked up for user without phone number", func(t *testing.T) {
+		withoutPhone := &entity.User{ID: uuid.New(), Email: "nophone@starter.com"}
+		exporterRepo := &fakeUserExporterRepository{}
+		svc := service.NewUserExporter(config.Config{}, &fakeUserFinderRepository{users: map[uuid.UUID]*entity.User{withoutPhone.ID: withoutPhone}}, exporterRepo)
+
+		export, err := svc.Export(context.Background(), withoutPhone.ID)
+		assert.Nil(t, err)
+		assert.Empty(t, export.SMSSent)
+		assert.Empty(t, exporterRepo.smsLookups)
+	})
+
+	t.Run("fail to export unknown user", func(t *testing.T) {
+		svc := service.NewUserExporter(config.Config{}, &fakeUserFinderRepository{}, &fakeUserExporterRepository{})
+
+		_, err := svc.Export(context.Background(), uuid.New())
+		assert.EqualError(t, err, commonError.ErrUserNotFound.Error().Error())
+	})
+
+	t.Run("fail to export when a module can not be read", func(t *testing.T) {
+		exporterRepo := &fakeUserExporterRepository{sessionsErr: errors.New("connection refused")}
+		svc := service.NewUserExporter(config.Config{}, &fakeUserFinderRepository{users: map[uuid.UUID]*entity.User{user.ID: user}}, exporterRepo)
+
+		_, err := svc.Export(context.Background(), user.ID)
+		assert.EqualError(t, err, commonError.ErrInternalServerError.Error().Error())
+	})
+}
@@ -2,44 +2,76 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"grpc-starter/common/config"
-	"grpc-starter/common/constant"
-	"grpc-starter/common/errors"
-	commonJwt "grpc-starter/common/jwt"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/hasher"
 	"grpc-starter/common/tools"
 	"grpc-starter/modules/user/v1/entity"
 	"grpc-starter/modules/user/v1/internal/repository"
 )
 
+const (
+	// dummyPassword is hashed once and compared with the password of an unknown email,
+	// so it takes as long as a wrong password of a registered email
+	dummyPassword = "dummy password of unknown email"
+)
+
 // UserFinder responsible for finding user
 type UserFinder struct {
-	cfg                  config.Config
-	userFinderRepository repository.UserFinderRepositoryUseCase
+	cfg                   config.Config
+	userFinderRepository  repository.UserFinderRepositoryUseCase
+	userUpdaterRepository repository.UserUpdaterRepositoryUseCase
+	userTokenSvc          UserTokenUseCase
+	userMFASvc            UserMFAUseCase
+	userLoginAttemptRepo  repository.UserLoginAttemptRepositoryUseCase
+	passwordHasher        hasher.PasswordHasher
+	dummyPasswordHash     string
 }
 
 // UserFinderUseCase is use case for finding existing user
 type UserFinderUseCase interface {
 	// FindByID finds user by user id
 	FindByID(ctx context.Context, refID uuid.UUID) (*entity.User, error)
-	// Login finds user by email and password and generates token returns user and token
-	Login(ctx context.Context, email string, password string) (*entity.User, string, error)
+	// FindByIDWithDeleted finds user by user id including soft deleted one
+	FindByIDWithDeleted(ctx context.Context, refID uuid.UUID) (*entity.User, error)
+	// FindAll finds users matching filter, starting after the given page token, and returns the next page token
+	FindAll(ctx context.Context, filter *entity.UserFilter, pageToken string) ([]*entity.User, string, error)
+	// Login finds user by email and password and generates token returns user and token pair,
+	// or mfa challenge instead of token pair when user has two-factor authentication enabled
+	Login(ctx context.Context, email string, password string) (*entity.User, *entity.TokenPair, *entity.MFAChallenge, error)
 }
 
 // NewUserFinder constructs new instance of UserFinder
 func NewUserFinder(
 	cfg config.Config,
 	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userUpdaterRepository repository.UserUpdaterRepositoryUseCase,
+	userTokenSvc UserTokenUseCase,
+	userMFASvc UserMFAUseCase,
+	userLoginAttemptRepo repository.UserLoginAttemptRepositoryUseCase,
+	passwordHasher hasher.PasswordHasher,
 ) *UserFinder {
+	dummyPasswordHash, err := passwordHasher.Hash(dummyPassword)
+	if err != nil {
+		log.Println("[UserFinder - NewUserFinder] Error while hashing dummy password :", err)
+	}
+
 	return &UserFinder{
-		cfg:                  cfg,
-		userFinderRepository: userFinderRepository,
+		cfg:                   cfg,
+		userFinderRepository:  userFinderRepository,
+		userUpdaterRepository: userUpdaterRepository,
+		userTokenSvc:          userTokenSvc,
+		userMFASvc:            userMFASvc,
+		userLoginAttemptRepo:  userLoginAttemptRepo,
+		passwordHasher:        passwordHasher,
+		dummyPasswordHash:     dummyPasswordHash,
 	}
 }
 
@@ -49,8 +81,8 @@ func (svc *UserFinder) FindByID(ctx context.Context, refID uuid.UUID) (*entity.U
 
 	if err != nil {
 		log.Println("[UserFinder - FindByID] Error while finding user data :", err)
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.ErrRecordNotFound.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrRecordNotFound.Error()
 		}
 		return nil, err
 	}
@@ -58,41 +90,185 @@ func (svc *UserFinder) FindByID(ctx context.Context, refID uuid.UUID) (*entity.U
 	return res, nil
 }
 
-// Login finds user by email and password and generates token returns user and token
-func (svc *UserFinder) Login(ctx context.Context, email string, password string) (*entity.User, string, error) {
-	res, err := svc.userFinderRepository.FindByEmail(ctx, email)
+// FindByIDWithDeleted finds user by user id including soft deleted one
+func (svc *UserFinder) FindByIDWithDeleted(ctx context.Context, refID uuid.UUID) (*entity.User, error) {
+	res, err := svc.userFinderRepository.FindByIDWithDeleted(ctx, refID)
 
 	if err != nil {
-		log.Println("[UserFinder - FindByEmailPassword] Error while finding user data :", err)
-		if err == gorm.ErrRecordNotFound {
-			return nil, "", errors.ErrRecordNotFound.Error()
+		log.Println("[UserFinder - FindByIDWithDeleted] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
 		}
-		return nil, "", err
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return res, nil
+}
+
+// FindAll finds users matching filter, starting after the given page token, and returns the next page token.
+// The next page token is empty on the last page.
+func (svc *UserFinder) FindAll(ctx context.Context, filter *entity.UserFilter, pageToken string) ([]*entity.User, string, error) {
+	if pageToken != "" {
+		cursor, err := entity.DecodeUserCursor(pageToken, filter)
+		if err != nil {
+			log.Println("[UserFinder - FindAll] Error while decoding page token :", err)
+			return nil, "", commonError.ErrInvalidPageToken.Error()
+		}
+		filter.Cursor = cursor
+	}
+
+	res, err := svc.userFinderRepository.FindAll(ctx, filter)
+	if err != nil {
+		log.Println("[UserFinder - FindAll] Error while finding user data :", err)
+		return nil, "", commonError.ErrInternalServerError.Error()
+	}
+
+	if len(res) <= filter.Limit {
+		return res, "", nil
+	}
+
+	res = res[:filter.Limit]
+
+	return res, filter.CursorOf(res[len(res)-1]).Encode(), nil
+}
+
+// Login finds user by email and password and generates token returns user and token pair,
+// or mfa challenge instead of token pair when user has two-factor authentication enabled.
+// Unknown email and wrong password return the same error after the same hash comparison,
+// and both count as failed login of the email and of the ip address of the caller.
+// A password hash made with an outdated algorithm or parameters is upgraded once the password is proven.
+func (svc *UserFinder) Login(ctx context.Context, email string, password string) (*entity.User, *entity.TokenPair, *entity.MFAChallenge, error) {
+	account := entity.NormalizeEmail(email)
+	clientIP := tools.GetClientIPFromContext(ctx, svc.cfg.Network.TrustedProxies)
+
+	locked, err := svc.isLoginLocked(ctx, account, clientIP)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	verifyPassword := tools.BcryptVerifyHash(res.Password, password)
+	if locked {
+		return nil, nil, nil, commonError.ErrTooManyLoginAttempts.Error()
+	}
 
-	if !verifyPassword {
-		return nil, "", errors.ErrWrongLoginCredentials.Error()
+	// credentials are read with a single query whether the email exists or not, never through cache,
+	// so that known and unknown emails take the same time up to the hash comparison
+	passwordHash := svc.dummyPasswordHash
+	res, err := svc.userFinderRepository.FindCredentialsByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("[UserFinder - Login] Error while finding user data :", err)
+		return nil, nil, nil, commonError.ErrInternalServerError.Error()
 	}
 
-	claims := &commonJwt.CustomClaims{
-		ExpiresAt: time.Now().Add(time.Hour * constant.TwentyFourHour * constant.DaysInOneYear).Unix(),
-		ID:        uuid.New().String(),
-		IssuedAt:  time.Now().Unix(),
-		NotBefore: time.Now().Unix(),
-		Subject:   res.ID,
-		Issuer:    constant.MobileIssuer,
+	if res != nil {
+		passwordHash, res.Password = res.Password, ""
 	}
 
-	gen := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	verifyPassword, err := svc.passwordHasher.Verify(passwordHash, password)
+	if err != nil {
+		log.Println("[UserFinder - Login] Error while verifying password :", err)
+	}
 
-	token, err := gen.SignedString([]byte(svc.cfg.JWTConfig.SecretKey))
+	if res == nil || !verifyPassword {
+		svc.registerLoginFailure(ctx, entity.LoginAttemptScopeAccount, account, svc.cfg.LoginThrottle.AccountMaxFailures)
+		svc.registerLoginFailure(ctx, entity.LoginAttemptScopeIP, clientIP, svc.cfg.LoginThrottle.IPMaxFailures)
+		return nil, nil, nil, commonError.ErrWrongLoginCredentials.Error()
+	}
+
+	if err := svc.userLoginAttemptRepo.Remove(ctx, entity.LoginAttemptScopeAccount, account); err != nil {
+		log.Println("[UserFinder - Login] Error while resetting failed logins :", err)
+	}
+
+	svc.rehashPassword(ctx, res.ID, passwordHash, password)
+
+	if res.IsDisabled() {
+		return nil, nil, nil, commonError.ErrUserDisabled.Error()
+	}
+
+	challenge, err := svc.userMFASvc.Challenge(ctx, res.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if challenge != nil {
+		return res, nil, challenge, nil
+	}
+
+	token, err := svc.userTokenSvc.Issue(ctx, res.ID)
 
 	if err != nil {
 		log.Println("[UserFinder - Login] Error while generating token :", err)
-		return nil, "", errors.ErrInternalServerError.Error()
+		return nil, nil, nil, err
 	}
 
-	return res, token, nil
+	return res, token, nil, nil
+}
+
+// isLoginLocked checks whether login of the email or from the ip address is locked
+func (svc *UserFinder) isLoginLocked(ctx context.Context, account string, clientIP string) (bool, error) {
+	now := time.Now()
+	identifiers := map[string]string{
+		entity.LoginAttemptScopeAccount: account,
+		entity.LoginAttemptScopeIP:      clientIP,
+	}
+
+	for scope, identifier := range identifiers {
+		if identifier == "" {
+			continue
+		}
+
+		attempt, err := svc.userLoginAttemptRepo.Find(ctx, scope, identifier)
+		if err != nil {
+			log.Println("[UserFinder - isLoginLocked] Error while finding failed logins :", err)
+			return false, commonError.ErrInternalServerError.Error()
+		}
+
+		if attempt.IsLocked(now) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// registerLoginFailure counts failed login of identifier and locks it once it reaches maxFailures.
+// Failures are counted atomically, so concurrent failures never count as one, and the lockout is computed from the count.
+func (svc *UserFinder) registerLoginFailure(ctx context.Context, scope string, identifier string, maxFailures int) {
+	if identifier == "" {
+		return
+	}
+
+	failures, err := svc.userLoginAttemptRepo.RegisterFailure(ctx, scope, identifier, svc.cfg.LoginThrottle.FailureWindow)
+	if err != nil {
+		log.Println("[UserFinder - registerLoginFailure] Error while counting failed login :", err)
+		return
+	}
+
+	now := time.Now()
+	attempt := new(entity.LoginAttempt)
+	attempt.RegisterFailure(now, failures, maxFailures, svc.cfg.LoginThrottle.BaseLockout, svc.cfg.LoginThrottle.MaxLockout)
+	if !attempt.IsLocked(now) {
+		return
+	}
+
+	if err := svc.userLoginAttemptRepo.Lock(ctx, scope, identifier, attempt.LockedUntil); err != nil {
+		log.Println("[UserFinder - registerLoginFailure] Error while locking login :", err)
+	}
+}
+
+// rehashPassword upgrades password hash of user when it is made with an outdated algorithm or parameters,
+// failing to upgrade does not fail the login because the old hash keeps working
+func (svc *UserFinder) rehashPassword(ctx context.Context, userID uuid.UUID, currentHash string, password string) {
+	if !svc.passwordHasher.NeedsRehash(currentHash) {
+		return
+	}
+
+	passwordHash, err := svc.passwordHasher.Hash(password)
+	if err != nil {
+		log.Println("[UserFinder - rehashPassword] Error while hashing password :", err)
+		return
+	}
+
+	if err := svc.userUpdaterRepository.UpdatePasswordHash(ctx, userID, currentHash, passwordHash); err != nil {
+		log.Println("[UserFinder - rehashPassword] Error while updating password hash :", err)
+	}
 }
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonentity "grpc-starter/common/entity"
+	commonError "grpc-starter/common/errors"
+	commonGorm "grpc-starter/common/gorm"
+	"grpc-starter/common/oidc"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+// UserIdentity responsible for login with ID tokens of OpenID Connect providers
+type UserIdentity struct {
+	cfg                    config.Config
+	userFinderRepository   repository.UserFinderRepositoryUseCase
+	userIdentityRepository repository.UserIdentityRepositoryUseCase
+	idTokenVerifier        *oidc.Verifier
+	userTokenSvc           UserTokenUseCase
+	userMFASvc             UserMFAUseCase
+}
+
+// UserIdentityUseCase is use case for login with ID tokens of OpenID Connect providers
+type UserIdentityUseCase interface {
+	// LoginWithIDToken verifies id token, finds or creates the user it belongs to and generates token,
+	// returns user and token pair, or mfa challenge instead of token pair when user has two-factor authentication enabled
+	LoginWithIDToken(ctx context.Context, idToken string) (*entity.User, *entity.TokenPair, *entity.MFAChallenge, error)
+}
+
+// NewUserIdentity constructs new instance of UserIdentity
+func NewUserIdentity(
+	cfg config.Config,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userIdentityRepository repository.UserIdentityRepositoryUseCase,
+	idTokenVerifier *oidc.Verifier,
+	userTokenSvc UserTokenUseCase,
+	userMFASvc UserMFAUseCase,
+) *UserIdentity {
+	return &UserIdentity{
+		cfg:                    cfg,
+		userFinderRepository:   userFinderRepository,
+		userIdentityRepository: userIdentityRepository,
+		idTokenVerifier:        idTokenVerifier,
+		userTokenSvc:           userTokenSvc,
+		userMFASvc:             userMFASvc,
+	}
+}
+
+// LoginWithIDToken verifies id token, finds or creates the user it belongs to and generates token.
+// An identity that is already linked logs in its user. Otherwise the identity is linked to the user owning
+// the same email, or a new user is created, both only when the provider has verified the email.
+// A user whose own email is not verified is never linked, because anyone could have registered that email.
+func (svc *UserIdentity) LoginWithIDToken(ctx context.Context, idToken string) (*entity.User, *entity.TokenPair, *entity.MFAChallenge, error) {
+	claims, err := svc.idTokenVerifier.Verify(ctx, idToken)
+	if err != nil {
+		log.Println("[UserIdentity - LoginWithIDToken] Error while verifying id token :", err)
+		return nil, nil, nil, commonError.ErrInvalidIDToken.Error()
+	}
+
+	user, err := svc.findLinkedUser(ctx, claims)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if user == nil {
+		user, err = svc.linkUser(ctx, claims)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if user.IsDisabled() {
+		return nil, nil, nil, commonError.ErrUserDisabled.Error()
+	}
+
+	challenge, err := svc.userMFASvc.Challenge(ctx, user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if challenge != nil {
+		return user, nil, challenge, nil
+	}
+
+	token, err := svc.userTokenSvc.Issue(ctx, user.ID)
+	if err != nil {
+		log.Println("[UserIdentity - LoginWithIDToken] Error while generating token for user :", err)
+		return nil, nil, nil, err
+	}
+
+	return user, token, nil, nil
+}
+
+// findLinkedUser finds user of an identity that is already linked, it returns nil when the identity is not linked yet
+func (svc *UserIdentity) findLinkedUser(ctx context.Context, claims *oidc.IDToken) (*entity.User, error) {
+	identity, err := svc.userIdentityRepository.FindByProviderSubject(ctx, claims.Provider, claims.Subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Println("[UserIdentity - findLinkedUser] Error while finding user identity :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	user, err := svc.userFinderRepository.FindByID(ctx, identity.UserID)
+	if err != nil {
+		log.Println("[UserIdentity - findLinkedUser] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return user, nil
+}
+
+// linkUser links identity to the user owning its email, or creates a new user when there is none
+func (svc *UserIdentity) linkUser(ctx context.Context, claims *oidc.IDToken) (*entity.User, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, commonError.ErrIdentityEmailNotVerified.Error()
+	}
+
+	user, err := svc.userFinderRepository.FindByEmail(ctx, claims.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("[UserIdentity - linkUser] Error while finding user data :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if user != nil {
+		if !user.IsEmailVerified() {
+			return nil, commonError.ErrIdentityLinkNotAllowed.Error()
+		}
+		err = svc.userIdentityRepository.Create(ctx, svc.newIdentity(user.ID, claims))
+	} else {
+		user = entity.NewUserFromIdentity(uuid.New(), claims.Email, commonentity.SystemActor)
+		err = svc.userIdentityRepository.CreateWithUser(ctx, user, svc.newIdentity(user.ID, claims))
+	}
+
+	if err != nil {
+		log.Println("[UserIdentity - linkUser] Error while linking user identity :", err)
+		return svc.linkConflict(ctx, claims, err)
+	}
+
+	return user, nil
+}
+
+// linkConflict handles failure of linking, when the same identity is linked concurrently the linked user is returned
+func (svc *UserIdentity) linkConflict(ctx context.Context, claims *oidc.IDToken, err error) (*entity.User, error) {
+	var uniqueErr *commonGorm.UniqueViolationError
+	if !errors.As(err, &uniqueErr) {
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	user, findErr := svc.findLinkedUser(ctx, claims)
+	if findErr != nil {
+		return nil, findErr
+	}
+
+	if user == nil {
+		return nil, createError(err)
+	}
+
+	return user, nil
+}
+
+// newIdentity creates identity of id token claims for user
+func (svc *UserIdentity) newIdentity(userID uuid.UUID, claims *oidc.IDToken) *entity.UserIdentity {
+	return entity.NewUserIdentity(userID, claims.Provider, claims.Subject, claims.Email, commonentity.SystemActor)
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonentity "grpc-starter/common/entity"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/tools"
+	"grpc-starter/common/totp"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+const (
+	// mfaChallengeTokenLength is the number of random bytes used for mfa challenge token
+	mfaChallengeTokenLength = 32
+)
+
+// UserMFA responsible for time-based one-time password two-factor authentication
+type UserMFA struct {
+	cfg                  config.Config
+	userFinderRepository repository.UserFinderRepositoryUseCase
+	userMFARepository    repository.UserMFARepositoryUseCase
+	userTokenSvc         UserTokenUseCase
+}
+
+// UserMFAUseCase is use case for two-factor authentication
+type UserMFAUseCase interface {
+	// Enroll generates a new unconfirmed secret for user
+	Enroll(ctx context.Context, userID uuid.UUID) (*entity.MFAEnrollment, error)
+	// Confirm enables two-factor authentication with a one-time password of the enrolled secret and returns recovery codes
+	Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// Challenge issues mfa challenge when user has two-factor authentication enabled, otherwise it returns nil
+	Challenge(ctx context.Context, userID uuid.UUID) (*entity.MFAChallenge, error)
+	// Verify exchanges mfa challenge and a one-time password or recovery code for user and token pair
+	Verify(ctx context.Context, challengeToken string, code string) (*entity.User, *entity.TokenPair, error)
+}
+
+// NewUserMFA constructs new instance of UserMFA
+func NewUserMFA(
+	cfg config.Config,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userMFARepository repository.UserMFARepositoryUseCase,
+	userTokenSvc UserTokenUseCase,
+) *UserMFA {
+	return &UserMFA{
+		cfg:                  cfg,
+		userFinderRepository: userFinderRepository,
+		userMFARepository:    userMFARepository,
+		userTokenSvc:         userTokenSvc,
+	}
+}
+
+// Enroll generates a new unconfirmed secret for user, replacing the previous unconfirmed one
+func (svc *UserMFA) Enroll(ctx context.Context, userID uuid.UUID) (*entity.MFAEnrollment, error) {
+	user, err := svc.userFinderRepository.FindByID(ctx, userID)
+	if err != nil {
+		log.Println("[UserMFA - Enroll] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	mfa, err := svc.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa != nil && mfa.IsConfirmed() {
+		return nil, commonError.ErrMFAAlreadyEnabled.Error()
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Println("[UserMFA - Enroll] Error while generating secret :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.userMFARepository.Save(ctx, entity.NewUserMFA(userID, secret, commonentity.SystemActor)); err != nil {
+		log.Println("[UserMFA - Enroll] Error while saving user mfa :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return &entity.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(svc.cfg.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication with a one-time password of the enrolled secret and returns recovery codes.
+// Recovery codes are only stored as bcrypt hashes, so this is the only time they are shown to the user.
+func (svc *UserMFA) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := svc.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil {
+		return nil, commonError.ErrMFANotEnrolled.Error()
+	}
+
+	if mfa.IsConfirmed() {
+		return nil, commonError.ErrMFAAlreadyEnabled.Error()
+	}
+
+	valid, err := svc.verifyTOTP(ctx, mfa, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !valid {
+		return nil, commonError.ErrInvalidMFACode.Error()
+	}
+
+	plainCodes := make([]string, 0, svc.cfg.MFA.RecoveryCodeCount)
+	recoveryCodes := make([]*entity.RecoveryCode, 0, svc.cfg.MFA.RecoveryCodeCount)
+	for i := 0; i < svc.cfg.MFA.RecoveryCodeCount; i++ {
+		plain, hashed, err := tools.GenerateRecoveryCode()
+		if err != nil {
+			log.Println("[UserMFA - Confirm] Error while generating recovery code :", err)
+			return nil, commonError.ErrInternalServerError.Error()
+		}
+		plainCodes = append(plainCodes, plain)
+		recoveryCodes = append(recoveryCodes, entity.NewRecoveryCode(userID, hashed, commonentity.SystemActor))
+	}
+
+	if err := svc.userMFARepository.Confirm(ctx, userID, recoveryCodes); err != nil {
+		log.Println("[UserMFA - Confirm] Error while confirming user mfa :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return plainCodes, nil
+}
+
+// Challenge issues mfa challenge when user has two-factor authentication enabled, otherwise it returns nil
+func (svc *UserMFA) Challenge(ctx context.Context, userID uuid.UUID) (*entity.MFAChallenge, error) {
+	mfa, err := svc.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil || !mfa.IsConfirmed() {
+		return nil, nil
+	}
+
+	token, err := tools.GenerateRandomToken(mfaChallengeTokenLength)
+	if err != nil {
+		log.Println("[UserMFA - Challenge] Error while generating mfa challenge token :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	challenge := entity.NewMFAChallenge(token, userID, svc.cfg.MFA.ChallengeTTL)
+	if err := svc.userMFARepository.SaveChallenge(ctx, challenge); err != nil {
+		log.Println("[UserMFA - Challenge] Error while saving mfa challenge :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return challenge, nil
+}
+
+// Verify exchanges mfa challenge and a one-time password or recovery code for user and token pair.
+// Every attempt is counted before the code is checked, once the attempts are exhausted the user has to login again.
+// A correct code claims the challenge before it is used and tokens are issued, so a challenge only ever issues
+// one token pair, and a request losing the claim to a concurrent one leaves the code unused.
+func (svc *UserMFA) Verify(ctx context.Context, challengeToken string, code string) (*entity.User, *entity.TokenPair, error) {
+	challenge, err := svc.userMFARepository.FindChallenge(ctx, challengeToken)
+	if err != nil {
+		log.Println("[UserMFA - Verify] Error while finding mfa challenge :", err)
+		return nil, nil, commonError.ErrInvalidMFAChallenge.Error()
+	}
+
+	attempts, err := svc.userMFARepository.CountChallengeAttempt(ctx, challenge)
+	if err != nil {
+		log.Println("[UserMFA - Verify] Error while counting mfa challenge attempt :", err)
+		return nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if attempts > svc.cfg.MFA.MaxAttempts {
+		return nil, nil, commonError.ErrInvalidMFAChallenge.Error()
+	}
+
+	mfa, err := svc.findMFA(ctx, challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mfa == nil || !mfa.IsConfirmed() {
+		return nil, nil, commonError.ErrInvalidMFAChallenge.Error()
+	}
+
+	var (
+		valid        bool
+		step         int64
+		recoveryCode *entity.RecoveryCode
+	)
+	if len(code) == totp.Digits {
+		step, valid = totp.Validate(mfa.Secret, code, time.Now(), svc.cfg.MFA.Skew)
+	} else {
+		recoveryCode, err = svc.findRecoveryCode(ctx, mfa.UserID, code)
+		if err != nil {
+			return nil, nil, err
+		}
+		valid = recoveryCode != nil
+	}
+
+	if !valid {
+		return nil, nil, commonError.ErrInvalidMFACode.Error()
+	}
+
+	challenge, err = svc.userMFARepository.ClaimChallenge(ctx, challengeToken)
+	if err != nil {
+		log.Println("[UserMFA - Verify] Error while claiming mfa challenge :", err)
+		if errors.Is(err, repository.ErrMFAChallengeClaimed) {
+			return nil, nil, commonError.ErrInvalidMFAChallenge.Error()
+		}
+		return nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	var unused bool
+	if recoveryCode != nil {
+		unused, err = svc.useRecoveryCode(ctx, recoveryCode)
+	} else {
+		unused, err = svc.useStep(ctx, mfa.UserID, step)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the code has been used with another challenge in the meantime
+	if !unused {
+		return nil, nil, commonError.ErrInvalidMFACode.Error()
+	}
+
+	user, err := svc.userFinderRepository.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		log.Println("[UserMFA - Verify] Error while finding user data :", err)
+		return nil, nil, commonError.ErrInvalidMFAChallenge.Error()
+	}
+
+	if user.IsDisabled() {
+		return nil, nil, commonError.ErrUserDisabled.Error()
+	}
+
+	token, err := svc.userTokenSvc.Issue(ctx, user.ID)
+	if err != nil {
+		log.Println("[UserMFA - Verify] Error while generating token for user :", err)
+		return nil, nil, err
+	}
+
+	return user, token, nil
+}
+
+// findMFA finds two-factor authentication of user, it returns nil when the user has never enrolled
+func (svc *UserMFA) findMFA(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	mfa, err := svc.userMFARepository.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Println("[UserMFA - findMFA] Error while finding user mfa :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return mfa, nil
+}
+
+// verifyTOTP checks one-time password of user, a code is rejected when its time step has already been used
+func (svc *UserMFA) verifyTOTP(ctx context.Context, mfa *entity.UserMFA, code string) (bool, error) {
+	step, valid := totp.Validate(mfa.Secret, code, time.Now(), svc.cfg.MFA.Skew)
+	if !valid {
+		return false, nil
+	}
+
+	return svc.useStep(ctx, mfa.UserID, step)
+}
+
+// useStep marks time step of one-time password of user as used, it returns false when the step has already been used
+func (svc *UserMFA) useStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	ttl := time.Duration(2*svc.cfg.MFA.Skew+1) * totp.Period * time.Second
+	unused, err := svc.userMFARepository.UseStep(ctx, userID, step, ttl)
+	if err != nil {
+		log.Println("[UserMFA - useStep] Error while using time step :", err)
+		return false, commonError.ErrInternalServerError.Error()
+	}
+
+	return unused, nil
+}
+
+// findRecoveryCode finds unused recovery code of user matching code without using it, it returns nil when none matches
+func (svc *UserMFA) findRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (*entity.RecoveryCode, error) {
+	recoveryCodes, err := svc.userMFARepository.FindUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		log.Println("[UserMFA - findRecoveryCode] Error while finding recovery codes :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		if tools.BcryptVerifyHash(recoveryCode.CodeHash, code) {
+			return recoveryCode, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// useRecoveryCode marks recovery code as used, it returns false when the code has already been used
+func (svc *UserMFA) useRecoveryCode(ctx context.Context, recoveryCode *entity.RecoveryCode) (bool, error) {
+	used, err := svc.userMFARepository.UseRecoveryCode(ctx, recoveryCode.ID)
+	if err != nil {
+		log.Println("[UserMFA - useRecoveryCode] Error while using recovery code :", err)
+		return false, commonError.ErrInternalServerError.Error()
+	}
+
+	return used, nil
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+	"grpc-starter/modules/user/v1/service"
+)
+
+// fakeUserMFARepository serves a confirmed mfa with its recovery codes and a single challenge,
+// the methods it does not override are not expected to be called
+type fakeUserMFARepository struct {
+	repository.UserMFARepositoryUseCase
+	mfa           *entity.UserMFA
+	recoveryCodes []*entity.RecoveryCode
+	challenge     *entity.MFAChallenge
+	claimErr      error
+	claimed       int
+	used          []uuid.UUID
+}
+
+func (r *fakeUserMFARepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	return r.mfa, nil
+}
+
+func (r *fakeUserMFARepository) FindUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]*entity.RecoveryCode, error) {
+	return r.recoveryCodes, nil
+}
+
+func (r *fakeUserMFARepository) UseRecoveryCode(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.used = append(r.used, id)
+	return true, nil
+}
+
+func (r *fakeUserMFARepository) FindChallenge(ctx context.Context, token string) (*entity.MFAChallenge, error) {
+	return r.challenge, nil
+}
+
+func (r *fakeUserMFARepository) CountChallengeAttempt(ctx context.Context, challenge *entity.MFAChallenge) (int, error) {
+	return 1, nil
+}
+
+func (r *fakeUserMFARepository) ClaimChallenge(ctx context.Context, token string) (*entity.MFAChallenge, error) {
+	if r.claimErr != nil {
+		return nil, r.claimErr
+	}
+
+	r.claimed++
+	return r.challenge, nil
+}
+
+func TestUserMFA_Verify(t *testing.T) {
+	cfg := config.Config{MFA: config.MFA{MaxAttempts: 5, Skew: 1}}
+	user := &entity.User{ID: uuid.New(), Email: "user@starter.com"}
+
+	hashed, err := tools.BcryptEncrypt("recovery-code")
+	assert.Nil(t, err)
+
+	newMFARepo := func() *fakeUserMFARepository {
+		return &fakeUserMFARepository{
+			mfa:           &entity.UserMFA{UserID: user.ID, ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true}},
+			recoveryCodes: []*entity.RecoveryCode{{ID: uuid.New(), UserID: user.ID, CodeHash: hashed}},
+			challenge:     entity.NewMFAChallenge("challenge-token", user.ID, time.Minute),
+		}
+	}
+	newSvc := func(mfaRepo *fakeUserMFARepository, tokenSvc *fakeUserToken) *service.UserMFA {
+		finder := &fakeUserFinderRepository{users: map[uuid.UUID]*entity.User{user.ID: user}}
+		return service.NewUserMFA(cfg, finder, mfaRepo, tokenSvc)
+	}
+
+	t.Run("recovery code is used once the challenge is claimed", func(t *testing.T) {
+		mfaRepo, tokenSvc := newMFARepo(), &fakeUserToken{}
+
+		verified, token, err := newSvc(mfaRepo, tokenSvc).Verify(context.Background(), "challenge-token", "recovery-code")
+		assert.Nil(t, err)
+		assert.NotNil(t, token)
+		assert.Equal(t, user.ID, verified.ID)
+		assert.Equal(t, 1, mfaRepo.claimed)
+		assert.Equal(t, []uuid.UUID{mfaRepo.recoveryCodes[0].ID}, mfaRepo.used)
+		assert.Equal(t, []uuid.UUID{user.ID}, tokenSvc.issued)
+	})
+
+	t.Run("recovery code is left unused when a concurrent request claimed the challenge", func(t *testing.T) {
+		mfaRepo, tokenSvc := newMFARepo(), &fakeUserToken{}
+		mfaRepo.claimErr = repository.ErrMFAChallengeClaimed
+
+		_, _, err := newSvc(mfaRepo, tokenSvc).Verify(context.Background(), "challenge-token", "recovery-code")
+		assert.EqualError(t, err, commonError.ErrInvalidMFAChallenge.Error().Error())
+		assert.Empty(t, mfaRepo.used)
+		assert.Empty(t, tokenSvc.issued)
+	})
+
+	t.Run("wrong recovery code keeps the challenge", func(t *testing.T) {
+		mfaRepo, tokenSvc := newMFARepo(), &fakeUserToken{}
+
+		_, _, err := newSvc(mfaRepo, tokenSvc).Verify(context.Background(), "challenge-token", "wrong-code")
+		assert.EqualError(t, err, commonError.ErrInvalidMFACode.Error().Error())
+		assert.Zero(t, mfaRepo.claimed)
+		assert.Empty(t, mfaRepo.used)
+	})
+}
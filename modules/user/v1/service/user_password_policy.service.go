@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bufio"
+	_ "embed" // embeds breached password list
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/hasher"
+)
+
+const (
+	// passwordField is the request field reported in password policy violations
+	passwordField = "password"
+	// minPersonalInfoLength is the minimum length of username or email part checked as password substring,
+	// shorter parts match too many passwords by accident
+	minPersonalInfoLength = 3
+)
+
+//go:embed breached_passwords.txt
+var breachedPasswordList string
+
+// UserPasswordPolicy responsible for checking password against the password policy
+type UserPasswordPolicy struct {
+	cfg      config.Config
+	breached map[string]struct{}
+}
+
+// UserPasswordPolicyUseCase is use case for checking password against the password policy
+type UserPasswordPolicyUseCase interface {
+	// Validate checks password of user with the given username and email,
+	// it returns invalid argument error with a violation for every broken rule
+	Validate(password string, username string, email string) error
+}
+
+// NewUserPasswordPolicy constructs new instance of UserPasswordPolicy
+func NewUserPasswordPolicy(cfg config.Config) *UserPasswordPolicy {
+	return &UserPasswordPolicy{
+		cfg:      cfg,
+		breached: parseBreachedPasswords(breachedPasswordList),
+	}
+}
+
+// Validate checks password of user with the given username and email,
+// it returns invalid argument error with a violation for every broken rule.
+// It is the only check of password length, the length is counted in characters, and in bytes as well
+// when passwords are hashed with bcrypt, which ignores every byte past its limit.
+func (svc *UserPasswordPolicy) Validate(password string, username string, email string) error {
+	policy := svc.cfg.PasswordPolicy
+	var violations []*errdetails.BadRequest_FieldViolation
+	violate := func(description string) {
+		violations = append(violations, commonError.NewFieldViolation(passwordField, description))
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violate(fmt.Sprintf("password minimal %d karakter", policy.MinLength))
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		violate(fmt.Sprintf("password maksimal %d karakter", policy.MaxLength))
+	}
+	if svc.cfg.PasswordHash.Algorithm == hasher.AlgorithmBcrypt && len([]byte(password)) > hasher.BcryptMaxPasswordBytes {
+		violate(fmt.Sprintf("password maksimal %d byte", hasher.BcryptMaxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		violate("password harus mengandung huruf besar")
+	}
+	if policy.RequireLower && !hasLower {
+		violate("password harus mengandung huruf kecil")
+	}
+	if policy.RequireDigit && !hasDigit {
+		violate("password harus mengandung angka")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violate("password harus mengandung simbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if containsPersonalInfo(lowered, username, email) {
+		violate("password tidak boleh mengandung username atau email")
+	}
+
+	if policy.CheckBreached {
+		if _, ok := svc.breached[lowered]; ok {
+			violate("password terlalu umum dan pernah bocor, gunakan password lain")
+		}
+	}
+
+	if len(violations) > 0 {
+		return commonError.ErrPasswordPolicyViolated.WithFieldViolations(violations...)
+	}
+
+	return nil
+}
+
+// containsPersonalInfo checks whether lower cased password contains username or local part of email
+func containsPersonalInfo(password string, username string, email string) bool {
+	localPart := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		localPart = email[:at]
+	}
+
+	for _, info := range []string{username, localPart} {
+		info = strings.ToLower(strings.TrimSpace(info))
+		if utf8.RuneCountInString(info) >= minPersonalInfoLength && strings.Contains(password, info) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseBreachedPasswords builds lookup set from breached password list
+func parseBreachedPasswords(list string) map[string]struct{} {
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+
+	return breached
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"grpc-starter/common/config"
+	"grpc-starter/common/hasher"
+	"grpc-starter/modules/user/v1/service"
+)
+
+// passwordViolations returns descriptions of the password field violations carried by err
+func passwordViolations(t *testing.T, err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	var descriptions []string
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, violation := range badRequest.GetFieldViolations() {
+			assert.Equal(t, "password", violation.GetField())
+			descriptions = append(descriptions, violation.GetDescription())
+		}
+	}
+
+	return descriptions
+}
+
+func TestUserPasswordPolicy_Validate(t *testing.T) {
+	policy := config.PasswordPolicy{
+		MinLength:     8,
+		MaxLength:     72,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		CheckBreached: true,
+	}
+
+	tests := []struct {
+		name       string
+		algorithm  config.PasswordHashAlgorithm
+		password   string
+		username   string
+		email      string
+		violations []string
+	}{
+		{
+			name:     "password satisfying every rule is accepted",
+			password: "Kopi-Susu-2022",
+			username: "rifqi",
+			email:    "rifqi@starter.com",
+		},
+		{
+			name:       "too short password is rejected",
+			password:   "Ab1!",
+			violations: []string{"password minimal 8 karakter"},
+		},
+		{
+			name:       "too long password is rejected",
+			algorithm:  hasher.AlgorithmArgon2id,
+			password:   "Ab1!" + strings.Repeat("a", 69),
+			violations: []string{"password maksimal 72 karakter"},
+		},
+		{
+			name:     "length is counted in characters",
+			password: "Ab1!ééé",
+			// seven characters, although ten bytes
+			violations: []string{"password minimal 8 karakter"},
+		},
+		{
+			name:       "password longer than bcrypt uses is rejected when hashed with bcrypt",
+			algorithm:  hasher.AlgorithmBcrypt,
+			password:   "Ab1!" + strings.Repeat("é", 35),
+			violations: []string{"password maksimal 72 byte"},
+		},
+		{
+			name:      "password longer than bcrypt uses is accepted when hashed with argon2id",
+			algorithm: hasher.AlgorithmArgon2id,
+			password:  "Ab1!" + strings.Repeat("é", 35),
+		},
+		{
+			name:     "every missing character class is reported",
+			password: "        ",
+			violations: []string{
+				"password harus mengandung huruf besar",
+				"password harus mengandung huruf kecil",
+				"password harus mengandung angka",
+				"password harus mengandung simbol",
+			},
+		},
+		{
+			name:       "password containing username is rejected",
+			password:   "Xx-Rifqi-2022",
+			username:   "rifqi",
+			violations: []string{"password tidak boleh mengandung username atau email"},
+		},
+		{
+			name:       "password containing local part of email is rejected",
+			password:   "Akram57!2022",
+			email:      "akram57@starter.com",
+			violations: []string{"password tidak boleh mengandung username atau email"},
+		},
+		{
+			name:     "short username is not matched",
+			password: "Kopi-Susu-2022",
+			username: "ko",
+		},
+		{
+			name:       "breached password is rejected regardless of case",
+			password:   "PASSWORD123",
+			violations: []string{"password harus mengandung huruf kecil", "password harus mengandung simbol", "password terlalu umum dan pernah bocor, gunakan password lain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := tt.algorithm
+			if algorithm == "" {
+				algorithm = hasher.AlgorithmBcrypt
+			}
+			svc := service.NewUserPasswordPolicy(config.Config{
+				PasswordPolicy: policy,
+				PasswordHash:   config.PasswordHash{Algorithm: algorithm},
+			})
+
+			assert.Equal(t, tt.violations, passwordViolations(t, svc.Validate(tt.password, tt.username, tt.email)))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/tools"
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+const (
+	// phoneOTPSMSContent is the content of phone number login code sms
+	phoneOTPSMSContent = "Kode verifikasi anda adalah %s. Kode ini berlaku selama %s. Jangan berikan kode ini kepada siapapun."
+)
+
+// UserPhoneOTP responsible for login with a code sent to user phone number
+type UserPhoneOTP struct {
+	cfg                    config.Config
+	userFinderRepository   repository.UserFinderRepositoryUseCase
+	userUpdaterRepository  repository.UserUpdaterRepositoryUseCase
+	userPhoneOTPRepository repository.UserPhoneOTPRepositoryUseCase
+	userTokenSvc           UserTokenUseCase
+	userMFASvc             UserMFAUseCase
+}
+
+// UserPhoneOTPUseCase is use case for login with a code sent to user phone number
+type UserPhoneOTPUseCase interface {
+	// Request sends login code to phone number when it belongs to a user
+	Request(ctx context.Context, phoneNumber string) error
+	// Login checks login code of phone number and generates token returns user and token pair,
+	// or mfa challenge instead of token pair when user has two-factor authentication enabled
+	Login(ctx context.Context, phoneNumber string, code string) (*entity.User, *entity.TokenPair, *entity.MFAChallenge, error)
+}
+
+// NewUserPhoneOTP constructs new instance of UserPhoneOTP
+func NewUserPhoneOTP(
+	cfg config.Config,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userUpdaterRepository repository.UserUpdaterRepositoryUseCase,
+	userPhoneOTPRepository repository.UserPhoneOTPRepositoryUseCase,
+	userTokenSvc UserTokenUseCase,
+	userMFASvc UserMFAUseCase,
+) *UserPhoneOTP {
+	return &UserPhoneOTP{
+		cfg:                    cfg,
+		userFinderRepository:   userFinderRepository,
+		userUpdaterRepository:  userUpdaterRepository,
+		userPhoneOTPRepository: userPhoneOTPRepository,
+		userTokenSvc:           userTokenSvc,
+		userMFASvc:             userMFASvc,
+	}
+}
+
+// Request sends login code to phone number when it belongs to a user.
+// Rate limits are applied per phone number before the user is looked up and no error is returned
+// for unregistered phone numbers, so the caller can not enumerate registered phone numbers.
+// Phone number is normalised first, so every format of the same number shares its rate limits and code.
+func (svc *UserPhoneOTP) Request(ctx context.Context, phoneNumber string) error {
+	phoneNumber = entity.NormalizePhoneNumber(phoneNumber)
+
+	started, err := svc.userPhoneOTPRepository.StartCooldown(ctx, phoneNumber, svc.cfg.PhoneOTP.ResendCooldown)
+	if err != nil {
+		log.Println("[UserPhoneOTP - Request] Error while saving phone otp cooldown :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if !started {
+		return commonError.ErrVerificationResendTooSoon.Error()
+	}
+
+	quota, err := svc.userPhoneOTPRepository.CountRequest(ctx, phoneNumber, svc.cfg.PhoneOTP.RequestWindow)
+	if err != nil {
+		log.Println("[UserPhoneOTP - Request] Error while counting phone otp request :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if quota.IsExceeded(svc.cfg.PhoneOTP.MaxRequests) {
+		return commonError.ErrOTPRequestLimitExceeded.Error()
+	}
+
+	user, err := svc.userFinderRepository.FindByPhoneNumber(ctx, phoneNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Println("[UserPhoneOTP - Request] Phone otp requested for unregistered phone number")
+			return nil
+		}
+		log.Println("[UserPhoneOTP - Request] Error while finding user data :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if user.IsDisabled() {
+		log.Println("[UserPhoneOTP - Request] Phone otp requested for disabled user")
+		return nil
+	}
+
+	code, codeHash, err := tools.GenerateOTP()
+	if err != nil {
+		log.Println("[UserPhoneOTP - Request] Error while generating phone otp :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.userPhoneOTPRepository.Save(ctx, phoneNumber, entity.NewOTP(codeHash, svc.cfg.PhoneOTP.CodeTTL)); err != nil {
+		log.Println("[UserPhoneOTP - Request] Error while saving phone otp :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	payload := notificationEntity.NewSecretSMSPayload(
+		phoneNumber,
+		fmt.Sprintf(phoneOTPSMSContent, code, svc.cfg.PhoneOTP.CodeTTL),
+		entity.SMSCategoryPhoneOTP,
+	)
+
+	if err := tools.SendTopic(ctx, svc.cfg, notificationEntity.SendSMSTopicName, payload); err != nil {
+		log.Println("[UserPhoneOTP - Request] Error while publishing phone otp sms :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return nil
+}
+
+// Login checks login code of phone number and generates token returns user and token pair,
+// or mfa challenge instead of token pair when user has two-factor authentication enabled.
+// Every attempt is counted atomically before the code is compared, so concurrent attempts can not exceed the maximum attempts,
+// and a correct code is consumed atomically, so it can only be used once. It marks the phone number as verified.
+func (svc *UserPhoneOTP) Login(ctx context.Context, phoneNumber string, code string) (*entity.User, *entity.TokenPair, *entity.MFAChallenge, error) {
+	phoneNumber = entity.NormalizePhoneNumber(phoneNumber)
+
+	otp, err := svc.userPhoneOTPRepository.Find(ctx, phoneNumber)
+	if err != nil {
+		log.Println("[UserPhoneOTP - Login] Error while finding phone otp :", err)
+		return nil, nil, nil, commonError.ErrInvalidVerificationCode.Error()
+	}
+
+	attempts, err := svc.userPhoneOTPRepository.CountAttempt(ctx, phoneNumber, otp)
+	if err != nil {
+		log.Println("[UserPhoneOTP - Login] Error while counting phone otp attempt :", err)
+		return nil, nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if attempts > svc.cfg.PhoneOTP.MaxAttempts {
+		return nil, nil, nil, commonError.ErrTooManyVerificationAttempts.Error()
+	}
+
+	if !tools.BcryptVerifyHash(otp.CodeHash, code) {
+		return nil, nil, nil, commonError.ErrInvalidVerificationCode.Error()
+	}
+
+	if err := svc.userPhoneOTPRepository.Consume(ctx, phoneNumber, otp.CodeHash); err != nil {
+		log.Println("[UserPhoneOTP - Login] Error while consuming phone otp :", err)
+		if errors.Is(err, repository.ErrPhoneOTPConsumed) {
+			return nil, nil, nil, commonError.ErrInvalidVerificationCode.Error()
+		}
+		return nil, nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	user, err := svc.userFinderRepository.FindByPhoneNumber(ctx, phoneNumber)
+	if err != nil {
+		log.Println("[UserPhoneOTP - Login] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, commonError.ErrInvalidVerificationCode.Error()
+		}
+		return nil, nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if user.IsDisabled() {
+		return nil, nil, nil, commonError.ErrUserDisabled.Error()
+	}
+
+	if !user.IsPhoneNumberVerified() {
+		if err := svc.userUpdaterRepository.MarkPhoneNumberVerified(ctx, user.ID); err != nil {
+			log.Println("[UserPhoneOTP - Login] Error while marking user phone number as verified :", err)
+			return nil, nil, nil, commonError.ErrInternalServerError.Error()
+		}
+	}
+
+	challenge, err := svc.userMFASvc.Challenge(ctx, user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if challenge != nil {
+		return user, nil, challenge, nil
+	}
+
+	token, err := svc.userTokenSvc.Issue(ctx, user.ID)
+	if err != nil {
+		log.Println("[UserPhoneOTP - Login] Error while generating token for user :", err)
+		return nil, nil, nil, err
+	}
+
+	return user, token, nil, nil
+}
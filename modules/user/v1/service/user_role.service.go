@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonentity "grpc-starter/common/entity"
+	commonError "grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+// UserRole responsible for managing roles of users
+type UserRole struct {
+	cfg                  config.Config
+	roleRepository       repository.UserRoleRepositoryUseCase
+	userFinderRepository repository.UserFinderRepositoryUseCase
+	revocationStore      *commonJwt.RevocationStore
+}
+
+// UserRoleUseCase is use case for managing roles of users
+type UserRoleUseCase interface {
+	// AssignRole assigns role to a user and returns roles of the user
+	AssignRole(ctx context.Context, userID uuid.UUID, role string) ([]string, error)
+	// RevokeRole revokes role from a user and returns roles of the user
+	RevokeRole(ctx context.Context, userID uuid.UUID, role string) ([]string, error)
+}
+
+// NewUserRole constructs new instance of UserRole
+func NewUserRole(
+	cfg config.Config,
+	roleRepository repository.UserRoleRepositoryUseCase,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	revocationStore *commonJwt.RevocationStore,
+) *UserRole {
+	return &UserRole{
+		cfg:                  cfg,
+		roleRepository:       roleRepository,
+		userFinderRepository: userFinderRepository,
+		revocationStore:      revocationStore,
+	}
+}
+
+// AssignRole assigns role to a user and returns roles of the user
+func (svc *UserRole) AssignRole(ctx context.Context, userID uuid.UUID, role string) ([]string, error) {
+	found, err := svc.findUserRole(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.roleRepository.Assign(ctx, entity.NewUserRole(userID, found.ID, commonentity.SystemActor)); err != nil {
+		log.Println("[UserRole - AssignRole] Error while assigning role :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return svc.refreshClaims(ctx, userID)
+}
+
+// RevokeRole revokes role from a user and returns roles of the user
+func (svc *UserRole) RevokeRole(ctx context.Context, userID uuid.UUID, role string) ([]string, error) {
+	found, err := svc.findUserRole(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.roleRepository.Revoke(ctx, userID, found.ID); err != nil {
+		log.Println("[UserRole - RevokeRole] Error while revoking role :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return svc.refreshClaims(ctx, userID)
+}
+
+// findUserRole makes sure both user and role exist and returns the role
+func (svc *UserRole) findUserRole(ctx context.Context, userID uuid.UUID, role string) (*entity.Role, error) {
+	if _, err := svc.userFinderRepository.FindByID(ctx, userID); err != nil {
+		log.Println("[UserRole - findUserRole] Error while finding user :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	found, err := svc.roleRepository.FindRoleByName(ctx, role)
+	if err != nil {
+		log.Println("[UserRole - findUserRole] Error while finding role :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrRoleNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return found, nil
+}
+
+// refreshClaims invalidates access tokens of a user so that the next refresh carries its new roles,
+// and returns roles of the user
+func (svc *UserRole) refreshClaims(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	if err := svc.revocationStore.RevokeAll(ctx, userID, svc.cfg.JWTConfig.AccessTokenTTL); err != nil {
+		log.Println("[UserRole - refreshClaims] Error while revoking access tokens :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	roles, err := svc.roleRepository.FindRoleNamesByUserID(ctx, userID)
+	if err != nil {
+		log.Println("[UserRole - refreshClaims] Error while finding user roles :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return roles, nil
+}
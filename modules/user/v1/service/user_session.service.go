@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonError "grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+// UserSession responsible for sessions of devices users are logged in from
+type UserSession struct {
+	cfg                    config.Config
+	sessionRepository      repository.UserSessionRepositoryUseCase
+	refreshTokenRepository repository.UserRefreshTokenRepositoryUseCase
+	revocationStore        *commonJwt.RevocationStore
+}
+
+// UserSessionUseCase is use case for sessions of devices users are logged in from
+type UserSessionUseCase interface {
+	// ListSessions lists sessions of a user that are neither revoked nor expired
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error)
+	// RevokeSession revokes a session of a user, its refresh tokens and the access tokens issued for it
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+}
+
+// NewUserSession constructs new instance of UserSession
+func NewUserSession(
+	cfg config.Config,
+	sessionRepository repository.UserSessionRepositoryUseCase,
+	refreshTokenRepository repository.UserRefreshTokenRepositoryUseCase,
+	revocationStore *commonJwt.RevocationStore,
+) *UserSession {
+	return &UserSession{
+		cfg:                    cfg,
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		revocationStore:        revocationStore,
+	}
+}
+
+// ListSessions lists sessions of a user that are neither revoked nor expired
+func (svc *UserSession) ListSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error) {
+	sessions, err := svc.sessionRepository.FindActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		log.Println("[UserSession - ListSessions] Error while finding sessions :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of a user, its refresh tokens and the access tokens issued for it
+func (svc *UserSession) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	session, err := svc.sessionRepository.FindByID(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commonError.ErrSessionNotFound.Error()
+		}
+		log.Println("[UserSession - RevokeSession] Error while finding session :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if !session.IsActive(time.Now()) {
+		return commonError.ErrSessionNotFound.Error()
+	}
+
+	if err := svc.refreshTokenRepository.RevokeFamily(ctx, session.ID); err != nil {
+		log.Println("[UserSession - RevokeSession] Error while revoking refresh token family :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.revocationStore.RevokeSession(ctx, session.ID, svc.cfg.JWTConfig.AccessTokenTTL); err != nil {
+		log.Println("[UserSession - RevokeSession] Error while revoking access tokens of session :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.sessionRepository.Revoke(ctx, session.ID); err != nil {
+		log.Println("[UserSession - RevokeSession] Error while revoking session :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return nil
+}
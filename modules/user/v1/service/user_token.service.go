@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"grpc-starter/common/config"
+	commonentity "grpc-starter/common/entity"
+	commonError "grpc-starter/common/errors"
+	commonJwt "grpc-starter/common/jwt"
+	"grpc-starter/common/tools"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+)
+
+const (
+	// refreshTokenLength is the number of random bytes used for refresh token
+	refreshTokenLength = 32
+)
+
+// UserToken responsible for issuing and rotating user tokens
+type UserToken struct {
+	cfg                    config.Config
+	refreshTokenRepository repository.UserRefreshTokenRepositoryUseCase
+	sessionRepository      repository.UserSessionRepositoryUseCase
+	roleRepository         repository.UserRoleRepositoryUseCase
+	tokenIssuer            *commonJwt.TokenIssuer
+	revocationStore        *commonJwt.RevocationStore
+}
+
+// UserTokenUseCase is use case for issuing and rotating user tokens
+type UserTokenUseCase interface {
+	// Issue issues access token and refresh token in a new token family,
+	// and records the session of the device the caller logs in from
+	Issue(ctx context.Context, userID uuid.UUID) (*entity.TokenPair, error)
+	// Refresh rotates refresh token and issues new access token.
+	// Reusing an already rotated refresh token revokes its whole family.
+	Refresh(ctx context.Context, refreshToken string) (uuid.UUID, *entity.TokenPair, error)
+	// Logout revokes access token of the principal and the family of the given refresh token, if any
+	Logout(ctx context.Context, principal *commonJwt.Principal, refreshToken string) error
+	// LogoutAll revokes every access token and refresh token of a user
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+}
+
+// NewUserToken constructs new instance of UserToken
+func NewUserToken(
+	cfg config.Config,
+	refreshTokenRepository repository.UserRefreshTokenRepositoryUseCase,
+	sessionRepository repository.UserSessionRepositoryUseCase,
+	roleRepository repository.UserRoleRepositoryUseCase,
+	tokenIssuer *commonJwt.TokenIssuer,
+	revocationStore *commonJwt.RevocationStore,
+) *UserToken {
+	return &UserToken{
+		cfg:                    cfg,
+		refreshTokenRepository: refreshTokenRepository,
+		sessionRepository:      sessionRepository,
+		roleRepository:         roleRepository,
+		tokenIssuer:            tokenIssuer,
+		revocationStore:        revocationStore,
+	}
+}
+
+// Issue issues access token and refresh token in a new token family,
+// and records the session of the device the caller logs in from.
+// The session shares its id with the token family.
+func (svc *UserToken) Issue(ctx context.Context, userID uuid.UUID) (*entity.TokenPair, error) {
+	refreshToken, plain, err := svc.newRefreshToken(userID, uuid.New())
+	if err != nil {
+		log.Println("[UserToken - Issue] Error while generating refresh token :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.refreshTokenRepository.Create(ctx, refreshToken); err != nil {
+		log.Println("[UserToken - Issue] Error while saving refresh token :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	session := entity.NewSession(
+		refreshToken.FamilyID,
+		userID,
+		tools.GetDeviceNameFromContext(ctx),
+		tools.GetUserAgentFromContext(ctx),
+		tools.GetClientIPFromContext(ctx, svc.cfg.Network.TrustedProxies),
+		refreshToken.ExpiresAt,
+		commonentity.SystemActor,
+	)
+
+	if err := svc.sessionRepository.Create(ctx, session); err != nil {
+		log.Println("[UserToken - Issue] Error while saving session :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return svc.newTokenPair(ctx, userID, session.ID, plain)
+}
+
+// Refresh rotates refresh token and issues new access token.
+// Reusing an already rotated refresh token revokes its whole family.
+func (svc *UserToken) Refresh(ctx context.Context, refreshToken string) (uuid.UUID, *entity.TokenPair, error) {
+	current, err := svc.refreshTokenRepository.FindByTokenHash(ctx, tools.SHA256Hex(refreshToken))
+	if err != nil {
+		log.Println("[UserToken - Refresh] Error while finding refresh token :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, nil, commonError.ErrInvalidRefreshToken.Error()
+		}
+		return uuid.Nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if current.IsRevoked() || current.IsExpired(time.Now()) {
+		return uuid.Nil, nil, commonError.ErrInvalidRefreshToken.Error()
+	}
+
+	if current.IsUsed() {
+		log.Println("[UserToken - Refresh] Refresh token reuse detected, revoking family :", current.FamilyID)
+		return uuid.Nil, nil, svc.revokeFamily(ctx, current.FamilyID)
+	}
+
+	next, plain, err := svc.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		log.Println("[UserToken - Refresh] Error while generating refresh token :", err)
+		return uuid.Nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.refreshTokenRepository.Rotate(ctx, current, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyRotated) {
+			log.Println("[UserToken - Refresh] Concurrent refresh token reuse detected, revoking family :", current.FamilyID)
+			return uuid.Nil, nil, svc.revokeFamily(ctx, current.FamilyID)
+		}
+		log.Println("[UserToken - Refresh] Error while rotating refresh token :", err)
+		return uuid.Nil, nil, commonError.ErrInternalServerError.Error()
+	}
+
+	// last seen time is informational only, failing to record it must not fail the refresh
+	if err := svc.sessionRepository.Touch(ctx, current.FamilyID, tools.GetClientIPFromContext(ctx, svc.cfg.Network.TrustedProxies), time.Now(), next.ExpiresAt); err != nil {
+		log.Println("[UserToken - Refresh] Error while updating session :", err)
+	}
+
+	pair, err := svc.newTokenPair(ctx, current.UserID, current.FamilyID, plain)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	return current.UserID, pair, nil
+}
+
+// Logout revokes access token of the principal and the family of the given refresh token, if any
+func (svc *UserToken) Logout(ctx context.Context, principal *commonJwt.Principal, refreshToken string) error {
+	if err := svc.revocationStore.RevokeToken(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+		log.Println("[UserToken - Logout] Error while revoking access token :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	current, err := svc.refreshTokenRepository.FindByTokenHash(ctx, tools.SHA256Hex(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		log.Println("[UserToken - Logout] Error while finding refresh token :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	// a refresh token of another user must not be revocable by this user
+	if current.UserID != principal.UserID {
+		return nil
+	}
+
+	if err := svc.refreshTokenRepository.RevokeFamily(ctx, current.FamilyID); err != nil {
+		log.Println("[UserToken - Logout] Error while revoking refresh token family :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.sessionRepository.Revoke(ctx, current.FamilyID); err != nil {
+		log.Println("[UserToken - Logout] Error while revoking session :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every access token and refresh token of a user
+func (svc *UserToken) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := svc.revocationStore.RevokeAll(ctx, userID, svc.cfg.JWTConfig.AccessTokenTTL); err != nil {
+		log.Println("[UserToken - LogoutAll] Error while revoking access tokens :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.refreshTokenRepository.RevokeByUserID(ctx, userID); err != nil {
+		log.Println("[UserToken - LogoutAll] Error while revoking refresh tokens :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.sessionRepository.RevokeByUserID(ctx, userID); err != nil {
+		log.Println("[UserToken - LogoutAll] Error while revoking sessions :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return nil
+}
+
+// revokeFamily revokes refresh token family together with its session and the access tokens issued for it,
+// and returns the error that must be sent to the caller
+func (svc *UserToken) revokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := svc.refreshTokenRepository.RevokeFamily(ctx, familyID); err != nil {
+		log.Println("[UserToken - revokeFamily] Error while revoking refresh token family :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.sessionRepository.Revoke(ctx, familyID); err != nil {
+		log.Println("[UserToken - revokeFamily] Error while revoking session :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.revocationStore.RevokeSession(ctx, familyID, svc.cfg.JWTConfig.AccessTokenTTL); err != nil {
+		log.Println("[UserToken - revokeFamily] Error while revoking access tokens of session :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	return commonError.ErrInvalidRefreshToken.Error()
+}
+
+// newRefreshToken generates plain refresh token and its entity in the given family
+func (svc *UserToken) newRefreshToken(userID uuid.UUID, familyID uuid.UUID) (*entity.RefreshToken, string, error) {
+	plain, err := tools.GenerateRandomToken(refreshTokenLength)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken := entity.NewRefreshToken(
+		uuid.New(),
+		userID,
+		familyID,
+		tools.SHA256Hex(plain),
+		time.Now().Add(svc.cfg.JWTConfig.RefreshTokenTTL),
+		commonentity.SystemActor,
+	)
+
+	return refreshToken, plain, nil
+}
+
+// newTokenPair signs short-lived access token carrying user roles and permissions, and pairs it with refresh token
+func (svc *UserToken) newTokenPair(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, refreshToken string) (*entity.TokenPair, error) {
+	generation, err := svc.revocationStore.Generation(ctx, userID)
+	if err != nil {
+		log.Println("[UserToken - newTokenPair] Error while finding token generation :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	roles, err := svc.roleRepository.FindRoleNamesByUserID(ctx, userID)
+	if err != nil {
+		log.Println("[UserToken - newTokenPair] Error while finding user roles :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	scopes, err := svc.roleRepository.FindPermissionNamesByUserID(ctx, userID)
+	if err != nil {
+		log.Println("[UserToken - newTokenPair] Error while finding user permissions :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	claims := &commonJwt.CustomClaims{
+		Subject:    userID,
+		Generation: generation,
+		SessionID:  sessionID,
+		Roles:      roles,
+		Scopes:     scopes,
+	}
+
+	accessToken, err := svc.tokenIssuer.Issue(claims)
+	if err != nil {
+		log.Println("[UserToken - newTokenPair] Error while generating access token :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return &entity.TokenPair{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		RefreshToken:         refreshToken,
+	}, nil
+}
@@ -2,43 +2,266 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
 	"grpc-starter/common/config"
 	commonError "grpc-starter/common/errors"
+	"grpc-starter/common/hasher"
+	"grpc-starter/common/tools"
+	notificationEntity "grpc-starter/modules/notification/v1/entity"
 	"grpc-starter/modules/user/v1/entity"
 	"grpc-starter/modules/user/v1/internal/repository"
 )
 
+const (
+	// passwordResetTokenLength is the number of random bytes used for password reset token
+	passwordResetTokenLength = 32
+	// passwordResetEmailSubject is the subject of password reset email
+	passwordResetEmailSubject = "Reset Password"
+	// passwordResetEmailContent is the html content of password reset email
+	passwordResetEmailContent = `<p>Kami menerima permintaan untuk mengatur ulang password akun anda.</p>
+<p>Silahkan klik <a href="%s">link ini</a> untuk mengatur ulang password. Link ini berlaku selama %s dan hanya dapat digunakan satu kali.</p>
+<p>Abaikan email ini jika anda tidak merasa melakukan permintaan tersebut.</p>`
+)
+
 // UserUpdater responsible for updating user
 type UserUpdater struct {
-	cfg                  config.Config
-	updateUserRepository repository.UserUpdaterRepositoryUseCase
+	cfg                         config.Config
+	updateUserRepository        repository.UserUpdaterRepositoryUseCase
+	userFinderRepository        repository.UserFinderRepositoryUseCase
+	userPasswordResetRepository repository.UserPasswordResetRepositoryUseCase
+	userTokenSvc                UserTokenUseCase
+	passwordPolicySvc           UserPasswordPolicyUseCase
+	passwordHasher              hasher.PasswordHasher
 }
 
 // UserUpdaterUseCase is use case for updating existing user
 type UserUpdaterUseCase interface {
-	// Update update user by user id
+	// Update update user by user id, as long as it has not been updated since it was read
 	Update(ctx context.Context, user *entity.User) error
+	// UpdateProfile updates profile fields listed in paths and returns the updated user.
+	// A non-zero version must match the current version of the user.
+	UpdateProfile(ctx context.Context, userID uuid.UUID, profile *entity.User, paths []string, version int64) (*entity.User, error)
+	// Disable disables user and revokes all of its tokens, then returns the disabled user
+	Disable(ctx context.Context, userID uuid.UUID) (*entity.User, error)
+	// Restore enables disabled user and restores soft deleted user, then returns the restored user
+	Restore(ctx context.Context, userID uuid.UUID) (*entity.User, error)
+	// ForgotPassword issues password reset token and sends it to user email
+	ForgotPassword(ctx context.Context, email string) error
+	// ChangePassword checks password against the password policy, consumes password reset token, updates user password
+	// and revokes every token of the user
+	ChangePassword(ctx context.Context, token string, password string) error
 }
 
 // NewUserUpdater constructs new instance of UserUpdater
 func NewUserUpdater(
 	cfg config.Config,
 	updateUserRepository repository.UserUpdaterRepositoryUseCase,
+	userFinderRepository repository.UserFinderRepositoryUseCase,
+	userPasswordResetRepository repository.UserPasswordResetRepositoryUseCase,
+	userTokenSvc UserTokenUseCase,
+	passwordPolicySvc UserPasswordPolicyUseCase,
+	passwordHasher hasher.PasswordHasher,
 ) *UserUpdater {
 	return &UserUpdater{
-		cfg:                  cfg,
-		updateUserRepository: updateUserRepository,
+		cfg:                         cfg,
+		updateUserRepository:        updateUserRepository,
+		userFinderRepository:        userFinderRepository,
+		userPasswordResetRepository: userPasswordResetRepository,
+		userTokenSvc:                userTokenSvc,
+		passwordPolicySvc:           passwordPolicySvc,
+		passwordHasher:              passwordHasher,
 	}
 }
 
-// Update updates user
+// Update updates user, as long as it has not been updated since it was read
 func (svc *UserUpdater) Update(ctx context.Context, user *entity.User) error {
 	if err := svc.updateUserRepository.Update(ctx, user); err != nil {
 		log.Println("[UserUpdater - Update] Error while updating user data :", err)
+		return updateError(err)
+	}
+
+	return nil
+}
+
+// UpdateProfile updates profile fields listed in paths and returns the updated user.
+// A non-zero version must match the current version of the user.
+func (svc *UserUpdater) UpdateProfile(ctx context.Context, userID uuid.UUID, profile *entity.User, paths []string, version int64) (*entity.User, error) {
+	user, err := svc.userFinderRepository.FindByID(ctx, userID)
+	if err != nil {
+		log.Println("[UserUpdater - UpdateProfile] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if version != 0 && version != user.Version {
+		return nil, commonError.ErrUserVersionConflict.Error()
+	}
+
+	if !user.ApplyProfile(profile, paths) {
+		return nil, commonError.ErrInvalidUpdateMask.Error()
+	}
+
+	user.UpdatedBy = tools.StringToNullString(userID.String())
+
+	if err := svc.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Disable disables user and revokes all of its tokens, then returns the disabled user
+func (svc *UserUpdater) Disable(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	if _, err := svc.findWithDeleted(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := svc.updateUserRepository.Disable(ctx, userID); err != nil {
+		log.Println("[UserUpdater - Disable] Error while disabling user :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.userTokenSvc.LogoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return svc.findWithDeleted(ctx, userID)
+}
+
+// Restore enables disabled user and restores soft deleted user, then returns the restored user.
+// A deleted user whose personal data has been erased can not be restored.
+func (svc *UserUpdater) Restore(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := svc.findWithDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsErased() {
+		return nil, commonError.ErrUserErased.Error()
+	}
+
+	if err := svc.updateUserRepository.Restore(ctx, userID); err != nil {
+		log.Println("[UserUpdater - Restore] Error while restoring user :", err)
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return svc.findWithDeleted(ctx, userID)
+}
+
+// findWithDeleted finds user including soft deleted one
+func (svc *UserUpdater) findWithDeleted(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := svc.userFinderRepository.FindByIDWithDeleted(ctx, userID)
+	if err != nil {
+		log.Println("[UserUpdater - findWithDeleted] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonError.ErrUserNotFound.Error()
+		}
+		return nil, commonError.ErrInternalServerError.Error()
+	}
+
+	return user, nil
+}
+
+// ForgotPassword issues password reset token and sends it to user email.
+// It does not return error when the email is not registered, so the caller can not enumerate registered emails.
+func (svc *UserUpdater) ForgotPassword(ctx context.Context, email string) error {
+	user, err := svc.userFinderRepository.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Println("[UserUpdater - ForgotPassword] Password reset requested for unregistered email")
+			return nil
+		}
+		log.Println("[UserUpdater - ForgotPassword] Error while finding user data :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	token, err := tools.GenerateRandomToken(passwordResetTokenLength)
+	if err != nil {
+		log.Println("[UserUpdater - ForgotPassword] Error while generating password reset token :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.userPasswordResetRepository.Save(ctx, token, user.ID, svc.cfg.PasswordReset.TokenTTL); err != nil {
+		log.Println("[UserUpdater - ForgotPassword] Error while saving password reset token :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	link := fmt.Sprintf("%s?token=%s", svc.cfg.PasswordReset.URL, token)
+	payload := notificationEntity.NewSecretEmailPayload(
+		user.Email,
+		passwordResetEmailSubject,
+		fmt.Sprintf(passwordResetEmailContent, link, svc.cfg.PasswordReset.TokenTTL),
+		entity.EmailCategoryPasswordReset,
+	)
+
+	if err := tools.SendTopic(ctx, svc.cfg, notificationEntity.SendEmailTopicName, payload); err != nil {
+		log.Println("[UserUpdater - ForgotPassword] Error while publishing password reset email :", err)
 		return commonError.ErrInternalServerError.Error()
 	}
 
 	return nil
 }
+
+// ChangePassword checks password against the password policy, consumes password reset token, updates user password
+// and revokes every token of the user.
+// The token is only consumed once the password is accepted, so the user can retry with another password.
+func (svc *UserUpdater) ChangePassword(ctx context.Context, token string, password string) error {
+	userID, err := svc.userPasswordResetRepository.Find(ctx, token)
+	if err != nil {
+		log.Println("[UserUpdater - ChangePassword] Error while finding password reset token :", err)
+		return commonError.ErrInvalidPasswordResetToken.Error()
+	}
+
+	user, err := svc.userFinderRepository.FindByID(ctx, userID)
+	if err != nil {
+		log.Println("[UserUpdater - ChangePassword] Error while finding user data :", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commonError.ErrInvalidPasswordResetToken.Error()
+		}
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	if err := svc.passwordPolicySvc.Validate(password, user.Username.String, user.Email); err != nil {
+		return err
+	}
+
+	if _, err := svc.userPasswordResetRepository.Consume(ctx, token); err != nil {
+		log.Println("[UserUpdater - ChangePassword] Error while consuming password reset token :", err)
+		return commonError.ErrInvalidPasswordResetToken.Error()
+	}
+
+	hashed, err := svc.passwordHasher.Hash(password)
+	if err != nil {
+		log.Println("[UserUpdater - ChangePassword] Error while hashing password :", err)
+		return commonError.ErrInternalServerError.Error()
+	}
+
+	user.Password = hashed
+	// the reset token proves the change is made by the user itself
+	user.UpdatedBy = tools.StringToNullString(user.ID.String())
+
+	if err := svc.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// whoever held the old password must not keep using its tokens
+	return svc.userTokenSvc.LogoutAll(ctx, user.ID)
+}
+
+// updateError maps failure of updating user, a concurrent update becomes aborted error
+// and a unique violation becomes already exists error with the conflicting field
+func updateError(err error) error {
+	if errors.Is(err, repository.ErrUserVersionConflict) {
+		return commonError.ErrUserVersionConflict.Error()
+	}
+
+	return createError(err)
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"grpc-starter/common/config"
+	"grpc-starter/common/hasher"
+	"grpc-starter/modules/user/v1/entity"
+	"grpc-starter/modules/user/v1/internal/repository"
+	"grpc-starter/modules/user/v1/service"
+)
+
+// fakeUserPasswordResetRepository holds a single password reset token
+type fakeUserPasswordResetRepository struct {
+	token    string
+	userID   uuid.UUID
+	consumed bool
+}
+
+func (r *fakeUserPasswordResetRepository) Save(ctx context.Context, token string, userID uuid.UUID, ttl time.Duration) error {
+	r.token, r.userID = token, userID
+	return nil
+}
+
+func (r *fakeUserPasswordResetRepository) Find(ctx context.Context, token string) (uuid.UUID, error) {
+	if r.consumed || token != r.token {
+		return uuid.Nil, errors.New("token not found")
+	}
+	return r.userID, nil
+}
+
+func (r *fakeUserPasswordResetRepository) Consume(ctx context.Context, token string) (uuid.UUID, error) {
+	userID, err := r.Find(ctx, token)
+	r.consumed = err == nil
+	return userID, err
+}
+
+// fakeUserUpdaterRepository records updated users, the methods it does not override are not expected to be called
+type fakeUserUpdaterRepository struct {
+	repository.UserUpdaterRepositoryUseCase
+	updated   []*entity.User
+	updateErr error
+}
+
+func (r *fakeUserUpdaterRepository) Update(ctx context.Context, user *entity.User) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	r.updated = append(r.updated, user)
+	return nil
+}
+
+// fakeUserToken records users tokens are issued for and users logged out from all devices,
+// the methods it does not override are not expected to be called
+type fakeUserToken struct {
+	service.UserTokenUseCase
+	issued    []uuid.UUID
+	loggedOut []uuid.UUID
+}
+
+func (svc *fakeUserToken) Issue(ctx context.Context, userID uuid.UUID) (*entity.TokenPair, error) {
+	svc.issued = append(svc.issued, userID)
+	return &entity.TokenPair{}, nil
+}
+
+func (svc *fakeUserToken) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	svc.loggedOut = append(svc.loggedOut, userID)
+	return nil
+}
+
+func TestUserUpdater_ChangePassword(t *testing.T) {
+	cfg := config.Config{PasswordPolicy: config.PasswordPolicy{MinLength: 8, MaxLength: 72}}
+	user := &entity.User{ID: uuid.New(), Email: "user@example.com", Password: "old-hash"}
+
+	newUpdater := func(updaterRepo *fakeUserUpdaterRepository, tokenSvc *fakeUserToken) (*service.UserUpdater, *fakeUserPasswordResetRepository) {
+		resetRepo := &fakeUserPasswordResetRepository{token: "reset-token", userID: user.ID}
+		stored := *user
+		return service.NewUserUpdater(
+			cfg,
+			updaterRepo,
+			&fakeUserFinderRepository{users: map[uuid.UUID]*entity.User{user.ID: &stored}},
+			resetRepo,
+			tokenSvc,
+			service.NewUserPasswordPolicy(cfg),
+			hasher.NewBcrypt(4),
+		), resetRepo
+	}
+
+	t.Run("changed password revokes every token of the user", func(t *testing.T) {
+		updaterRepo, tokenSvc := &fakeUserUpdaterRepository{}, &fakeUserToken{}
+		updater, resetRepo := newUpdater(updaterRepo, tokenSvc)
+
+		err := updater.ChangePassword(context.Background(), "reset-token", "n3w-passphrase")
+		assert.Nil(t, err)
+		assert.True(t, resetRepo.consumed)
+		assert.Len(t, updaterRepo.updated, 1)
+		assert.NotEqual(t, "old-hash", updaterRepo.updated[0].Password)
+		assert.Equal(t, []uuid.UUID{user.ID}, tokenSvc.loggedOut)
+	})
+
+	t.Run("failed update keeps tokens of the user", func(t *testing.T) {
+		updaterRepo, tokenSvc := &fakeUserUpdaterRepository{updateErr: errors.New("connection reset")}, &fakeUserToken{}
+		updater, _ := newUpdater(updaterRepo, tokenSvc)
+
+		err := updater.ChangePassword(context.Background(), "reset-token", "n3w-passphrase")
+		assert.NotNil(t, err)
+		assert.Empty(t, tokenSvc.loggedOut)
+	})
+
+	t.Run("invalid token changes nothing", func(t *testing.T) {
+		updaterRepo, tokenSvc := &fakeUserUpdaterRepository{}, &fakeUserToken{}
+		updater, _ := newUpdater(updaterRepo, tokenSvc)
+
+		err := updater.ChangePassword(context.Background(), "unknown-token", "n3w-passphrase")
+		assert.NotNil(t, err)
+		assert.Empty(t, updaterRepo.updated)
+		assert.Empty(t, tokenSvc.loggedOut)
+	})
+}
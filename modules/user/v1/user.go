@@ -12,13 +12,34 @@ import (
 
 	userv1 "grpc-starter/api/user/v1"
 	"grpc-starter/common/config"
+	commonJwt "grpc-starter/common/jwt"
 	"grpc-starter/modules/user/v1/internal/builder"
 )
 
 // InitGrpc initializes gRPC user modules.
-func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB, redisPool *redis.Pool, grpcConn *grpc.ClientConn) {
-	user := builder.BuildUserHandler(cfg, db, redisPool, grpcConn)
+// It returns an error when the modules can not be built from the configuration.
+func InitGrpc(
+	server *grpc.Server,
+	cfg config.Config,
+	db *gorm.DB,
+	redisPool *redis.Pool,
+	grpcConn *grpc.ClientConn,
+	keyManager *commonJwt.KeyManager,
+) error {
+	user, err := builder.BuildUserHandler(cfg, db, redisPool, grpcConn, keyManager)
+	if err != nil {
+		return err
+	}
+
 	userv1.RegisterUserServiceServer(server, user)
+	return nil
+}
+
+// StartJobs starts background jobs of user modules, they stop once ctx is done.
+func StartJobs(ctx context.Context, cfg config.Config, db *gorm.DB, redisPool *redis.Pool) {
+	if cfg.AccountErasure.Enabled {
+		go builder.BuildErasureJob(cfg, db, redisPool).Run(ctx)
+	}
 }
 
 // InitRest initializes REST user modules.
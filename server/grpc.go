@@ -26,7 +26,6 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
-	commonJwt "grpc-starter/common/jwt"
 	"grpc-starter/server/interceptor"
 )
 
@@ -69,8 +68,10 @@ func NewGrpc(port string, options ...grpc.ServerOption) *Grpc {
 // 	- Metrics, using Prometheus.
 // 	- Logging, using zap logger.
 // 	- Recoverer, using grpc_recovery.
-func NewDevelopmentGrpc(port string) *Grpc {
-	options := grpc_middleware.WithUnaryServerChain(defaultUnaryServerInterceptors()...)
+//
+// Requests are authenticated by authFunc.
+func NewDevelopmentGrpc(port string, authFunc grpc_auth.AuthFunc) *Grpc {
+	options := grpc_middleware.WithUnaryServerChain(defaultUnaryServerInterceptors(authFunc)...)
 	srv := NewGrpc(port, options)
 	grpc_prometheus.Register(srv.Server)
 	return srv
@@ -88,7 +89,9 @@ func NewDevelopmentGrpc(port string) *Grpc {
 // It also activates some auxiliaries:
 // 	- Profiler, using Google Cloud Profiler.
 // 	- Tracing, using Google Cloud Stackdriver Trace. The sample probability is 1% for production environment. Otherwise, it is 100%.
-func NewProductionGrpc(env, serviceName, gcpProjectID, grpcPort string) (*Grpc, error) {
+//
+// Requests are authenticated by authFunc.
+func NewProductionGrpc(env, serviceName, gcpProjectID, grpcPort string, authFunc grpc_auth.AuthFunc) (*Grpc, error) {
 	if err := activateProfiling(gcpProjectID, serviceName); err != nil {
 		return nil, err
 	}
@@ -102,7 +105,7 @@ func NewProductionGrpc(env, serviceName, gcpProjectID, grpcPort string) (*Grpc,
 	}
 
 	midds := []grpc.UnaryServerInterceptor{interceptor.ErrorReporting(reporter)}
-	midds = append(midds, defaultUnaryServerInterceptors()...)
+	midds = append(midds, defaultUnaryServerInterceptors(authFunc)...)
 
 	options := grpc_middleware.WithUnaryServerChain(midds...)
 	srv := NewGrpc(grpcPort, grpc.StatsHandler(&ocgrpc.ServerHandler{}), options)
@@ -151,7 +154,7 @@ func createErrorReporter(serviceName, gcpProjectID string) (*errorreporting.Clie
 }
 
 // defaultUnaryServerInterceptors returns a list of default unary server interceptors.
-func defaultUnaryServerInterceptors() []grpc.UnaryServerInterceptor {
+func defaultUnaryServerInterceptors(authFunc grpc_auth.AuthFunc) []grpc.UnaryServerInterceptor {
 	logger, _ := zap.NewProduction() // error is impossible, hence ignored.
 	grpc_zap.SetGrpcLoggerV2(grpc_logsettable.ReplaceGrpcLoggerV2(), logger)
 	grpc_prometheus.EnableHandlingTimeHistogram()
@@ -159,7 +162,7 @@ func defaultUnaryServerInterceptors() []grpc.UnaryServerInterceptor {
 	options := []grpc.UnaryServerInterceptor{
 		grpc_recovery.UnaryServerInterceptor(grpc_recovery.WithRecoveryHandler(recoveryHandler)),
 		grpc_zap.UnaryServerInterceptor(logger),
-		grpc_auth.UnaryServerInterceptor(commonJwt.Authorize),
+		grpc_auth.UnaryServerInterceptor(authFunc),
 		grpc_prometheus.UnaryServerInterceptor,
 	}
 	return options
@@ -1,6 +1,7 @@
 package server_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -22,7 +23,9 @@ func TestNewGrpc(t *testing.T) {
 
 func TestNewDevelopmentGrpc(t *testing.T) {
 	t.Run("successfully create a development gRPC server", func(t *testing.T) {
-		srv := server.NewDevelopmentGrpc(testPort)
+		srv := server.NewDevelopmentGrpc(testPort, func(ctx context.Context) (context.Context, error) {
+			return ctx, nil
+		})
 		defer srv.Stop()
 		assert.NotNil(t, srv)
 	})
@@ -9,10 +9,14 @@ import (
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// deviceNameHeader is the HTTP header a client uses to name the device of its session
+const deviceNameHeader = "X-Device-Name"
+
 // Rest is responsible to act as HTTP/1.1 REST server.
 // It composes grpc-gateway runtime.ServeMux.
 type Rest struct {
@@ -22,8 +26,15 @@ type Rest struct {
 
 // ErrorData represents error code and message for the response.
 type ErrorData struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Fields  []ErrorField `json:"fields,omitempty"`
+}
+
+// ErrorField represents a field of the request that caused the error.
+type ErrorField struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
 }
 
 // Error represents error response.
@@ -48,6 +59,7 @@ func NewRest(port string) *Rest {
 				},
 			}),
 			runtime.WithErrorHandler(customErrorHandler),
+			runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
 		),
 		port: port,
 	}
@@ -68,6 +80,7 @@ func NewProductionRest(port string) *Rest {
 				},
 			}),
 			runtime.WithErrorHandler(customErrorHandler),
+			runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
 		),
 		port: port,
 	}
@@ -88,11 +101,22 @@ func (r *Rest) EnableHealth() error {
 	return r.ServeMux.HandlePath(http.MethodGet, "/healthz", healthHandler())
 }
 
+// EnableJWKS enables JSON Web Key Set endpoint.
+// It can be accessed via /.well-known/jwks.json and serves the value returned by keySet.
+func (r *Rest) EnableJWKS(keySet func() interface{}) error {
+	return r.ServeMux.HandlePath(http.MethodGet, "/.well-known/jwks.json", jwksHandler(keySet))
+}
+
+// Handler returns runtime.ServeMux wrapped with CORS handling, as served by Run.
+func (r *Rest) Handler() http.Handler {
+	return allowCORS(r.ServeMux)
+}
+
 // Run runs HTTP/1.1 runtime.ServeMux.
 // It runs inside a goroutine.
 func (r *Rest) Run() error {
 	go func() {
-		if err := http.ListenAndServe(fmt.Sprintf(":%s", r.port), allowCORS(r.ServeMux)); err != nil {
+		if err := http.ListenAndServe(fmt.Sprintf(":%s", r.port), r.Handler()); err != nil {
 			panic(err)
 		}
 	}()
@@ -111,14 +135,22 @@ func healthHandler() runtime.HandlerFunc {
 	}
 }
 
+func jwksHandler(keySet func() interface{}) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(keySet())
+	}
+}
+
 func allowCORS(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if origin := r.Header.Get("Origin"); origin != "" {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
-				headers := []string{"Content-Type", "Accept", "Authorization"}
+				headers := []string{"Content-Type", "Accept", "Authorization", deviceNameHeader}
 				w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ","))
-				methods := []string{"GET", "HEAD", "POST", "PUT", "DELETE"}
+				methods := []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
 				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ","))
 				return
 			}
@@ -127,6 +159,15 @@ func allowCORS(h http.Handler) http.Handler {
 	})
 }
 
+// incomingHeaderMatcher forwards the device name header to gRPC metadata on top of the default headers
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, deviceNameHeader) {
+		return strings.ToLower(deviceNameHeader), true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 // customErrorHandler customize error handler instead of using the default one
 func customErrorHandler(
 	ctx context.Context,
@@ -147,6 +188,7 @@ func customErrorHandler(
 		Error: ErrorData{
 			Code:    int(s.Code()),
 			Message: s.Message(),
+			Fields:  errorFields(s),
 		},
 		Meta: nil,
 	})
@@ -155,3 +197,22 @@ func customErrorHandler(
 		_, _ = w.Write([]byte(fallback))
 	}
 }
+
+// errorFields collects field violations of bad request details of the status
+func errorFields(s *status.Status) []ErrorField {
+	var fields []ErrorField
+	for _, detail := range s.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, violation := range badRequest.GetFieldViolations() {
+			fields = append(fields, ErrorField{
+				Field:       violation.GetField(),
+				Description: violation.GetDescription(),
+			})
+		}
+	}
+
+	return fields
+}
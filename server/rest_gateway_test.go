@@ -1,6 +1,8 @@
 package server_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,3 +43,27 @@ func TestRest_EnableHealth(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestRest_EnableJWKS(t *testing.T) {
+	t.Run("success enable jwks", func(t *testing.T) {
+		srv := server.NewRest(testRestPort)
+		err := srv.EnableJWKS(func() interface{} { return map[string]interface{}{"keys": []interface{}{}} })
+		assert.Nil(t, err)
+	})
+}
+
+func TestRest_Handler(t *testing.T) {
+	t.Run("preflight allows patch", func(t *testing.T) {
+		srv := server.NewRest(testRestPort)
+
+		req := httptest.NewRequest(http.MethodOptions, "/v1/users/me", nil)
+		req.Header.Set("Origin", "http://localhost:3000")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "http://localhost:3000", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+	})
+}
@@ -13,12 +13,18 @@ import (
 	"github.com/pawelWritesCode/gdutils/pkg/stringutils"
 	"github.com/pawelWritesCode/gdutils/pkg/timeutils"
 	"github.com/pawelWritesCode/gdutils/pkg/types"
+
+	"grpc-starter/common/oidc/oidctest"
 )
 
 // Scenario is entity that contains utility services and holds methods used behind godog steps.
 type Scenario struct {
 	// APIContext holds utility services and methods for working with HTTP(s) API.
 	APIContext *gdutils.APIContext
+	// OIDCIssuer is the local OpenID Connect issuer signing ID tokens, it is nil when the suite runs without it.
+	OIDCIssuer *oidctest.Issuer
+	// OIDCClientID is the audience of signed ID tokens.
+	OIDCClientID string
 }
 
 // IGenerateARandomRunesOfLengthWithCharactersAndSaveItAs creates random runes generator func using provided charset.
@@ -107,6 +113,33 @@ func (s *Scenario) IGenerateCurrentTimeAndTravelByAndSaveItAs(timeDirection, tim
 	return s.APIContext.GenerateTimeAndTravel(timeutils.TimeDirection(timeDirection), duration, cacheKey)
 }
 
+// ISignAnIDTokenForSubjectWithEmailAndSaveItAs signs ID token with verified email using the local OpenID Connect issuer
+// and preserve it in scenario cache under provided cacheKey.
+func (s *Scenario) ISignAnIDTokenForSubjectWithEmailAndSaveItAs(subjectTemplate, emailTemplate, cacheKey string) error {
+	if s.OIDCIssuer == nil {
+		return errors.New("local OpenID Connect issuer is not started, set OIDC_PROVIDER_ISSUER")
+	}
+
+	subject, err := s.APIContext.TemplateEngine.Replace(subjectTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'subject' template, err: %w", err)
+	}
+
+	email, err := s.APIContext.TemplateEngine.Replace(emailTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'email' template, err: %w", err)
+	}
+
+	idToken, err := s.OIDCIssuer.IDToken(s.OIDCClientID, subject, email, true)
+	if err != nil {
+		return fmt.Errorf("could not sign id token, err: %w", err)
+	}
+
+	s.APIContext.Cache.Save(cacheKey, idToken)
+
+	return nil
+}
+
 /*
 		ISendRequestToWithFormatBodyAndHeaders sends HTTP(s) requests with provided body and headers.
 